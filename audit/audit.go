@@ -0,0 +1,311 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+// Package audit implements an append-only, tamper-evident log of the
+// administrative and security-relevant actions of a NATS Streaming Server.
+//
+// An audit log file starts with the file version, followed by records.
+// The record layout is the same as the records of the FileStore:
+// 4 bytes for the size of the payload, 4 bytes for the CRC-32 of the
+// payload, and the payload itself, which is a marshaled spb.AuditRecord.
+// Each record contains the SHA-256 of the payload of the previous record,
+// including across rotated files, so that records cannot be modified,
+// removed or reordered without breaking the chain.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/util"
+)
+
+const (
+	// Version of the audit log files.
+	fileVersion = 1
+
+	// Size of the header of a record: size and CRC.
+	recordHeaderSize = 8
+
+	// Format of the suffix appended to the name of rotated files.
+	rotateTimeFormat = "20060102T150405.000000000"
+)
+
+// Events recorded in the audit log
+const (
+	EventAuditCorrupted   = "audit_corrupted"
+	EventClientConnect    = "client_connect"
+	EventClientClose      = "client_close"
+	EventClientReplaced   = "client_replaced"
+	EventDurableCreate    = "durable_create"
+	EventDurableDelete    = "durable_delete"
+	EventDurableSeek      = "durable_seek"
+	EventDurableExpire    = "durable_expire"
+	EventSubPause         = "sub_pause"
+	EventSubResume        = "sub_resume"
+	EventFTActive         = "ft_active"
+	EventConfigReload     = "config_reload"
+	EventConfigReloadFail = "config_reload_failed"
+)
+
+// Errors returned when writing to an audit log that is not opened.
+var (
+	ErrNotOpened = errors.New("audit log not opened")
+	ErrClosed    = errors.New("audit log closed")
+)
+
+// Log is an audit log. Records are appended to the file and synced to disk.
+// The file is rotated once it reaches its maximum size.
+type Log struct {
+	sync.Mutex
+	fileName string
+	maxSize  int64
+	file     *os.File
+	size     int64
+	prevHash []byte
+	buf      []byte
+	opened   bool
+	closed   bool
+}
+
+// New returns the audit log `fileName`, which is opened with Log.Open().
+// This allows a server to open the log only once it owns it, for instance
+// when it becomes the active server of a fault-tolerance group.
+// A `maxSize` of 0 means that the file is never rotated.
+func New(fileName string, maxSize int64) *Log {
+	return &Log{fileName: fileName, maxSize: maxSize}
+}
+
+// Open opens the audit log `fileName`, creating it if needed.
+// See Log.Open() for details.
+func Open(fileName string, maxSize int64) (*Log, error) {
+	l := New(fileName, maxSize)
+	if err := l.Open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Open opens the audit log file, creating it if needed. If the file exists,
+// it is verified and new records are chained to its last record.
+// If the verification fails, the file is rotated and the first record of
+// the new file, chained to the last valid record, reports the error.
+func (l *Log) Open() error {
+	l.Lock()
+	defer l.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if l.opened {
+		return nil
+	}
+	var verifyErr error
+	if _, err := os.Stat(l.fileName); err == nil {
+		l.prevHash, verifyErr = ReadFile(l.fileName, nil, nil)
+		if verifyErr != nil {
+			if err := l.rotate(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := l.openFile(); err != nil {
+		return err
+	}
+	l.opened = true
+	if verifyErr != nil {
+		if err := l.write(EventAuditCorrupted, "", "", verifyErr.Error()); err != nil {
+			l.file.Close()
+			l.file = nil
+			l.opened = false
+			return err
+		}
+	}
+	return nil
+}
+
+// openFile opens (or creates) the current file of the audit log.
+func (l *Log) openFile() error {
+	file, err := os.OpenFile(l.fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err == nil && stat.Size() == 0 {
+		if err = util.WriteInt(file, fileVersion); err == nil {
+			err = file.Sync()
+		}
+	}
+	if err == nil {
+		stat, err = file.Stat()
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = stat.Size()
+	return nil
+}
+
+// rotate renames the current file of the audit log. The current file
+// must be closed.
+func (l *Log) rotate() error {
+	rotated := fmt.Sprintf("%s.%s", l.fileName, time.Now().UTC().Format(rotateTimeFormat))
+	if err := os.Rename(l.fileName, rotated); err != nil {
+		return fmt.Errorf("unable to rotate audit log: %v", err)
+	}
+	return nil
+}
+
+// rotateFile closes, rotates and reopens the current file. If the file
+// cannot be renamed, it is reopened so that records can still be written,
+// and the rotation will be attempted again on the next write. If the file
+// cannot be reopened, this will be attempted again on the next write.
+func (l *Log) rotateFile() error {
+	err := l.file.Close()
+	l.file = nil
+	if err == nil {
+		err = l.rotate()
+	}
+	if oerr := l.openFile(); oerr != nil {
+		return oerr
+	}
+	return err
+}
+
+// Write appends a record to the audit log. If the record is written but
+// the file could not be rotated, the rotation error is returned.
+func (l *Log) Write(event, clusterID, clientID, details string) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if !l.opened {
+		return ErrNotOpened
+	}
+	return l.write(event, clusterID, clientID, details)
+}
+
+// write appends a record to the audit log.
+// Lock held on entry.
+func (l *Log) write(event, clusterID, clientID, details string) error {
+	// The file is not opened if it could not be reopened after a rotation.
+	if l.file == nil {
+		if err := l.openFile(); err != nil {
+			return err
+		}
+	}
+	rec := &spb.AuditRecord{
+		Timestamp: time.Now().UnixNano(),
+		Event:     event,
+		ClusterID: clusterID,
+		ClientID:  clientID,
+		Details:   details,
+		PrevHash:  l.prevHash,
+	}
+	recSize := rec.Size()
+	totalSize := recordHeaderSize + recSize
+	var rotateErr error
+	if l.maxSize > 0 && l.size > 4 && l.size+int64(totalSize) > l.maxSize {
+		if rotateErr = l.rotateFile(); l.file == nil {
+			return rotateErr
+		}
+	}
+	l.buf = util.EnsureBufBigEnough(l.buf, totalSize)
+	if _, err := rec.MarshalTo(l.buf[recordHeaderSize:totalSize]); err != nil {
+		return err
+	}
+	payload := l.buf[recordHeaderSize:totalSize]
+	util.ByteOrder.PutUint32(l.buf[:4], uint32(recSize))
+	util.ByteOrder.PutUint32(l.buf[4:recordHeaderSize], crc32.ChecksumIEEE(payload))
+	// Write the whole record at once to reduce the risk of partial writes.
+	if _, err := l.file.Write(l.buf[:totalSize]); err != nil {
+		return err
+	}
+	l.size += int64(totalSize)
+	hash := sha256.Sum256(payload)
+	l.prevHash = hash[:]
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	return rotateErr
+}
+
+// Close closes the audit log.
+func (l *Log) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// ReadFile reads and verifies the records of the audit log file `fileName`,
+// invoking `cb` (if not nil) for each record. If `prevHash` is not nil,
+// the first record must be chained to it, which allows verifying rotated
+// files in sequence. Returns the hash of the last record, to be passed when
+// reading the next file.
+func ReadFile(fileName string, prevHash []byte, cb func(*spb.AuditRecord) error) ([]byte, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fv, err := util.ReadInt(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify file version: %v", err)
+	}
+	if fv == 0 || fv > fileVersion {
+		return nil, fmt.Errorf("unsupported file version: %v (supports [1..%v])", fv, fileVersion)
+	}
+	var (
+		header = make([]byte, recordHeaderSize)
+		buf    []byte
+		count  = 0
+	)
+	for {
+		if _, err := io.ReadFull(file, header); err != nil {
+			if err == io.EOF {
+				return prevHash, nil
+			}
+			return prevHash, fmt.Errorf("record %v: unable to read header: %v", count+1, err)
+		}
+		count++
+		recSize := int(util.ByteOrder.Uint32(header[:4]))
+		crc := util.ByteOrder.Uint32(header[4:])
+		buf = util.EnsureBufBigEnough(buf, recSize)
+		payload := buf[:recSize]
+		if _, err := io.ReadFull(file, payload); err != nil {
+			return prevHash, fmt.Errorf("record %v: unable to read payload: %v", count, err)
+		}
+		if c := crc32.ChecksumIEEE(payload); c != crc {
+			return prevHash, fmt.Errorf("record %v: corrupted data, expected crc to be 0x%08x, got 0x%08x", count, crc, c)
+		}
+		rec := &spb.AuditRecord{}
+		if err := rec.Unmarshal(payload); err != nil {
+			return prevHash, fmt.Errorf("record %v: %v", count, err)
+		}
+		if prevHash != nil && !bytes.Equal(rec.PrevHash, prevHash) {
+			return prevHash, fmt.Errorf("record %v: broken chain, previous hash does not match", count)
+		}
+		if cb != nil {
+			if err := cb(rec); err != nil {
+				return prevHash, err
+			}
+		}
+		hash := sha256.Sum256(payload)
+		prevHash = hash[:]
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/util"
+)
+
+func createTmpDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unable to create tmp dir: %v", err)
+	}
+	return dir
+}
+
+func writeRecords(t *testing.T, l *Log, clientIDs ...string) {
+	for _, cid := range clientIDs {
+		if err := l.Write(EventClientConnect, "cluster", cid, "hb_inbox=inbox"); err != nil {
+			t.Fatalf("Error writing record: %v", err)
+		}
+	}
+}
+
+func readRecords(t *testing.T, files ...string) ([]*spb.AuditRecord, error) {
+	var (
+		recs     []*spb.AuditRecord
+		prevHash []byte
+		err      error
+	)
+	for _, file := range files {
+		prevHash, err = ReadFile(file, prevHash, func(rec *spb.AuditRecord) error {
+			recs = append(recs, rec)
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+	return recs, err
+}
+
+// auditFiles returns the rotated files followed by the current file.
+func auditFiles(t *testing.T, fileName string) []string {
+	rotated, err := filepath.Glob(fileName + ".*")
+	if err != nil {
+		t.Fatalf("Error listing files: %v", err)
+	}
+	sort.Strings(rotated)
+	return append(rotated, fileName)
+}
+
+func TestAuditWriteAndRead(t *testing.T) {
+	dir := createTmpDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "audit.log")
+
+	l, err := Open(fileName, 0)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "a", "b")
+	l.Close()
+	if err := l.Write(EventClientClose, "cluster", "a", ""); err != ErrClosed {
+		t.Fatalf("Expected error %v, got %v", ErrClosed, err)
+	}
+
+	// Records are appended and chained to the existing ones.
+	l, err = Open(fileName, 0)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "c")
+	l.Close()
+
+	recs, err := readRecords(t, fileName)
+	if err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 records, got %v", len(recs))
+	}
+	for i, cid := range []string{"a", "b", "c"} {
+		rec := recs[i]
+		if rec.Event != EventClientConnect || rec.ClusterID != "cluster" || rec.ClientID != cid ||
+			rec.Details != "hb_inbox=inbox" || rec.Timestamp == 0 {
+			t.Fatalf("Unexpected record: %v", rec)
+		}
+		if (i == 0) != (len(rec.PrevHash) == 0) {
+			t.Fatalf("Unexpected previous hash for record %v: %v", i, rec.PrevHash)
+		}
+	}
+}
+
+func TestAuditRotation(t *testing.T) {
+	dir := createTmpDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "audit.log")
+
+	l, err := Open(fileName, 100)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "a", "b", "c", "d", "e")
+	l.Close()
+
+	files := auditFiles(t, fileName)
+	if len(files) < 3 {
+		t.Fatalf("Expected files to be rotated, got %v", files)
+	}
+	for _, file := range files {
+		if stat, err := os.Stat(file); err != nil || stat.Size() > 100 {
+			t.Fatalf("Unexpected file %q: %v %v", file, stat, err)
+		}
+	}
+	// The chain is verified across files.
+	recs, err := readRecords(t, files...)
+	if err != nil || len(recs) != 5 {
+		t.Fatalf("Unexpected result: recs=%v err=%v", len(recs), err)
+	}
+	// But not if a file is missing.
+	if _, err := readRecords(t, append(files[:1], files[2:]...)...); err == nil || !strings.Contains(err.Error(), "broken chain") {
+		t.Fatalf("Expected broken chain, got %v", err)
+	}
+}
+
+func TestAuditTampering(t *testing.T) {
+	dir := createTmpDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "audit.log")
+
+	l, err := Open(fileName, 0)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "a", "b", "c")
+	l.Close()
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	// Split the content into the file version and the 3 records.
+	parts := [][]byte{content[:4]}
+	for pos := 4; pos < len(content); {
+		end := pos + recordHeaderSize + int(util.ByteOrder.Uint32(content[pos:pos+4]))
+		parts = append(parts, content[pos:end])
+		pos = end
+	}
+	join := func(indexes ...int) []byte {
+		var b []byte
+		for _, i := range indexes {
+			b = append(b, parts[i]...)
+		}
+		return b
+	}
+	for _, test := range []struct {
+		name    string
+		content []byte
+		err     string
+	}{
+		{"modified", append(join(0, 1, 2, 3)[:len(content)-1], 'x'), "corrupted"},
+		{"truncated", content[:len(content)-1], "unable to read payload"},
+		{"removed", join(0, 1, 3), "broken chain"},
+		{"reordered", join(0, 1, 3, 2), "broken chain"},
+	} {
+		if err := ioutil.WriteFile(fileName, test.content, 0600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+		if _, err := ReadFile(fileName, nil, nil); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%s: expected error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+
+	// Opening a corrupted audit log rotates it and records the error.
+	ioutil.WriteFile(fileName, content[:len(content)-1], 0600)
+	l, err = Open(fileName, 0)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "d")
+	l.Close()
+	files := auditFiles(t, fileName)
+	if len(files) != 2 {
+		t.Fatalf("Expected corrupted file to be rotated, got %v", files)
+	}
+	recs, err := readRecords(t, fileName)
+	if err != nil || len(recs) != 2 || recs[0].Event != EventAuditCorrupted || recs[1].ClientID != "d" {
+		t.Fatalf("Unexpected result: recs=%v err=%v", recs, err)
+	}
+}
+
+func TestAuditOpen(t *testing.T) {
+	dir := createTmpDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "audit.log")
+
+	l := New(fileName, 0)
+	if err := l.Write(EventClientConnect, "cluster", "a", ""); err != ErrNotOpened {
+		t.Fatalf("Expected error %v, got %v", ErrNotOpened, err)
+	}
+	// The file is not created until the log is opened.
+	if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Fatalf("Expected file to not exist, got %v", err)
+	}
+	if err := l.Open(); err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	writeRecords(t, l, "a")
+	l.Close()
+	if err := l.Open(); err != ErrClosed {
+		t.Fatalf("Expected error %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestAuditRotationFailure(t *testing.T) {
+	dir := createTmpDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "audit.log")
+
+	l, err := Open(fileName, 100)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	defer l.Close()
+	writeRecords(t, l, "a")
+	// Removing the file causes the rotation to fail.
+	if err := os.Remove(fileName); err != nil {
+		t.Fatalf("Error removing file: %v", err)
+	}
+	if err := l.Write(EventClientConnect, "cluster", "b", "hb_inbox=inbox"); err == nil || !strings.Contains(err.Error(), "unable to rotate") {
+		t.Fatalf("Expected rotation error, got %v", err)
+	}
+	// The record was written to a new file, and the log is still usable.
+	writeRecords(t, l, "c")
+	recs, err := readRecords(t, auditFiles(t, fileName)...)
+	if err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	if len(recs) != 2 || recs[0].ClientID != "b" || recs[1].ClientID != "c" || len(recs[0].PrevHash) == 0 {
+		t.Fatalf("Unexpected records: %v", recs)
+	}
+}
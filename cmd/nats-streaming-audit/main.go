@@ -0,0 +1,81 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats-streaming-server/audit"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+var usageStr = `
+Usage: nats-streaming-audit [options] <file> [<file>...]
+
+Prints the records of the audit log files of a NATS Streaming Server,
+verifying their integrity. To verify the chaining across rotated files,
+list the files from the oldest to the current one, for instance:
+
+    nats-streaming-audit audit.log.* audit.log
+
+Options:
+    -verify                          Verify the files without printing the records
+    -h, --help                       Show this message
+`
+
+// usage will print out the flag options.
+func usage() {
+	fmt.Printf("%s\n", usageStr)
+	os.Exit(0)
+}
+
+func main() {
+	var verifyOnly bool
+
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	fs.Usage = usage
+	fs.BoolVar(&verifyOnly, "verify", false, "Verify only")
+	fs.Parse(os.Args[1:])
+	if fs.NArg() == 0 {
+		usage()
+	}
+
+	var (
+		prevHash []byte
+		err      error
+		count    = 0
+	)
+	cb := func(rec *spb.AuditRecord) error {
+		count++
+		if verifyOnly {
+			return nil
+		}
+		return printRecord(rec)
+	}
+	for _, file := range fs.Args() {
+		if prevHash, err = audit.ReadFile(file, prevHash, cb); err != nil {
+			fmt.Fprintf(os.Stderr, "Verification of %q failed: %v\n", file, err)
+			os.Exit(1)
+		}
+	}
+	if verifyOnly {
+		fmt.Printf("Verified %v records in %v files\n", count, fs.NArg())
+	}
+}
+
+// printRecord prints an audit record on a single line.
+func printRecord(rec *spb.AuditRecord) error {
+	ts := time.Unix(0, rec.Timestamp).UTC().Format(time.RFC3339Nano)
+	line := fmt.Sprintf("%s [%s] %s", ts, rec.ClusterID, rec.Event)
+	if rec.ClientID != "" {
+		line += " client=" + rec.ClientID
+	}
+	if rec.Details != "" {
+		line += " " + rec.Details
+	}
+	_, err := fmt.Println(line)
+	return err
+}
@@ -24,6 +24,9 @@ Streaming Server Options:
     -mm,  --max_msgs <int>           Max number of messages per channel (0 for unlimited)
     -mb,  --max_bytes <size>         Max messages total size per channel (0 for unlimited)
     -ma,  --max_age <duration>       Max duration a message can be stored ("0s" for unlimited)
+          --max_clients <int>        Max number of clients (0 for unlimited)
+          --max_client_subs <int>    Max number of subscriptions per client (0 for unlimited)
+          --max_durable_inactivity <duration>  Max duration a durable can stay offline before being removed ("0s" for unlimited)
     -ns,  --nats_server <string>     Connect to this external NATS Server URL (embedded otherwise)
     -sc,  --stan_config <string>     Streaming server configuration file
     -hbi, --hb_interval <duration>   Interval at which server sends heartbeat to a client
@@ -31,6 +34,9 @@ Streaming Server Options:
     -hbf, --hb_fail_count <int>      Number of failed heartbeats before server closes the client connection
           --ack_subs <int>           Number of internal subscriptions handling incoming ACKs (0 means one per client's subscription)
           --ft_group <string>        Name of the FT Group. A group can be 2 or more servers with a single active server and all sharing the same datastore.
+          --credentials_file <string> File containing the clients credentials. If set, clients must present a valid token to connect.
+          --audit_log <string>       File of the audit log of administrative and security-relevant actions.
+          --audit_log_max_size <size> Size at which the audit log file is rotated (0 means no rotation).
 
 Streaming Server File Store Options:
     --file_compact_enabled <bool>        Enable file compaction
@@ -0,0 +1,36 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nats-streaming-server/audit"
+)
+
+// auditf records an event in the audit log, if enabled. Failures to
+// write to the audit log are logged but do not fail the operation.
+// Events that occur while the server is a FT standby, which does not
+// open the audit log, are not recorded.
+func (s *StanServer) auditf(event, clientID, format string, args ...interface{}) {
+	if s.auditLog == nil {
+		return
+	}
+	details := fmt.Sprintf(format, args...)
+	if err := s.auditLog.Write(event, s.opts.ID, clientID, details); err != nil && err != audit.ErrNotOpened {
+		s.log.Errorf("Error recording %q event in audit log: %v", event, err)
+	}
+}
+
+// durableDetails returns the details of a durable subscription event.
+// For a durable queue group, `durableName` may be empty if it is already
+// part of `qgroup`.
+func durableDetails(subject, durableName, qgroup string) string {
+	if qgroup != "" {
+		if durableName != "" {
+			return fmt.Sprintf("channel=%s durable=%s queue=%s", subject, durableName, qgroup)
+		}
+		return fmt.Sprintf("channel=%s queue=%s", subject, qgroup)
+	}
+	return fmt.Sprintf("channel=%s durable=%s", subject, durableName)
+}
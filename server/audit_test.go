@@ -0,0 +1,183 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/audit"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+func TestAuditLogEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unable to create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Remove(reloadConfFile)
+
+	auditLog := filepath.Join(dir, "audit.log")
+	s := runReloadServer(t, `audit_log: "`+auditLog+`"`)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	dur, err := sc.Subscribe("foo", func(_ *stan.Msg) {}, stan.DurableName("dur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// Restarting a durable does not create it.
+	dur.Close()
+	if dur, err = sc.Subscribe("foo", func(_ *stan.Msg) {}, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := dur.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error on unsubscribe: %v", err)
+	}
+	// Durable queue groups are deleted when the last member leaves.
+	qsub1, err := sc.QueueSubscribe("foo", "group", func(_ *stan.Msg) {}, stan.DurableName("qdur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	qsub2, err := sc.QueueSubscribe("foo", "group", func(_ *stan.Msg) {}, stan.DurableName("qdur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	qsub1.Unsubscribe()
+	qsub2.Unsubscribe()
+	sc.Close()
+
+	writeReloadConfig(t, `audit_log: "`+auditLog+`"`+"\nhb_interval: \"10s\"")
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	writeReloadConfig(t, `audit_log: "other.log"`)
+	if err := s.Reload(); err == nil {
+		t.Fatal("Expected reload to fail")
+	}
+
+	// Dead clients are closed.
+	s.optsMu.Lock()
+	s.opts.ClientHBInterval = 50 * time.Millisecond
+	s.opts.ClientHBTimeout = 10 * time.Millisecond
+	s.opts.ClientHBFailCount = 1
+	s.optsMu.Unlock()
+	dead, err := stan.Connect(clusterName, "dead")
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	dead.NatsConn().Close()
+	waitForNumClients(t, s, 0)
+	s.Shutdown()
+
+	type event struct {
+		name     string
+		clientID string
+		details  string
+	}
+	var events []event
+	if _, err := audit.ReadFile(auditLog, nil, func(rec *spb.AuditRecord) error {
+		if rec.ClusterID != clusterName {
+			t.Fatalf("Unexpected cluster ID: %v", rec.ClusterID)
+		}
+		e := event{name: rec.Event, clientID: rec.ClientID}
+		switch rec.Event {
+		case audit.EventClientConnect, audit.EventConfigReloadFail:
+		default:
+			e.details = rec.Details
+		}
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	expected := []event{
+		{audit.EventClientConnect, clientName, ""},
+		{audit.EventDurableCreate, clientName, "channel=foo durable=dur"},
+		{audit.EventDurableDelete, clientName, "channel=foo durable=dur"},
+		{audit.EventDurableCreate, clientName, "channel=foo queue=qdur:group"},
+		{audit.EventDurableDelete, clientName, "channel=foo queue=qdur:group"},
+		{audit.EventClientClose, clientName, "reason=close_request"},
+		{audit.EventConfigReload, "", "changes=hb_interval=10s"},
+		{audit.EventConfigReloadFail, "", ""},
+		{audit.EventClientConnect, "dead", ""},
+		{audit.EventClientClose, "dead", "reason=heartbeat_timeout"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, events)
+	}
+	for i, e := range expected {
+		if events[i] != e {
+			t.Fatalf("Expected event %v, got %v", e, events[i])
+		}
+	}
+}
+
+func TestAuditLogDurableExpire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unable to create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	auditLog := filepath.Join(dir, "audit.log")
+	opts := GetDefaultOptions()
+	opts.AuditLog = auditLog
+	opts.MaxDurableInactivity = 100 * time.Millisecond
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	dur, err := sc.Subscribe("foo", func(_ *stan.Msg) {}, stan.DurableName("dur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	dur.Close()
+	qdur, err := sc.QueueSubscribe("bar", "group", func(_ *stan.Msg) {}, stan.DurableName("qdur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	qdur.Close()
+	waitForCount(t, 0, func() (string, int) {
+		n := 0
+		for _, c := range s.channels.getAll() {
+			c.ss.RLock()
+			n += len(c.ss.durables) + len(c.ss.qsubs)
+			c.ss.RUnlock()
+		}
+		return "durables", n
+	})
+	s.Shutdown()
+
+	// The durable and queue names of the durable queue group are reported
+	// separately, not as the compound name used internally.
+	expected := map[string]bool{
+		"channel=foo durable=dur":              false,
+		"channel=bar durable=qdur queue=group": false,
+	}
+	if _, err := audit.ReadFile(auditLog, nil, func(rec *spb.AuditRecord) error {
+		if rec.Event != audit.EventDurableExpire {
+			return nil
+		}
+		details := rec.Details[:strings.Index(rec.Details, " offline_since=")]
+		if _, ok := expected[details]; !ok {
+			t.Fatalf("Unexpected expire event details: %q", rec.Details)
+		}
+		expected[details] = true
+		return nil
+	}); err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	for details, found := range expected {
+		if !found {
+			t.Fatalf("Missing expire event %q", details)
+		}
+	}
+}
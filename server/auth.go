@@ -0,0 +1,231 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/nats-io/gnatsd/conf"
+)
+
+// Methods with which clients authenticate, depending on the
+// credentials they presented.
+const (
+	authMethodToken = "token"
+	authMethodHMAC  = "hmac"
+)
+
+// clientIdentity is the identity bound to an authenticated client on
+// connect: the credential it presented, and the ID of its connection,
+// that the client sends with each of its requests.
+type clientIdentity struct {
+	method     string
+	credential [sha256.Size]byte // hash of the presented token
+	connID     string
+}
+
+// credentials holds the clients credentials loaded from the
+// credentials file. A client authenticates either with the token
+// defined for its client ID or, if there is none and a HMAC key is
+// defined, with the hex-encoded HMAC-SHA256 of its client ID. The admin
+// token, if defined, authorizes the administrative requests received
+// from NATS.
+type credentials struct {
+	tokens     map[string]string
+	hmacKey    []byte
+	adminToken string
+}
+
+// loadCredentials parses the given credentials file.
+func loadCredentials(file string) (*credentials, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m, err := conf.Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	creds := &credentials{tokens: make(map[string]string)}
+	for k, v := range m {
+		name := strings.ToLower(k)
+		switch name {
+		case "clients":
+			if err := parseClientsCredentials(v, creds); err != nil {
+				return nil, err
+			}
+		case "hmac_key":
+			if err := checkType(k, reflect.String, v); err != nil {
+				return nil, err
+			}
+			creds.hmacKey = []byte(v.(string))
+		case "admin_token":
+			if err := checkType(k, reflect.String, v); err != nil {
+				return nil, err
+			}
+			creds.adminToken = v.(string)
+		}
+	}
+	if !creds.authClients() && creds.adminToken == "" {
+		return nil, fmt.Errorf("no credentials found in %q", file)
+	}
+	return creds, nil
+}
+
+// authClients returns true if clients credentials are defined, in which
+// case clients must authenticate on connect.
+func (c *credentials) authClients() bool {
+	return len(c.tokens) > 0 || len(c.hmacKey) > 0
+}
+
+// parseClientsCredentials updates `creds` with the clients tokens.
+func parseClientsCredentials(itf interface{}, creds *credentials) error {
+	list, ok := itf.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected clients credentials to be an array, got %v", itf)
+	}
+	for _, c := range list {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected client credentials to be a map/struct, got %v", c)
+		}
+		var clientID, token string
+		for k, v := range m {
+			name := strings.ToLower(k)
+			switch name {
+			case "client_id", "client", "cid":
+				if err := checkType(k, reflect.String, v); err != nil {
+					return err
+				}
+				clientID = v.(string)
+			case "token":
+				if err := checkType(k, reflect.String, v); err != nil {
+					return err
+				}
+				token = v.(string)
+			}
+		}
+		if !clientIDRegEx.MatchString(clientID) || token == "" {
+			return fmt.Errorf("client credentials require a valid client_id and a token, got %v", m)
+		}
+		creds.tokens[clientID] = token
+	}
+	return nil
+}
+
+// authenticate checks the token presented by the client on the
+// connection `connID` and returns the identity bound to the client.
+func (c *credentials) authenticate(clientID, token, connID string) (*clientIdentity, error) {
+	method := ""
+	if expected, ok := c.tokens[clientID]; ok {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			method = authMethodToken
+		}
+	} else if len(c.hmacKey) > 0 && hmac.Equal([]byte(token), []byte(signClientID(c.hmacKey, clientID))) {
+		method = authMethodHMAC
+	}
+	if method == "" {
+		return nil, ErrInvalidCredentials
+	}
+	return &clientIdentity{
+		method:     method,
+		credential: sha256.Sum256([]byte(token)),
+		connID:     connID,
+	}, nil
+}
+
+// sameCredential returns true if both identities were authenticated with
+// the same credential, which allows a new connection to take over the
+// client ID bound to `id`.
+func (id *clientIdentity) sameCredential(other *clientIdentity) bool {
+	return other != nil && id.method == other.method &&
+		subtle.ConstantTimeCompare(id.credential[:], other.credential[:]) == 1
+}
+
+// checkIdentity returns true if `connID` is the ID of the connection bound
+// to the client on connect. A client that is not bound to a connection,
+// because it was recovered from the store or connected before clients
+// authentication was enabled, is accepted only if `authRequired` is false.
+func (c *client) checkIdentity(connID string, authRequired bool) bool {
+	c.RLock()
+	ok := c.identityMatches(connID, authRequired)
+	c.RUnlock()
+	return ok
+}
+
+// identityMatches is checkIdentity without locking.
+// Client lock held on entry.
+func (c *client) identityMatches(connID string, authRequired bool) bool {
+	if c.identity == nil {
+		return !authRequired
+	}
+	return c.identity.connID == connID
+}
+
+// authRequired returns true if clients must be authenticated, in which
+// case their requests must come from the connection bound on connect.
+func (s *StanServer) authRequired() bool {
+	creds := s.getCredentials()
+	return creds != nil && creds.authClients()
+}
+
+// checkClientIdentity returns ErrInvalidCredentials if the request of the
+// client `clientID`, sent with `connID`, does not come from the connection
+// bound to the client, or if the client is not bound to a connection while
+// clients must be authenticated. The requests of unknown clients are left
+// to the callers.
+func (s *StanServer) checkClientIdentity(clientID, connID string) error {
+	if c := s.clients.lookup(clientID); c != nil && !c.checkIdentity(connID, s.authRequired()) {
+		s.log.Errorf("[Client:%s] Request rejected; it does not come from the client's connection", clientID)
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// checkAdminToken returns ErrAdminNotAuthorized unless an admin token is
+// defined in the credentials file and `token` matches it. Administrative
+// requests, received from NATS or HTTP, are otherwise rejected.
+func (s *StanServer) checkAdminToken(token string) error {
+	creds := s.getCredentials()
+	if creds == nil || creds.adminToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(creds.adminToken)) != 1 {
+		return ErrAdminNotAuthorized
+	}
+	return nil
+}
+
+// bearerToken returns the token of the "Authorization: Bearer <token>"
+// header of `r`, which carries the admin token of administrative requests
+// received over HTTP, or "" if there is no such header.
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(auth, prefix)
+}
+
+// signClientID returns the hex-encoded HMAC-SHA256 of the client ID.
+func signClientID(key []byte, clientID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(clientID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// getCredentials returns the clients credentials, which can be
+// changed on configuration reload.
+func (s *StanServer) getCredentials() *credentials {
+	s.optsMu.RLock()
+	creds := s.creds
+	s.optsMu.RUnlock()
+	return creds
+}
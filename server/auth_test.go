@@ -0,0 +1,345 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nuid"
+)
+
+const credsFile = "credentials.conf"
+
+func writeCredentials(t *testing.T, content string) {
+	if err := ioutil.WriteFile(credsFile, []byte(content), 0660); err != nil {
+		stackFatalf(t, "Unexpected error creating credentials file: %v", err)
+	}
+}
+
+// authConnect sends a connect request for `clientID`, authenticated with
+// `token`, on the connection `connID`, and returns the response.
+func authConnect(t *testing.T, s *StanServer, nc *nats.Conn, clientID, token, connID string) *spb.ConnectResponse {
+	req := &spb.ConnectRequest{
+		ClientID:       clientID,
+		HeartbeatInbox: nats.NewInbox(),
+		AuthToken:      token,
+		ConnID:         connID,
+		Protocol:       protocolVersion,
+		Capabilities:   uint32(serverCapabilities),
+	}
+	cr := &spb.ConnectResponse{}
+	sendRawRequest(t, nc, s.info.Discovery, req, cr)
+	return cr
+}
+
+func expectConnectErr(t *testing.T, clientID string, expectedErr error, options ...stan.Option) {
+	sc, err := stan.Connect(clusterName, clientID, options...)
+	if sc != nil {
+		sc.Close()
+	}
+	if err == nil || err.Error() != expectedErr.Error() {
+		stackFatalf(t, "Expected error %v, got %v", expectedErr, err)
+	}
+}
+
+func expectAuthConnectErr(t *testing.T, s *StanServer, nc *nats.Conn, clientID, token string, expectedErr error) {
+	if cr := authConnect(t, s, nc, clientID, token, nuid.Next()); cr.Error != expectedErr.Error() {
+		stackFatalf(t, "Expected error %v, got %q", expectedErr, cr.Error)
+	}
+}
+
+func TestAuthInvalidCredentials(t *testing.T) {
+	defer os.Remove(credsFile)
+	for _, content := range []string{
+		"",
+		"hmac_key: 123",
+		"clients: xxx",
+		"clients: [{client_id: \"me\"}]",
+		"clients: [{client_id: \"a.b\", token: \"secret\"}]",
+		"clients: [{client_id: \"me\", token: 123}]",
+		"admin_token: 123",
+	} {
+		writeCredentials(t, content)
+		opts := GetDefaultOptions()
+		opts.CredentialsFile = credsFile
+		s, err := RunServerWithOpts(opts, nil)
+		if s != nil || err == nil || !strings.Contains(err.Error(), "unable to load credentials") {
+			if s != nil {
+				s.Shutdown()
+			}
+			t.Fatalf("Expected error for credentials %q, got %v", content, err)
+		}
+	}
+	// Missing file
+	opts := GetDefaultOptions()
+	opts.CredentialsFile = "missing_credentials.conf"
+	if s, err := RunServerWithOpts(opts, nil); s != nil || err == nil {
+		if s != nil {
+			s.Shutdown()
+		}
+		t.Fatal("Expected error for missing credentials file")
+	}
+}
+
+func TestAuthConnect(t *testing.T) {
+	defer os.Remove(credsFile)
+	writeCredentials(t, `
+		clients: [{client_id: "me", token: "secret"}]
+		hmac_key: "key"
+	`)
+	opts := GetDefaultOptions()
+	opts.ID = clusterName
+	opts.CredentialsFile = credsFile
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+
+	if cr := authConnect(t, s, nc, "me", "secret", nuid.Next()); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+	if cr := authConnect(t, s, nc, "other", signClientID([]byte("key"), "other"), nuid.Next()); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+
+	// No token, wrong token, or the HMAC of a client that has a token.
+	expectAuthConnectErr(t, s, nc, "me", "", ErrInvalidCredentials)
+	expectAuthConnectErr(t, s, nc, "me", "wrong", ErrInvalidCredentials)
+	expectAuthConnectErr(t, s, nc, "me", signClientID([]byte("key"), "me"), ErrInvalidCredentials)
+	expectAuthConnectErr(t, s, nc, "other", signClientID([]byte("key"), "another"), ErrInvalidCredentials)
+	expectAuthConnectErr(t, s, nc, "other", signClientID([]byte("wrong"), "other"), ErrInvalidCredentials)
+
+	// Authenticated clients must send the ID of their connection.
+	if cr := authConnect(t, s, nc, "third", signClientID([]byte("key"), "third"), ""); cr.Error != ErrInvalidConnReq.Error() {
+		t.Fatalf("Expected error %v, got %q", ErrInvalidConnReq, cr.Error)
+	}
+
+	// A new connection with the same credential takes over the client
+	// ID of a connection that does not answer heartbeats.
+	if cr := authConnect(t, s, nc, "me", "secret", nuid.Next()); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+}
+
+func TestAuthRequestsIdentity(t *testing.T) {
+	defer os.Remove(credsFile)
+	writeCredentials(t, `clients: [{client_id: "me", token: "secret"}]`)
+	opts := GetDefaultOptions()
+	opts.ID = clusterName
+	opts.CredentialsFile = credsFile
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+
+	connID := nuid.Next()
+	cr := authConnect(t, s, nc, "me", "secret", connID)
+	if cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+	c := s.clients.lookup("me")
+	c.RLock()
+	hbInbox := c.info.HbInbox
+	c.RUnlock()
+
+	// Each request naming the client is rejected if it is sent without the
+	// ID of the connection bound to the client, or with another one.
+	subInbox := nats.NewInbox()
+	for _, wrongID := range []string{"", nuid.Next()} {
+		pa := &spb.PubAck{}
+		sendRawRequest(t, nc, cr.PubPrefix+".foo",
+			&spb.PubMsg{ClientID: "me", ConnID: wrongID, Guid: nuid.Next(), Subject: "foo"}, pa)
+		if pa.Error != ErrInvalidCredentials.Error() {
+			t.Fatalf("Expected error %v on publish, got %q", ErrInvalidCredentials, pa.Error)
+		}
+		ba := &spb.PubBatchAck{}
+		sendRawRequest(t, nc, cr.PubBatchRequests, &spb.PubBatch{ClientID: "me", ConnID: wrongID,
+			Msgs: []*spb.PubMsg{{Guid: nuid.Next(), Subject: "foo"}}}, ba)
+		if ba.Error != ErrInvalidCredentials.Error() {
+			t.Fatalf("Expected error %v on batch publish, got %q", ErrInvalidCredentials, ba.Error)
+		}
+		sr := &pb.SubscriptionResponse{}
+		sendRawRequest(t, nc, cr.SubRequests, &spb.SubscriptionRequest{ClientID: "me", ConnID: wrongID,
+			Subject: "foo", Inbox: subInbox, MaxInFlight: 1, AckWaitInSecs: 30}, sr)
+		if sr.Error != ErrInvalidCredentials.Error() {
+			t.Fatalf("Expected error %v on subscribe, got %q", ErrInvalidCredentials, sr.Error)
+		}
+		pr := &spb.PingResponse{}
+		sendRawRequest(t, nc, cr.PingRequests, &spb.Ping{ClientID: "me", ConnID: wrongID, HeartbeatInbox: hbInbox}, pr)
+		if pr.Error != ErrInvalidCredentials.Error() {
+			t.Fatalf("Expected error %v on ping, got %q", ErrInvalidCredentials, pr.Error)
+		}
+	}
+
+	// With the right connection ID, requests are accepted.
+	pa := &spb.PubAck{}
+	sendRawRequest(t, nc, cr.PubPrefix+".foo",
+		&spb.PubMsg{ClientID: "me", ConnID: connID, Guid: nuid.Next(), Subject: "foo"}, pa)
+	if pa.Error != "" {
+		t.Fatalf("Unexpected error on publish: %v", pa.Error)
+	}
+	sr := &pb.SubscriptionResponse{}
+	sendRawRequest(t, nc, cr.SubRequests, &spb.SubscriptionRequest{ClientID: "me", ConnID: connID,
+		Subject: "foo", Inbox: subInbox, MaxInFlight: 1, AckWaitInSecs: 30}, sr)
+	if sr.Error != "" {
+		t.Fatalf("Unexpected error on subscribe: %v", sr.Error)
+	}
+	ackInbox := sr.AckInbox
+
+	// The requests on the subscription, and the close of the connection,
+	// are rejected too.
+	wrongID := nuid.Next()
+	for _, subj := range []string{cr.SubPauseRequests, cr.SubCloseRequests, cr.UnsubRequests} {
+		var req interface {
+			Marshal() ([]byte, error)
+		}
+		if subj == cr.SubPauseRequests {
+			req = &spb.SubPauseRequest{ClientID: "me", ConnID: wrongID, Subject: "foo", Inbox: ackInbox, Pause: true}
+		} else {
+			req = &spb.UnsubscribeRequest{ClientID: "me", ConnID: wrongID, Subject: "foo", Inbox: ackInbox}
+		}
+		resp := &pb.SubscriptionResponse{}
+		sendRawRequest(t, nc, subj, req, resp)
+		if resp.Error != ErrInvalidCredentials.Error() {
+			t.Fatalf("Expected error %v on %q, got %q", ErrInvalidCredentials, subj, resp.Error)
+		}
+	}
+	checkSubs := func(expected int) {
+		if subs := s.clients.getSubs("me"); len(subs) != expected {
+			stackFatalf(t, "Expected %v subscriptions, got %v", expected, len(subs))
+		}
+	}
+	checkSubs(1)
+	resp := &pb.SubscriptionResponse{}
+	sendRawRequest(t, nc, cr.UnsubRequests,
+		&spb.UnsubscribeRequest{ClientID: "me", ConnID: connID, Subject: "foo", Inbox: ackInbox}, resp)
+	if resp.Error != "" {
+		t.Fatalf("Unexpected error on unsubscribe: %v", resp.Error)
+	}
+	checkSubs(0)
+
+	closeResp := &pb.CloseResponse{}
+	sendRawRequest(t, nc, cr.CloseRequests, &spb.CloseRequest{ClientID: "me", ConnID: wrongID}, closeResp)
+	if closeResp.Error != ErrInvalidCredentials.Error() {
+		t.Fatalf("Expected error %v on close, got %q", ErrInvalidCredentials, closeResp.Error)
+	}
+	checkClients(t, s, 1)
+	closeResp = &pb.CloseResponse{}
+	sendRawRequest(t, nc, cr.CloseRequests, &spb.CloseRequest{ClientID: "me", ConnID: connID}, closeResp)
+	if closeResp.Error != "" {
+		t.Fatalf("Unexpected error on close: %v", closeResp.Error)
+	}
+	checkClients(t, s, 0)
+}
+
+func TestAuthReload(t *testing.T) {
+	defer os.Remove(credsFile)
+	defer os.Remove(reloadConfFile)
+	writeCredentials(t, `hmac_key: "key"`)
+	s := runReloadServer(t, `credentials_file: "`+credsFile+`"`)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	// The client is left registered without answering the server's
+	// heartbeats.
+	if cr := authConnect(t, s, nc, "me", signClientID([]byte("key"), "me"), nuid.Next()); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+
+	// Invalid credentials are rejected.
+	writeCredentials(t, `hmac_key: 123`)
+	if err := s.Reload(); err == nil || !strings.Contains(err.Error(), "unable to load credentials") {
+		t.Fatalf("Expected error, got %v", err)
+	}
+
+	writeCredentials(t, `
+		clients: [{client_id: "me", token: "secret"}]
+		hmac_key: "key"
+	`)
+	changes, err := s.reload()
+	if err != nil || len(changes) != 1 || changes[0] != "credentials" {
+		t.Fatalf("Unexpected result: changes=%v err=%v", changes, err)
+	}
+	// The client ID is bound to the HMAC identity, so it cannot be taken
+	// over with a token, even a valid one.
+	expectAuthConnectErr(t, s, nc, "me", "secret", ErrInvalidCredentials)
+
+	// Same file content, no change.
+	changes, err = s.reload()
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("Unexpected result: changes=%v err=%v", changes, err)
+	}
+}
+
+func TestAuthRecoveredClients(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+	defer os.Remove(credsFile)
+	writeCredentials(t, `clients: [{client_id: "me", token: "secret"}]`)
+	opts := getTestDefaultOptsForPersistentStore()
+	opts.CredentialsFile = credsFile
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	connID := nuid.Next()
+	if cr := authConnect(t, s, nc, "me", "secret", connID); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+
+	// The connection binding is not persisted, so once recovered, the
+	// client's requests are rejected until it authenticates again.
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+	s.dupCIDTimeout = 100 * time.Millisecond
+	checkClients(t, s, 1)
+	pa := &spb.PubAck{}
+	sendRawRequest(t, nc, s.info.Publish+".foo",
+		&spb.PubMsg{ClientID: "me", ConnID: connID, Guid: nuid.Next(), Subject: "foo"}, pa)
+	if pa.Error != ErrInvalidCredentials.Error() {
+		t.Fatalf("Expected error %v on publish, got %q", ErrInvalidCredentials, pa.Error)
+	}
+	closeResp := &pb.CloseResponse{}
+	sendRawRequest(t, nc, s.info.Close, &spb.CloseRequest{ClientID: "me", ConnID: connID}, closeResp)
+	if closeResp.Error != ErrInvalidCredentials.Error() {
+		t.Fatalf("Expected error %v on close, got %q", ErrInvalidCredentials, closeResp.Error)
+	}
+	checkClients(t, s, 1)
+
+	// The client does not answer the heartbeats sent to its old inbox, so
+	// a new connection with the same credential replaces it.
+	connID = nuid.Next()
+	if cr := authConnect(t, s, nc, "me", "secret", connID); cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+	pa = &spb.PubAck{}
+	sendRawRequest(t, nc, s.info.Publish+".foo",
+		&spb.PubMsg{ClientID: "me", ConnID: connID, Guid: nuid.Next(), Subject: "foo"}, pa)
+	if pa.Error != "" {
+		t.Fatalf("Unexpected error on publish: %v", pa.Error)
+	}
+}
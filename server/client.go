@@ -3,31 +3,51 @@
 package server
 
 import (
-	"github.com/nats-io/nats-streaming-server/stores"
 	"sync"
 	"time"
+
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/stores"
 )
 
 // This is a proxy to the store interface.
 type clientStore struct {
 	sync.RWMutex
-	clients map[string]*client
-	store   stores.Store
+	clients    map[string]*client
+	store      stores.Store
+	maxClients int // 0 means unlimited
 }
 
 // client has information needed by the server. A client is also
-// stored in a stores.Client object (which contains ID and HbInbox).
+// stored in a stores.Client object (which contains ID, HbInbox and
+// the optional metadata sent by the client).
 type client struct {
 	sync.RWMutex
-	info *stores.Client
-	hbt  *time.Timer
-	fhb  int
-	subs []*subState
+	info     *stores.Client
+	hbt      *time.Timer
+	fhb      int
+	lastHB   time.Time // time of the last heartbeat response
+	subs     []*subState
+	identity *clientIdentity // bound on connect when clients are authenticated
+	// Messages published during the current second, and number of
+	// requests rejected because of the client's quotas.
+	pubSample    rateSample
+	rejectedSubs uint64
+	rejectedPubs uint64
+	// Time of the last ping received from the client, and timer checking
+	// the client's health if it stops sending pings.
+	lastPing  time.Time
+	pingTimer *time.Timer
+	// Set when the client stopped sending pings.
+	pingsMissed bool
 }
 
 // newClientStore creates a new clientStore instance using `store` as the backing storage.
 func newClientStore(store stores.Store) *clientStore {
-	return &clientStore{clients: make(map[string]*client), store: store}
+	return &clientStore{
+		clients: make(map[string]*client),
+		store:   store,
+	}
 }
 
 // getSubsCopy returns a copy of the client's subscribers array.
@@ -38,24 +58,45 @@ func (c *client) getSubsCopy() []*subState {
 	return subs
 }
 
+// hasCapability returns true if `capability` has been negotiated with
+// the client.
+func (c *client) hasCapability(capability spb.Capability) bool {
+	c.RLock()
+	has := c.info.Capabilities&uint32(capability) != 0
+	c.RUnlock()
+	return has
+}
+
 // Register a client if new, otherwise returns the client already registered
-// and `false` to indicate that the client is not new.
-func (cs *clientStore) register(ID, hbInbox string) (*client, bool, error) {
+// and `false` to indicate that the client is not new. Returns ErrTooManyClients
+// if the client is new and the maximum number of clients is reached.
+func (cs *clientStore) register(info *spb.ClientInfo) (*client, bool, error) {
 	cs.Lock()
 	defer cs.Unlock()
-	c := cs.clients[ID]
+	c := cs.clients[info.ID]
 	if c != nil {
 		return c, false, nil
 	}
-	sc, err := cs.store.AddClient(ID, hbInbox)
+	if cs.maxClients > 0 && len(cs.clients) >= cs.maxClients {
+		return nil, false, ErrTooManyClients
+	}
+	sc, err := cs.store.AddClientInfo(info)
 	if err != nil {
 		return nil, false, err
 	}
 	c = &client{info: sc, subs: make([]*subState, 0, 4)}
-	cs.clients[ID] = c
+	cs.clients[info.ID] = c
 	return c, true, nil
 }
 
+// setMaxClients sets the maximum number of clients. This does not
+// affect the clients already registered.
+func (cs *clientStore) setMaxClients(maxClients int) {
+	cs.Lock()
+	cs.maxClients = maxClients
+	cs.Unlock()
+}
+
 // Unregister a client.
 func (cs *clientStore) unregister(ID string) (*client, error) {
 	cs.Lock()
@@ -69,6 +110,10 @@ func (cs *clientStore) unregister(ID string) (*client, error) {
 		c.hbt.Stop()
 		c.hbt = nil
 	}
+	if c.pingTimer != nil {
+		c.pingTimer.Stop()
+		c.pingTimer = nil
+	}
 	c.Unlock()
 	delete(cs.clients, ID)
 	err := cs.store.DeleteClient(ID)
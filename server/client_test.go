@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 	"github.com/nats-io/nuid"
 	"time"
@@ -33,7 +34,7 @@ func TestClientRegister(t *testing.T) {
 	clientID, hbInbox := createClientInfo()
 
 	// Register a new one
-	sc, isNew, _ := cs.register(clientID, hbInbox)
+	sc, isNew, _ := cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 	if sc == nil || !isNew {
 		t.Fatal("Expected client to be new")
 	}
@@ -64,7 +65,7 @@ func TestClientRegister(t *testing.T) {
 	}()
 
 	// Register with same info
-	secondCli, isNew, _ := cs.register(clientID, hbInbox)
+	secondCli, isNew, _ := cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 	if secondCli != sc || isNew {
 		t.Fatal("Expected to get the same client")
 	}
@@ -87,7 +88,7 @@ func TestClientParallelRegister(t *testing.T) {
 
 			for j := 0; j < totalClients; j++ {
 				clientID := fmt.Sprintf("clientID-%v", j)
-				c, isNew, _ := cs.register(clientID, hbInbox)
+				c, isNew, _ := cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 				if c == nil {
 					errors <- fmt.Errorf("client should not be nil")
 					return
@@ -126,7 +127,7 @@ func TestClientUnregister(t *testing.T) {
 	cs.unregister(clientID)
 
 	// Now register a client
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	// Verify it's in the list of clients
 	if !cs.isValid(clientID) {
@@ -153,7 +154,7 @@ func TestClientLookup(t *testing.T) {
 	}
 
 	// Registers one
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	// Lookup again
 	if c := cs.lookup(clientID); c == nil {
@@ -181,12 +182,12 @@ func TestClientGetClientIDs(t *testing.T) {
 	clientID := "me"
 	hbInbox := nuid.Next()
 
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	clientID = "me2"
 	hbInbox = nuid.Next()
 
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	clients := cs.getClients()
 	if clients == nil || len(clients) != 2 {
@@ -213,7 +214,7 @@ func TestClientAddSub(t *testing.T) {
 	}
 
 	// Now register the client
-	sc, _, _ := cs.register(clientID, hbInbox)
+	sc, _, _ := cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	// Now this should work
 	if !cs.addSub(clientID, sub) {
@@ -267,7 +268,7 @@ func TestClientAddSub(t *testing.T) {
 	insubs := 0
 	for i := 0; i < total; i++ {
 		// Register the client
-		cs.register(clientID, hbInbox)
+		cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 		runtime.Gosched()
 		c, _ := cs.unregister(clientID)
 		if sc == nil {
@@ -298,7 +299,7 @@ func TestClientRemoveSub(t *testing.T) {
 	}
 
 	// Now register the client
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	// Add a subscription
 	if !cs.addSub(clientID, sub) {
@@ -343,7 +344,7 @@ func TestClientRemoveSub(t *testing.T) {
 	insubs := 0
 	for i := 0; i < total; i++ {
 		// Register the client
-		cs.register(clientID, hbInbox)
+		cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 		cs.addSub(clientID, sub)
 		runtime.Gosched()
 		c, _ := cs.unregister(clientID)
@@ -372,7 +373,7 @@ func TestClientGetSubs(t *testing.T) {
 	}
 
 	// Now register the client
-	cs.register(clientID, hbInbox)
+	cs.register(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
 
 	// Add a subscription
 	if !cs.addSub(clientID, &subState{subject: "foo"}) {
@@ -430,7 +431,7 @@ func TestClientSetClientHBForNonExistentClient(t *testing.T) {
 
 type clientStoreErrorsStore struct{ stores.Store }
 
-func (s *clientStoreErrorsStore) AddClient(id, hbinbox string) (*stores.Client, error) {
+func (s *clientStoreErrorsStore) AddClientInfo(info *spb.ClientInfo) (*stores.Client, error) {
 	return nil, errOnPurpose
 }
 func (s *clientStoreErrorsStore) DeleteClient(id string) error {
@@ -441,7 +442,7 @@ func TestClientStoreErrors(t *testing.T) {
 	cs := createClientStore()
 
 	// Register a client
-	rc, _, err := cs.register("me", "hbInbox")
+	rc, _, err := cs.register(&spb.ClientInfo{ID: "me", HbInbox: "hbInbox"})
 	if err != nil {
 		t.Fatalf("Error during registration: %v", err)
 	}
@@ -456,7 +457,7 @@ func TestClientStoreErrors(t *testing.T) {
 	cs.Unlock()
 
 	// Register: store will fail the AddClient call
-	if _, _, err := cs.register("me2", "hbInbox"); err == nil {
+	if _, _, err := cs.register(&spb.ClientInfo{ID: "me2", HbInbox: "hbInbox"}); err == nil {
 		t.Fatal("Expected register to fail")
 	}
 	// Make sure client is not registered
@@ -41,9 +41,32 @@ func ProcessConfigFile(configFile string, opts *Options) error {
 			m = content
 		}
 	}
+	// The sections of the tenants contain the same keys as the main
+	// section, so process them separately.
+	var tenants interface{}
+	for k, v := range m {
+		if strings.ToLower(k) == "tenants" {
+			tenants = v
+			delete(m, k)
+		}
+	}
+	if err := processConfigMap(m, opts); err != nil {
+		return err
+	}
+	if tenants != nil {
+		return parseTenants(tenants, opts)
+	}
+	return nil
+}
+
+// processConfigMap updates `opts` with the content of the map `m`, which
+// is either the main streaming section or the section of a tenant.
+func processConfigMap(m map[string]interface{}, opts *Options) error {
 	for k, v := range m {
 		name := strings.ToLower(k)
 		switch name {
+		case "tenants":
+			return fmt.Errorf("tenants cannot be defined inside a tenant")
 		case "id", "cid", "cluster_id":
 			if err := checkType(k, reflect.String, v); err != nil {
 				return err
@@ -141,7 +164,56 @@ func ProcessConfigFile(configFile string, opts *Options) error {
 				return err
 			}
 			opts.Partitioning = v.(bool)
+		case "credentials", "credentials_file":
+			if err := checkType(k, reflect.String, v); err != nil {
+				return err
+			}
+			opts.CredentialsFile = v.(string)
+		case "audit_log":
+			if err := checkType(k, reflect.String, v); err != nil {
+				return err
+			}
+			opts.AuditLog = v.(string)
+		case "audit_log_max_size":
+			if err := checkType(k, reflect.Int64, v); err != nil {
+				return err
+			}
+			opts.AuditLogMaxSize = v.(int64)
+		case "permissions", "client_permissions":
+			if err := parsePermissions(v, opts); err != nil {
+				return err
+			}
+		case "client_limits", "clients_limits":
+			if err := parseClientsLimits(v, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseTenants sets the list of tenants in `opts`. The options of a
+// tenant start from the default options, not from the main options.
+func parseTenants(itf interface{}, opts *Options) error {
+	list, ok := itf.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected tenants to be an array, got %v", itf)
+	}
+	opts.Tenants = make([]*Options, 0, len(list))
+	for _, t := range list {
+		m, ok := t.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected tenant to be a map/struct, got %v", t)
 		}
+		tOpts := GetDefaultOptions()
+		tOpts.ID = ""
+		if err := processConfigMap(m, tOpts); err != nil {
+			return err
+		}
+		if tOpts.ID == "" {
+			return fmt.Errorf("missing cluster_id in tenant %v", m)
+		}
+		opts.Tenants = append(opts.Tenants, tOpts)
 	}
 	return nil
 }
@@ -252,6 +324,18 @@ func parseChannelLimits(cl *stores.ChannelLimits, k, name string, v interface{},
 		if !isGlobal && cl.MaxAge == 0 {
 			cl.MaxAge = -1
 		}
+	case "max_durable_inactivity", "maxdurableinactivity":
+		if err := checkType(k, reflect.String, v); err != nil {
+			return err
+		}
+		dur, err := time.ParseDuration(v.(string))
+		if err != nil {
+			return err
+		}
+		cl.MaxDurableInactivity = dur
+		if !isGlobal && cl.MaxDurableInactivity == 0 {
+			cl.MaxDurableInactivity = -1
+		}
 	}
 	return nil
 }
@@ -283,6 +367,153 @@ func parsePerChannelLimits(itf interface{}, opts *Options) error {
 	return nil
 }
 
+// parsePermissions updates `opts` with the clients permissions.
+func parsePermissions(itf interface{}, opts *Options) error {
+	list, ok := itf.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected permissions to be an array, got %v", itf)
+	}
+	for _, p := range list {
+		m, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected client permissions to be a map/struct, got %v", p)
+		}
+		cp := &ClientPermissions{}
+		for k, v := range m {
+			name := strings.ToLower(k)
+			switch name {
+			case "client_id", "client", "cid":
+				if err := checkType(k, reflect.String, v); err != nil {
+					return err
+				}
+				cp.ClientID = v.(string)
+			case "publish", "pub":
+				channels, err := parseStringList(k, v)
+				if err != nil {
+					return err
+				}
+				cp.Publish = channels
+			case "subscribe", "sub":
+				channels, err := parseStringList(k, v)
+				if err != nil {
+					return err
+				}
+				cp.Subscribe = channels
+			}
+		}
+		if cp.ClientID == "" {
+			return fmt.Errorf("missing client_id in client permissions %v", m)
+		}
+		opts.Permissions = append(opts.Permissions, cp)
+	}
+	return nil
+}
+
+// parseClientsLimits updates `opts` with the maximum number of clients
+// and the global and per client limits.
+func parseClientsLimits(itf interface{}, opts *Options) error {
+	m, ok := itf.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected client limits to be a map/struct, got %v", itf)
+	}
+	for k, v := range m {
+		name := strings.ToLower(k)
+		switch name {
+		case "max_clients":
+			if err := checkType(k, reflect.Int64, v); err != nil {
+				return err
+			}
+			opts.MaxClients = int(v.(int64))
+		case "clients", "per_client", "per_client_limits":
+			if err := parsePerClientLimits(v, opts); err != nil {
+				return err
+			}
+		default:
+			if err := parseClientLimits(&opts.ClientLimits, k, name, v, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseClientLimits updates `cl` with client limits.
+func parseClientLimits(cl *ClientLimits, k, name string, v interface{}, isGlobal bool) error {
+	switch name {
+	case "max_subs", "max_subscriptions":
+		if err := checkType(k, reflect.Int64, v); err != nil {
+			return err
+		}
+		cl.MaxSubscriptions = int(v.(int64))
+		if !isGlobal && cl.MaxSubscriptions == 0 {
+			cl.MaxSubscriptions = -1
+		}
+	case "max_pub_msgs", "max_pub_msgs_per_sec":
+		if err := checkType(k, reflect.Int64, v); err != nil {
+			return err
+		}
+		cl.MaxPubMsgs = int(v.(int64))
+		if !isGlobal && cl.MaxPubMsgs == 0 {
+			cl.MaxPubMsgs = -1
+		}
+	case "max_pub_bytes", "max_pub_bytes_per_sec":
+		if err := checkType(k, reflect.Int64, v); err != nil {
+			return err
+		}
+		cl.MaxPubBytes = v.(int64)
+		if !isGlobal && cl.MaxPubBytes == 0 {
+			cl.MaxPubBytes = -1
+		}
+	}
+	return nil
+}
+
+// parsePerClientLimits updates `opts` with per client limits.
+func parsePerClientLimits(itf interface{}, opts *Options) error {
+	m, ok := itf.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected per client limits to be a map/struct, got %v", itf)
+	}
+	for clientID, limits := range m {
+		limitsMap, ok := limits.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected client limits to be a map/struct, got %v", limits)
+		}
+		cl := &ClientLimits{}
+		for k, v := range limitsMap {
+			name := strings.ToLower(k)
+			if err := parseClientLimits(cl, k, name, v, false); err != nil {
+				return err
+			}
+		}
+		if opts.PerClientLimits == nil {
+			opts.PerClientLimits = make(map[string]*ClientLimits)
+		}
+		opts.PerClientLimits[clientID] = cl
+	}
+	return nil
+}
+
+// parseStringList returns the list of strings from `v`, which can
+// be a single string or an array of strings.
+func parseStringList(name string, v interface{}) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected %s to be a string or an array of strings, got %v", name, v)
+	}
+	strs := make([]string, 0, len(list))
+	for _, e := range list {
+		if err := checkType(name, reflect.String, e); err != nil {
+			return nil, err
+		}
+		strs = append(strs, e.(string))
+	}
+	return strs, nil
+}
+
 func parseFileOptions(itf interface{}, opts *Options) error {
 	m, ok := itf.(map[string]interface{})
 	if !ok {
@@ -390,6 +621,8 @@ func ConfigureOptions(fs *flag.FlagSet, args []string, printVersion, printHelp,
 	fs.StringVar(&sopts.FilestoreDir, "dir", "", "stan.FilestoreDir")
 	fs.IntVar(&sopts.MaxChannels, "max_channels", stores.DefaultStoreLimits.MaxChannels, "stan.MaxChannels")
 	fs.IntVar(&sopts.MaxChannels, "mc", stores.DefaultStoreLimits.MaxChannels, "stan.MaxChannels")
+	fs.IntVar(&sopts.MaxClients, "max_clients", 0, "stan.MaxClients")
+	fs.IntVar(&sopts.ClientLimits.MaxSubscriptions, "max_client_subs", 0, "stan.ClientLimits.MaxSubscriptions")
 	fs.IntVar(&sopts.MaxSubscriptions, "max_subs", stores.DefaultStoreLimits.MaxSubscriptions, "stan.MaxSubscriptions")
 	fs.IntVar(&sopts.MaxSubscriptions, "msu", stores.DefaultStoreLimits.MaxSubscriptions, "stan.MaxSubscriptions")
 	fs.IntVar(&sopts.MaxMsgs, "max_msgs", stores.DefaultStoreLimits.MaxMsgs, "stan.MaxMsgs")
@@ -398,6 +631,7 @@ func ConfigureOptions(fs *flag.FlagSet, args []string, printVersion, printHelp,
 	fs.String("mb", fmt.Sprintf("%v", stores.DefaultStoreLimits.MaxBytes), "stan.MaxBytes")
 	fs.DurationVar(&sopts.MaxAge, "max_age", stores.DefaultStoreLimits.MaxAge, "stan.MaxAge")
 	fs.DurationVar(&sopts.MaxAge, "ma", stores.DefaultStoreLimits.MaxAge, "stan.MaxAge")
+	fs.DurationVar(&sopts.MaxDurableInactivity, "max_durable_inactivity", stores.DefaultStoreLimits.MaxDurableInactivity, "stan.MaxDurableInactivity")
 	fs.DurationVar(&sopts.ClientHBInterval, "hbi", DefaultHeartBeatInterval, "stan.ClientHBInterval")
 	fs.DurationVar(&sopts.ClientHBInterval, "hb_interval", DefaultHeartBeatInterval, "stan.ClientHBInterval")
 	fs.DurationVar(&sopts.ClientHBTimeout, "hbt", DefaultClientHBTimeout, "stan.ClientHBTimeout")
@@ -435,6 +669,9 @@ func ConfigureOptions(fs *flag.FlagSet, args []string, printVersion, printHelp,
 	fs.IntVar(&sopts.IOBatchSize, "io_batch_size", DefaultIOBatchSize, "stan.IOBatchSize")
 	fs.Int64Var(&sopts.IOSleepTime, "io_sleep_time", DefaultIOSleepTime, "stan.IOSleepTime")
 	fs.StringVar(&sopts.FTGroupName, "ft_group", "", "stan.FTGroupName")
+	fs.StringVar(&sopts.CredentialsFile, "credentials_file", "", "stan.CredentialsFile")
+	fs.StringVar(&sopts.AuditLog, "audit_log", "", "stan.AuditLog")
+	fs.String("audit_log_max_size", "0", "stan.AuditLogMaxSize")
 
 	// First, we need to call NATS's ConfigureOptions() with above flag set.
 	// It will be augmented with NATS specific flags and call fs.Parse(args) for us.
@@ -466,6 +703,7 @@ func ConfigureOptions(fs *flag.FlagSet, args []string, printVersion, printHelp,
 		if err := ProcessConfigFile(stanConfigFile, sopts); err != nil {
 			return nil, nil, err
 		}
+		sopts.ConfigFile = stanConfigFile
 		// Need to call Parse() again to override with command line params.
 		// No need to check for errors since this has already been called
 		// in natsd.ConfigureOptions()
@@ -491,11 +729,27 @@ func ConfigureOptions(fs *flag.FlagSet, args []string, printVersion, printHelp,
 			var i64 int64
 			i64, flagErr = getBytes(f)
 			sopts.FileStoreOpts.BufferSize = int(i64)
+		case "audit_log_max_size":
+			sopts.AuditLogMaxSize, flagErr = getBytes(f)
 		}
 	})
 	if flagErr != nil {
 		return nil, nil, flagErr
 	}
+	// Keep track of the options set from the command line so that they
+	// keep precedence over the configuration file when it is reloaded.
+	// The usage of the streaming flags is the path of the option's field.
+	cmdLine := &cmdLineOptions{opts: sopts.Clone()}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "SDV" {
+			cmdLine.fields = append(cmdLine.fields, "Debug", "Trace")
+		} else if strings.HasPrefix(f.Usage, "stan.") {
+			cmdLine.fields = append(cmdLine.fields, strings.TrimPrefix(f.Usage, "stan."))
+		}
+	})
+	if len(cmdLine.fields) > 0 {
+		sopts.cmdLine = cmdLine
+	}
 	return sopts, nopts, nil
 }
 
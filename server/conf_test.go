@@ -121,6 +121,9 @@ func TestParseConfig(t *testing.T) {
 	if opts.MaxSubscriptions != 15 {
 		t.Fatalf("Expected MaxSubscriptions to be 15, got %v", opts.MaxSubscriptions)
 	}
+	if opts.MaxDurableInactivity != 16*time.Second {
+		t.Fatalf("Expected MaxDurableInactivity to be 16s, got %v", opts.MaxDurableInactivity)
+	}
 	if len(opts.PerChannel) != 2 {
 		t.Fatalf("Expected PerChannel map to have 2 elements, got %v", len(opts.PerChannel))
 	}
@@ -140,6 +143,9 @@ func TestParseConfig(t *testing.T) {
 	if cl.MaxSubscriptions != 4 {
 		t.Fatalf("Expected MaxSubscriptions to be 4, got %v", cl.MaxSubscriptions)
 	}
+	if cl.MaxDurableInactivity != 9*time.Second {
+		t.Fatalf("Expected MaxDurableInactivity to be 9s, got %v", cl.MaxDurableInactivity)
+	}
 	cl, ok = opts.PerChannel["bar"]
 	if !ok {
 		t.Fatal("Expected channel bar to be found")
@@ -171,9 +177,37 @@ func TestParseConfig(t *testing.T) {
 	if opts.FTGroupName != "ft" {
 		t.Fatalf("Expected FTGroupName to be %q, got %q", "ft", opts.FTGroupName)
 	}
+	if opts.CredentialsFile != "/path/to/credentials" {
+		t.Fatalf("Expected CredentialsFile to be %q, got %q", "/path/to/credentials", opts.CredentialsFile)
+	}
+	if opts.AuditLog != "/path/to/audit.log" {
+		t.Fatalf("Expected AuditLog to be %q, got %q", "/path/to/audit.log", opts.AuditLog)
+	}
+	if opts.AuditLogMaxSize != 1024 {
+		t.Fatalf("Expected AuditLogMaxSize to be 1024, got %v", opts.AuditLogMaxSize)
+	}
 	if !opts.Partitioning {
 		t.Fatalf("Expected Partitioning to be true, got false")
 	}
+	expectedPerms := []*ClientPermissions{
+		{ClientID: "me", Publish: []string{"foo"}, Subscribe: []string{"foo", "bar.*"}},
+		{ClientID: "svc_*", Subscribe: []string{">"}},
+	}
+	if !reflect.DeepEqual(opts.Permissions, expectedPerms) {
+		t.Fatalf("Expected Permissions to be %v, got %v", expectedPerms, opts.Permissions)
+	}
+	if opts.MaxClients != 100 {
+		t.Fatalf("Expected MaxClients to be 100, got %v", opts.MaxClients)
+	}
+	expectedLimits := ClientLimits{MaxSubscriptions: 10, MaxPubMsgs: 1000, MaxPubBytes: 2048}
+	if opts.ClientLimits != expectedLimits {
+		t.Fatalf("Expected ClientLimits to be %+v, got %+v", expectedLimits, opts.ClientLimits)
+	}
+	// A 0 value in per client limits means unlimited.
+	expectedPerClient := map[string]*ClientLimits{"loader_*": {MaxPubMsgs: -1, MaxPubBytes: 4096}}
+	if !reflect.DeepEqual(opts.PerClientLimits, expectedPerClient) {
+		t.Fatalf("Expected PerClientLimits to be %v, got %v", expectedPerClient, opts.PerClientLimits)
+	}
 }
 
 func TestParsePermError(t *testing.T) {
@@ -244,7 +278,7 @@ func TestParsePerChannelLimitsSetToZero(t *testing.T) {
 	confFile := "config.conf"
 	defer os.Remove(confFile)
 	if err := ioutil.WriteFile(confFile,
-		[]byte("store_limits: {channels: {foo: {max_msgs: 0, max_bytes: 0, max_age: \"0\", max_subs: 0}}}"), 0660); err != nil {
+		[]byte("store_limits: {channels: {foo: {max_msgs: 0, max_bytes: 0, max_age: \"0\", max_subs: 0, max_durable_inactivity: \"0\"}}}"), 0660); err != nil {
 		t.Fatalf("Unexpected error creating conf file: %v", err)
 	}
 	opts := Options{}
@@ -262,6 +296,7 @@ func TestParsePerChannelLimitsSetToZero(t *testing.T) {
 	expected.MaxBytes = -1
 	expected.MaxAge = -1
 	expected.MaxSubscriptions = -1
+	expected.MaxDurableInactivity = -1
 	if !reflect.DeepEqual(*cl, expected) {
 		t.Fatalf("Expected channel limits for foo to be %v, got %v", expected, *cl)
 	}
@@ -274,6 +309,10 @@ func TestParseMapStruct(t *testing.T) {
 	expectFailureFor(t, "store_limits: {\nchannels: {\n\"foo\": xxx\n}\n}", mapStructErr)
 	expectFailureFor(t, "tls: xxx", mapStructErr)
 	expectFailureFor(t, "file: xxx", mapStructErr)
+	expectFailureFor(t, "permissions: [xxx]", mapStructErr)
+	expectFailureFor(t, "client_limits: xxx", mapStructErr)
+	expectFailureFor(t, "client_limits: {\nclients: xxx\n}", mapStructErr)
+	expectFailureFor(t, "client_limits: {\nclients: {\n\"me\": xxx\n}\n}", mapStructErr)
 }
 
 func TestParseWrongTypes(t *testing.T) {
@@ -293,12 +332,17 @@ func TestParseWrongTypes(t *testing.T) {
 	expectFailureFor(t, "hb_fail_count: false", wrongTypeErr)
 	expectFailureFor(t, "ack_subs_pool_size: false", wrongTypeErr)
 	expectFailureFor(t, "ft_group: 123", wrongTypeErr)
+	expectFailureFor(t, "credentials_file: 123", wrongTypeErr)
+	expectFailureFor(t, "audit_log: 123", wrongTypeErr)
+	expectFailureFor(t, "audit_log_max_size: false", wrongTypeErr)
 	expectFailureFor(t, "partitioning: 123", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{max_channels:false}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{max_msgs:false}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{max_bytes:false}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{max_age:false}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{max_age:\"foo\"}", wrongTimeErr)
+	expectFailureFor(t, "store_limits:{max_durable_inactivity:false}", wrongTypeErr)
+	expectFailureFor(t, "store_limits:{max_durable_inactivity:\"foo\"}", wrongTimeErr)
 	expectFailureFor(t, "store_limits:{max_subs:false}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{channels:{\"foo\":{max_msgs:false}}}", wrongTypeErr)
 	expectFailureFor(t, "store_limits:{channels:{\"foo\":{max_bytes:false}}}", wrongTypeErr)
@@ -326,6 +370,16 @@ func TestParseWrongTypes(t *testing.T) {
 	expectFailureFor(t, "file:{slice_archive_script:123}", wrongTypeErr)
 	expectFailureFor(t, "file:{fds_limit:false}", wrongTypeErr)
 	expectFailureFor(t, "file:{parallel_recovery:false}", wrongTypeErr)
+	expectFailureFor(t, "permissions: xxx", "array")
+	expectFailureFor(t, "permissions:[{client_id:123}]", wrongTypeErr)
+	expectFailureFor(t, "permissions:[{client_id:\"me\", publish:123}]", "array of strings")
+	expectFailureFor(t, "permissions:[{client_id:\"me\", subscribe:[123]}]", wrongTypeErr)
+	expectFailureFor(t, "permissions:[{publish:\"foo\"}]", "missing client_id")
+	expectFailureFor(t, "client_limits:{max_clients:false}", wrongTypeErr)
+	expectFailureFor(t, "client_limits:{max_subs:false}", wrongTypeErr)
+	expectFailureFor(t, "client_limits:{max_pub_msgs:false}", wrongTypeErr)
+	expectFailureFor(t, "client_limits:{max_pub_bytes:false}", wrongTypeErr)
+	expectFailureFor(t, "client_limits:{clients:{\"me\":{max_subs:false}}}", wrongTypeErr)
 }
 
 func expectFailureFor(t *testing.T, content, errorMatch string) {
@@ -396,6 +450,9 @@ func TestParseConfigureOptions(t *testing.T) {
 	if !sopts.Debug || !sopts.Trace {
 		t.Fatal("Debug and Trace should have been set to true")
 	}
+	if sopts.ConfigFile != "../test/configs/test_parse.conf" {
+		t.Fatalf("Expected config file to be recorded, got %q", sopts.ConfigFile)
+	}
 	// The config set both debug and trace to true, override with -SDV=false
 	sopts, _ = mustNotFail([]string{"-sc", "../test/configs/test_parse.conf", "-SDV=false"})
 	if sopts.Debug || sopts.Trace {
@@ -413,6 +470,14 @@ func TestParseConfigureOptions(t *testing.T) {
 	if sopts.FileStoreOpts.BufferSize != 300*1024 {
 		t.Fatalf("Expected file_buffer_size to be 300KB, got %v", sopts.FileStoreOpts.BufferSize)
 	}
+	sopts, _ = mustNotFail([]string{"-max_clients", "10", "-max_client_subs", "20"})
+	if sopts.MaxClients != 10 || sopts.ClientLimits.MaxSubscriptions != 20 {
+		t.Fatalf("Unexpected client limits: %v %+v", sopts.MaxClients, sopts.ClientLimits)
+	}
+	sopts, _ = mustNotFail([]string{"-audit_log", "audit.log", "-audit_log_max_size", "1MB"})
+	if sopts.AuditLog != "audit.log" || sopts.AuditLogMaxSize != 1024*1024 {
+		t.Fatalf("Unexpected audit log options: %q %v", sopts.AuditLog, sopts.AuditLogMaxSize)
+	}
 
 	// Failures with bytes
 	expectToFail([]string{"-max_bytes", "12abc"}, "error")
@@ -0,0 +1,261 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+// exclusiveDurableKey returns the key of an exclusive durable in the
+// subStore's durables map. Since the instances of an exclusive durable
+// belong to different clients, the key does not include the client ID,
+// but a character that is not valid in a client ID instead.
+func exclusiveDurableKey(subject, durableName string) string {
+	return fmt.Sprintf("*-%s-%s", subject, durableName)
+}
+
+// addStandby adds a standby instance, described by `sr`, to the exclusive
+// durable `dur`. The standby is stored so that it survives a server restart,
+// and takes over when the active instance leaves. If the durable is offline,
+// the first standby is promoted right away.
+func (s *StanServer) addStandby(c *channel, dur *subState, sr *spb.SubscriptionRequest, reply, ackInbox, ackSubject string) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	// The durable may have just been removed due to inactivity.
+	dur.RLock()
+	expired := dur.expired
+	dur.RUnlock()
+	if expired {
+		s.log.Errorf("[Client:%s] Unable to add standby for durable=%s, subject=%s: durable has expired",
+			sr.ClientID, sr.DurableName, sr.Subject)
+		s.sendSubscriptionResponseErr(reply, ErrUnknownDurable)
+		return
+	}
+
+	ss := c.ss
+	sub := &subState{
+		SubState: spb.SubState{
+			ClientID:           sr.ClientID,
+			Inbox:              sr.Inbox,
+			AckInbox:           ackInbox,
+			MaxInFlight:        sr.MaxInFlight,
+			MaxInFlightBytes:   sr.MaxInFlightBytes,
+			AckWaitInSecs:      sr.AckWaitInSecs,
+			DurableName:        sr.DurableName,
+			IsDurable:          true,
+			ReplaySpeedPercent: sr.ReplaySpeedPercent,
+			DeliveryRate:       sr.DeliveryRate,
+			DeliveryBurst:      sr.DeliveryBurst,
+			Exclusive:          true,
+			Standby:            true,
+		},
+		subject: sr.Subject,
+		ackWait: computeAckWait(sr.AckWaitInSecs),
+		store:   c.store.Subs,
+		chStats: &c.stats,
+	}
+	if !s.clients.addSub(sr.ClientID, sub) {
+		s.log.Errorf("[Client:%s] Unable to add standby for durable=%s, subject=%s: unknown client",
+			sr.ClientID, sr.DurableName, sr.Subject)
+		s.sendSubscriptionResponseErr(reply, ErrUnknownClient)
+		return
+	}
+	if err := sub.store.CreateSub(&sub.SubState); err != nil {
+		s.clients.removeSub(sr.ClientID, sub)
+		s.log.Errorf("Unable to store standby [%v:%v] on [%s]: %v", sr.ClientID, sr.Inbox, sr.Subject, err)
+		s.sendSubscriptionResponseErr(reply, err)
+		return
+	}
+	key := sub.durableKey()
+	ss.Lock()
+	ss.standbys[key] = append(ss.standbys[key], sub)
+	ss.Unlock()
+
+	s.monMu.Lock()
+	s.numSubs++
+	s.monMu.Unlock()
+
+	resp := &pb.SubscriptionResponse{AckInbox: ackSubject}
+	b, _ := resp.Marshal()
+	s.ncs.Publish(reply, b)
+
+	if s.isDebug() {
+		s.log.Debugf("[Client:%s] Added standby for exclusive durable=%s, subject=%s, inbox=%s, subid=%d",
+			sr.ClientID, sr.DurableName, sr.Subject, sr.Inbox, sub.ID)
+	}
+
+	dur.RLock()
+	offline := dur.ClientID == ""
+	dur.RUnlock()
+	if offline {
+		s.promoteStandby(c, dur)
+	}
+}
+
+// removeStandby removes `sub` from the standbys of its exclusive durable and
+// from the store. Returns false if `sub` is not a standby.
+func (ss *subStore) removeStandby(sub *subState) bool {
+	sub.Lock()
+	if !sub.Standby {
+		sub.Unlock()
+		return false
+	}
+	sub.removed = true
+	key := sub.durableKey()
+	subid := sub.ID
+	sub.Unlock()
+
+	found := false
+	ss.Lock()
+	// Unlike sub.deleteFromList(), keep the standbys in order.
+	standbys := ss.standbys[key]
+	for i, st := range standbys {
+		if st == sub {
+			standbys = append(standbys[:i], standbys[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if len(standbys) == 0 {
+		delete(ss.standbys, key)
+	} else {
+		ss.standbys[key] = standbys
+	}
+	ss.Unlock()
+	// It may have already been removed when its client went away while
+	// trying to promote it.
+	if found {
+		if err := sub.store.DeleteSub(subid); err != nil {
+			ss.stan.log.Errorf("Error deleting standby subid=%d, subject=%s, err=%v", subid, sub.subject, err)
+		}
+	}
+	return true
+}
+
+// hasStandbys returns true if the exclusive durable with the given key has
+// standby instances.
+func (ss *subStore) hasStandbys(durableKey string) bool {
+	ss.RLock()
+	defer ss.RUnlock()
+	return len(ss.standbys[durableKey]) > 0
+}
+
+// lookupStandby returns the standby instance whose ack subject, as returned
+// to the client in the subscription response, is `ackSubject`.
+func (ss *subStore) lookupStandby(ackSubject string) *subState {
+	ss.RLock()
+	defer ss.RUnlock()
+	for _, standbys := range ss.standbys {
+		for _, sub := range standbys {
+			// AckInbox is immutable for a standby.
+			if ackSubject == sub.AckInbox || ackSubject == ss.stan.acksSubsPrefix+sub.AckInbox {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// recoverStandby adds a standby recovered from the store to the standbys of
+// its exclusive durable. Returns false if its client is gone, in which case
+// the standby is removed from the store.
+func (s *StanServer) recoverStandby(c *channel, sub *subState) bool {
+	if !s.clients.addSub(sub.ClientID, sub) {
+		if err := sub.store.DeleteSub(sub.ID); err != nil {
+			s.log.Errorf("Error deleting standby subid=%d, subject=%s, err=%v", sub.ID, sub.subject, err)
+		}
+		return false
+	}
+	key := sub.durableKey()
+	standbys := c.ss.standbys[key]
+	// Keep the standbys in the order they were added.
+	i := len(standbys)
+	for i > 0 && standbys[i-1].ID > sub.ID {
+		i--
+	}
+	standbys = append(standbys, nil)
+	copy(standbys[i+1:], standbys[i:])
+	standbys[i] = sub
+	c.ss.standbys[key] = standbys
+	return true
+}
+
+// promoteStandby resumes the offline exclusive durable `dur` with the first
+// of its standbys whose client is still registered. The messages pending
+// acknowledgment are redelivered to this new active instance.
+// s.closeMu held on entry.
+func (s *StanServer) promoteStandby(c *channel, dur *subState) {
+	ss := c.ss
+	dur.RLock()
+	key := dur.durableKey()
+	dur.RUnlock()
+	for {
+		ss.Lock()
+		standbys := ss.standbys[key]
+		if len(standbys) == 0 {
+			ss.Unlock()
+			return
+		}
+		standby := standbys[0]
+		if len(standbys) == 1 {
+			delete(ss.standbys, key)
+		} else {
+			ss.standbys[key] = standbys[1:]
+		}
+		ss.Unlock()
+
+		standby.Lock()
+		standby.removed = true
+		st := standby.SubState
+		standby.Unlock()
+		if err := standby.store.DeleteSub(st.ID); err != nil {
+			s.log.Errorf("Error deleting standby subid=%d, subject=%s, err=%v", st.ID, c.name, err)
+		}
+		// The durable replaces the standby in its client.
+		if !s.clients.removeSub(st.ClientID, standby) {
+			continue
+		}
+		sr := &spb.SubscriptionRequest{
+			ClientID:           st.ClientID,
+			Subject:            c.name,
+			Inbox:              st.Inbox,
+			MaxInFlight:        st.MaxInFlight,
+			MaxInFlightBytes:   st.MaxInFlightBytes,
+			AckWaitInSecs:      st.AckWaitInSecs,
+			ReplaySpeedPercent: st.ReplaySpeedPercent,
+			DeliveryRate:       st.DeliveryRate,
+			DeliveryBurst:      st.DeliveryBurst,
+		}
+		s.resumeDurable(dur, sr, st.AckInbox)
+		if err := s.updateDurable(ss, dur); err != nil {
+			s.log.Errorf("[Client:%s] Unable to promote standby for durable=%s, subject=%s: %v",
+				st.ClientID, st.DurableName, c.name, err)
+			// This suspends the durable again and promotes the next standby.
+			ss.Remove(c, dur, false)
+			return
+		}
+		dur.Lock()
+		if s.acksSubsPoolSize == 0 {
+			ackSub, err := s.nc.Subscribe(st.AckInbox, s.processAckMsg)
+			if err != nil {
+				dur.Unlock()
+				panic(fmt.Sprintf("Could not subscribe to ack subject, %v\n", err))
+			}
+			ackSub.SetPendingLimits(-1, -1)
+			dur.ackSub = ackSub
+			s.nc.Flush()
+		}
+		dur.initialized = true
+		subid := dur.ID
+		dur.Unlock()
+
+		s.log.Noticef("[Client:%s] Promoted standby of exclusive durable=%s, subject=%s, subid=%d",
+			st.ClientID, st.DurableName, c.name, subid)
+		s.subStartCh <- &subStartInfo{c: c, sub: dur, isDurable: true}
+		return
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/nats-io/go-nats"
+	"github.com/nats-io/nats-streaming-server/audit"
 	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 	"github.com/nats-io/nats-streaming-server/util"
@@ -80,6 +81,12 @@ func (s *StanServer) ftStart() (retErr error) {
 	// possible store corruption...
 	activationTime := time.Now()
 	s.log.Noticef("Server is active")
+	if s.auditLog != nil {
+		if err := s.auditLog.Open(); err != nil {
+			return fmt.Errorf("unable to open audit log: %v", err)
+		}
+	}
+	s.auditf(audit.EventFTActive, "", "ft_group=%s", s.opts.FTGroupName)
 	s.startGoRoutine(func() {
 		s.ftSendHBLoop(activationTime)
 	})
@@ -206,7 +213,7 @@ func (s *StanServer) ftSetup() error {
 	// Create channel to notify FT go routine to quit.
 	s.ftQuit = make(chan struct{}, 1)
 	// Set the state as standby initially
-	s.state = FTStandby
+	s.setState(FTStandby)
 	return nil
 }
 
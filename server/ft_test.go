@@ -5,8 +5,10 @@ package server
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,6 +18,7 @@ import (
 	natsdTest "github.com/nats-io/gnatsd/test"
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/audit"
 	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 )
@@ -776,3 +779,45 @@ func TestFTActiveReceivesInvalidHBMessages(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 	checkState(t, s, FTActive)
 }
+
+func TestFTAuditLogOpenedWhenActive(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unable to create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	auditLog := filepath.Join(dir, "audit.log")
+
+	delayFirstLockAttempt()
+	defer cancelFirstLockAttemptDelay()
+
+	opts := getTestFTDefaultOptions()
+	opts.AuditLog = auditLog
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+	replaceWithMockedStore(s, false, nil)
+	ftReleasePause()
+
+	checkState(t, s, FTStandby)
+	waitForGetLockAttempt()
+	// The standby must not touch the audit log of the active server.
+	if _, err := os.Stat(auditLog); !os.IsNotExist(err) {
+		t.Fatalf("Expected audit log to not exist, got %v", err)
+	}
+
+	setMockedStoreVals(s, true, nil)
+	checkState(t, s, FTActive)
+	var events []string
+	if _, err := audit.ReadFile(auditLog, nil, func(rec *spb.AuditRecord) error {
+		events = append(events, rec.Event)
+		return nil
+	}); err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	if len(events) != 1 || events[0] != audit.EventFTActive {
+		t.Fatalf("Expected a single %q event, got %v", audit.EventFTActive, events)
+	}
+}
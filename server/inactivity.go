@@ -0,0 +1,174 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-streaming-server/audit"
+)
+
+// durableInactivity returns how long the durables of this channel can stay
+// offline before being removed. 0 means that they are never removed.
+func (c *channel) durableInactivity() time.Duration {
+	return c.store.Subs.GetLimits().MaxDurableInactivity
+}
+
+// Clear the idleTimer.
+// sub Lock held in entry.
+func (sub *subState) clearIdleTimer() {
+	if sub.idleTimer != nil {
+		sub.idleTimer.Stop()
+		sub.idleTimer = nil
+	}
+}
+
+// setupIdleTimer sets a timer that removes the offline durable `sub`,
+// or the durable queue group of which `sub` is the shadow, once it has been
+// offline, since sub.OfflineTime, for longer than the channel's limit.
+// sub's lock held on entry.
+func (s *StanServer) setupIdleTimer(c *channel, sub *subState) {
+	sub.clearIdleTimer()
+	limit := c.durableInactivity()
+	if limit <= 0 {
+		return
+	}
+	expire := time.Duration(sub.OfflineTime + int64(limit) - time.Now().UnixNano())
+	sub.idleTimer = time.AfterFunc(expire, func() {
+		s.expireDurable(c, sub)
+	})
+}
+
+// setupRecoveredIdleTimers sets the inactivity timer of the offline
+// durables and shadow durable queue subscribers recovered from the store.
+// Those that went offline before their offline time was recorded are
+// considered offline since now.
+func (s *StanServer) setupRecoveredIdleTimers() {
+	now := time.Now().UnixNano()
+	setup := func(c *channel, sub *subState) {
+		sub.Lock()
+		if sub.OfflineTime == 0 {
+			sub.OfflineTime = now
+		}
+		s.setupIdleTimer(c, sub)
+		sub.Unlock()
+	}
+	for _, c := range s.channels.getAll() {
+		if c.durableInactivity() <= 0 {
+			continue
+		}
+		ss := c.ss
+		ss.RLock()
+		for _, sub := range ss.durables {
+			sub.RLock()
+			offline := sub.isOfflineDurableSubscriber()
+			sub.RUnlock()
+			if offline {
+				setup(c, sub)
+			}
+		}
+		for _, qs := range ss.qsubs {
+			qs.RLock()
+			if shadow := qs.shadow; shadow != nil && len(qs.subs) == 0 {
+				setup(c, shadow)
+			}
+			qs.RUnlock()
+		}
+		ss.RUnlock()
+	}
+}
+
+// expireDurable removes, from the server and the store, the offline durable
+// `sub`, or the durable queue group of which `sub` is the shadow, unless it
+// has been resumed in the meantime.
+func (s *StanServer) expireDurable(c *channel, sub *subState) {
+	s.mu.RLock()
+	shutdown := s.shutdown
+	s.mu.RUnlock()
+	if shutdown {
+		return
+	}
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	limit := c.durableInactivity()
+	ss := c.ss
+	ss.Lock()
+	expired := ss.removeIdleDurable(sub, limit)
+	ss.Unlock()
+	if !expired {
+		return
+	}
+	sub.RLock()
+	subid := sub.ID
+	durName, qgroup := sub.DurableName, sub.QGroup
+	// The queue group of a durable queue subscription is the compound
+	// "<durable name>:<queue name>", report both names separately.
+	if i := strings.Index(qgroup, ":"); i >= 0 {
+		durName, qgroup = qgroup[:i], qgroup[i+1:]
+	}
+	details := durableDetails(c.name, durName, qgroup)
+	since := time.Unix(0, sub.OfflineTime).UTC().Format(time.RFC3339)
+	sub.RUnlock()
+	if err := sub.store.DeleteSub(subid); err != nil {
+		s.log.Errorf("Error deleting expired durable subid=%d, subject=%s, err=%v", subid, c.name, err)
+	}
+	s.log.Noticef("Removed durable subscription %s, offline since %s", details, since)
+	s.auditf(audit.EventDurableExpire, "", "%s offline_since=%s", details, since)
+}
+
+// removeIdleDurable removes `sub` from the subStore if it is an offline
+// durable, or the shadow of a durable queue group, in which case the group
+// is removed, that has been offline for at least `limit`. Returns true if
+// it was removed.
+// subStore's lock held on entry.
+func (ss *subStore) removeIdleDurable(sub *subState, limit time.Duration) bool {
+	var qs *queueState
+	sub.RLock()
+	qgroup := sub.QGroup
+	sub.RUnlock()
+	if qgroup != "" {
+		if qs = ss.qsubs[qgroup]; qs == nil {
+			return false
+		}
+		qs.Lock()
+		defer qs.Unlock()
+	}
+	sub.Lock()
+	defer sub.Unlock()
+	// If the durable was resumed, and went offline again, before the locks
+	// could be grabbed, then the new timer is in charge.
+	if time.Now().UnixNano() < sub.OfflineTime+int64(limit) {
+		return false
+	}
+	sub.idleTimer = nil
+	if qs != nil {
+		if qs.shadow != sub || len(qs.subs) > 0 {
+			return false
+		}
+		qs.shadow = nil
+		delete(ss.qsubs, qgroup)
+	} else {
+		if !sub.isOfflineDurableSubscriber() || sub.expired {
+			return false
+		}
+		// The ClientID, part of the durable key, is cleared when the
+		// durable goes offline, so look the durable up by value.
+		key := ""
+		for k, dur := range ss.durables {
+			if dur == sub {
+				key = k
+				break
+			}
+		}
+		// An offline exclusive durable that has just been joined by a
+		// standby is about to be resumed by this standby.
+		if key == "" || len(ss.standbys[key]) > 0 {
+			return false
+		}
+		delete(ss.durables, key)
+	}
+	sub.expired = true
+	return true
+}
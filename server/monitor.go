@@ -10,10 +10,14 @@ import (
 	"runtime"
 	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	gnatsd "github.com/nats-io/gnatsd/server"
+	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
+	"github.com/nats-io/nats-streaming-server/util"
 )
 
 // Routes for the monitoring pages
@@ -23,8 +27,21 @@ const (
 	StorePath    = RootPath + "/storez"
 	ClientsPath  = RootPath + "/clientsz"
 	ChannelsPath = RootPath + "/channelsz"
+	HealthzPath  = RootPath + "/healthz"
+	MsgTracePath = RootPath + "/msgtracez"
+	ReloadPath   = RootPath + "/reloadz"
+	SeekPath     = RootPath + "/seekz"
+	PausePath    = RootPath + "/pausez"
 
 	defaultMonitorListLimit = 1024
+
+	// Value of the `sort` URL argument to sort subscriptions (and the
+	// channels or clients owning them) by decreasing lag.
+	sortByLag = "lag"
+
+	// FT roles reported by the health endpoint.
+	ftRoleActive  = "active"
+	ftRoleStandby = "standby"
 )
 
 // Serverz describes the NATS Streaming Server
@@ -42,6 +59,42 @@ type Serverz struct {
 	Channels      int       `json:"channels"`
 	TotalMsgs     int       `json:"total_msgs"`
 	TotalBytes    uint64    `json:"total_bytes"`
+	Rejected      *Quotaz   `json:"rejected,omitempty"`
+}
+
+// Quotaz reports the number of requests rejected because of the clients limits
+type Quotaz struct {
+	Clients       uint64 `json:"clients"`
+	Subscriptions uint64 `json:"subscriptions"`
+	Publishes     uint64 `json:"publishes"`
+}
+
+// Healthz describes the health of the NATS Streaming Server
+type Healthz struct {
+	ClusterID  string    `json:"cluster_id"`
+	ServerID   string    `json:"server_id"`
+	Now        time.Time `json:"now"`
+	State      string    `json:"state"`
+	FTRole     string    `json:"ft_role,omitempty"`
+	Ready      bool      `json:"ready"`
+	LastError  string    `json:"last_error,omitempty"`
+	StoreError string    `json:"store_error,omitempty"`
+}
+
+// MsgTracesz lists the timelines of the traced messages
+type MsgTracesz struct {
+	ClusterID string       `json:"cluster_id"`
+	ServerID  string       `json:"server_id"`
+	Now       time.Time    `json:"now"`
+	Traces    []*MsgTracez `json:"traces"`
+}
+
+// Reloadz describes the changes applied by a configuration reload
+type Reloadz struct {
+	ClusterID string    `json:"cluster_id"`
+	ServerID  string    `json:"server_id"`
+	Now       time.Time `json:"now"`
+	Changes   []string  `json:"changes"`
 }
 
 // Storez describes the NATS Streaming Store
@@ -71,6 +124,17 @@ type Clientsz struct {
 type Clientz struct {
 	ID            string                      `json:"id"`
 	HBInbox       string                      `json:"hb_inbox"`
+	Name          string                      `json:"name,omitempty"`
+	Version       string                      `json:"version,omitempty"`
+	Hostname      string                      `json:"hostname,omitempty"`
+	Language      string                      `json:"language,omitempty"`
+	Labels        map[string]string           `json:"labels,omitempty"`
+	ConnectTime   *time.Time                  `json:"connect_time,omitempty"`
+	LastHB        *time.Time                  `json:"last_hb,omitempty"`
+	Protocol      int32                       `json:"protocol"`
+	Capabilities  []string                    `json:"capabilities,omitempty"`
+	RejectedSubs  uint64                      `json:"rejected_subscriptions,omitempty"`
+	RejectedPubs  uint64                      `json:"rejected_publishes,omitempty"`
 	Subscriptions map[string][]*Subscriptionz `json:"subscriptions,omitempty"`
 }
 
@@ -94,22 +158,38 @@ type Channelz struct {
 	Bytes         uint64           `json:"bytes"`
 	FirstSeq      uint64           `json:"first_seq"`
 	LastSeq       uint64           `json:"last_seq"`
+	InMsgsRate    float64          `json:"in_msgs_rate"`
+	InBytesRate   float64          `json:"in_bytes_rate"`
+	OutMsgsRate   float64          `json:"out_msgs_rate"`
+	OutBytesRate  float64          `json:"out_bytes_rate"`
 	Subscriptions []*Subscriptionz `json:"subscriptions,omitempty"`
 }
 
 // Subscriptionz describes a NATS Streaming Subscription
 type Subscriptionz struct {
-	Inbox        string `json:"inbox"`
-	AckInbox     string `json:"ack_inbox"`
-	DurableName  string `json:"durable_name,omitempty"`
-	QueueName    string `json:"queue_name,omitempty"`
-	IsDurable    bool   `json:"is_durable"`
-	IsOffline    bool   `json:"is_offline"`
-	MaxInflight  int    `json:"max_inflight"`
-	AckWait      int    `json:"ack_wait"`
-	LastSent     uint64 `json:"last_sent"`
-	PendingCount int    `json:"pending_count"`
-	IsStalled    bool   `json:"is_stalled"`
+	Inbox            string `json:"inbox"`
+	AckInbox         string `json:"ack_inbox"`
+	DurableName      string `json:"durable_name,omitempty"`
+	QueueName        string `json:"queue_name,omitempty"`
+	IsDurable        bool   `json:"is_durable"`
+	IsOffline        bool   `json:"is_offline"`
+	IsExclusive      bool   `json:"is_exclusive,omitempty"`
+	IsStandby        bool   `json:"is_standby,omitempty"`
+	MaxInflight      int    `json:"max_inflight"`
+	MaxInflightBytes int64  `json:"max_inflight_bytes,omitempty"`
+	DeliveryRate     uint32 `json:"delivery_rate,omitempty"`
+	DeliveryBurst    uint32 `json:"delivery_burst,omitempty"`
+	IsPaused         bool   `json:"is_paused,omitempty"`
+	RedeliveryPaused bool   `json:"redelivery_paused,omitempty"`
+	AckWait          int    `json:"ack_wait"`
+	LastSent         uint64 `json:"last_sent"`
+	PendingCount     int    `json:"pending_count"`
+	PendingBytes     int64  `json:"pending_bytes"`
+	IsStalled        bool   `json:"is_stalled"`
+	Lag              uint64 `json:"lag"`
+	OldestUnackedAge int64  `json:"oldest_unacked_age_ms"`
+	RedeliveryCount  uint64 `json:"redelivery_count"`
+	AvgAckLatency    int64  `json:"avg_ack_latency_ms"`
 }
 
 func (s *StanServer) startMonitoring(nOpts *gnatsd.Options) error {
@@ -131,10 +211,15 @@ func (s *StanServer) startMonitoring(nOpts *gnatsd.Options) error {
 
 	mux := hh.(*http.ServeMux)
 	mux.HandleFunc(RootPath, s.handleRootz)
-	mux.HandleFunc(ServerPath, s.handleServerz)
-	mux.HandleFunc(StorePath, s.handleStorez)
-	mux.HandleFunc(ClientsPath, s.handleClientsz)
-	mux.HandleFunc(ChannelsPath, s.handleChannelsz)
+	mux.HandleFunc(ServerPath, s.tenantHandler((*StanServer).handleServerz))
+	mux.HandleFunc(StorePath, s.tenantHandler((*StanServer).handleStorez))
+	mux.HandleFunc(ClientsPath, s.tenantHandler((*StanServer).handleClientsz))
+	mux.HandleFunc(ChannelsPath, s.tenantHandler((*StanServer).handleChannelsz))
+	mux.HandleFunc(HealthzPath, s.tenantHandler((*StanServer).handleHealthz))
+	mux.HandleFunc(MsgTracePath, s.tenantHandler((*StanServer).handleMsgTracez))
+	mux.HandleFunc(ReloadPath, s.handleReloadz)
+	mux.HandleFunc(SeekPath, s.tenantHandler((*StanServer).handleSeekz))
+	mux.HandleFunc(PausePath, s.tenantHandler((*StanServer).handlePausez))
 
 	return nil
 }
@@ -155,10 +240,66 @@ func (s *StanServer) handleRootz(w http.ResponseWriter, r *http.Request) {
 	<a href=%s>store</a><br/>
 	<a href=%s>clients</a><br/>
 	<a href=%s>channels</a><br/>
+	<a href=%s>health</a><br/>
+	<a href=%s>message traces</a><br/>
     <br/>
     <a href=http://nats.io/documentation/server/gnatsd-monitoring/>help</a>
   </body>
-</html>`, ServerPath, StorePath, ClientsPath, ChannelsPath)
+</html>`, ServerPath, StorePath, ClientsPath, ChannelsPath, HealthzPath, MsgTracePath)
+}
+
+// handleHealthz reports the health of the server. The status code is:
+// - 200 when the server is active (standalone or FT active, the FT role
+// is reported in the body),
+// - 202 when the server is a FT standby,
+// - 500 when the server has failed (the body contains the last error),
+// - 507 when the store failed to store messages,
+// - 503 when the server is shutting down, or with `ready=1`, when the server
+// is not yet ready to serve clients (recovery not complete, or FT standby).
+// This handler does not acquire the server lock, which is held during
+// the recovery.
+func (s *StanServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	readyOption, _ := strconv.Atoi(r.URL.Query().Get("ready"))
+	state := State(atomic.LoadInt64(&s.healthState))
+	ready := atomic.LoadInt64(&s.ready) == 1
+	healthz := &Healthz{
+		ClusterID: s.opts.ID,
+		ServerID:  s.serverID,
+		Now:       time.Now(),
+		State:     state.String(),
+		Ready:     ready && (state == Standalone || state == FTActive),
+	}
+	status := http.StatusOK
+	switch state {
+	case FTActive:
+		healthz.FTRole = ftRoleActive
+	case Failed:
+		status = http.StatusInternalServerError
+		if err := s.LastError(); err != nil {
+			healthz.LastError = err.Error()
+		}
+	case Shutdown:
+		status = http.StatusServiceUnavailable
+	case FTStandby:
+		status = http.StatusAccepted
+		healthz.FTRole = ftRoleStandby
+	}
+	if status == http.StatusOK {
+		if err := s.getStoreError(); err != nil {
+			status = http.StatusInsufficientStorage
+			healthz.StoreError = err.Error()
+		}
+	}
+	if readyOption == 1 && !healthz.Ready && status < http.StatusInternalServerError {
+		status = http.StatusServiceUnavailable
+	}
+	b, err := json.MarshalIndent(healthz, "", "  ")
+	if err != nil {
+		s.log.Errorf("Error marshaling response to %q request: %v", r.URL, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
 }
 
 func (s *StanServer) handleServerz(w http.ResponseWriter, r *http.Request) {
@@ -190,6 +331,17 @@ func (s *StanServer) handleServerz(w http.ResponseWriter, r *http.Request) {
 		TotalMsgs:     count,
 		TotalBytes:    bytes,
 	}
+	// Report the rejected requests only if clients are limited.
+	s.optsMu.RLock()
+	limited := s.opts.MaxClients > 0 || s.clientLimits != nil
+	s.optsMu.RUnlock()
+	if limited {
+		serverz.Rejected = &Quotaz{
+			Clients:       atomic.LoadUint64(&s.quotas.rejectedClients),
+			Subscriptions: atomic.LoadUint64(&s.quotas.rejectedSubs),
+			Publishes:     atomic.LoadUint64(&s.quotas.rejectedPubs),
+		}
+	}
 	s.sendResponse(w, r, serverz)
 }
 
@@ -222,29 +374,140 @@ func (s *StanServer) handleStorez(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error getting information about channels state: %v", err), http.StatusInternalServerError)
 		return
 	}
+	s.optsMu.RLock()
+	limits := s.opts.StoreLimits
+	s.optsMu.RUnlock()
 	storez := &Storez{
 		ClusterID:  s.info.ClusterID,
 		ServerID:   s.serverID,
 		Now:        time.Now(),
 		Type:       s.store.Name(),
-		Limits:     s.opts.StoreLimits,
+		Limits:     limits,
 		TotalMsgs:  count,
 		TotalBytes: bytes,
 	}
 	s.sendResponse(w, r, storez)
 }
 
+// handleReloadz reloads the configuration file on a POST request.
+func (s *StanServer) handleReloadz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "configuration reload requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	changes, err := s.reload()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reloading configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+	if changes == nil {
+		changes = []string{}
+	}
+	reloadz := &Reloadz{
+		ClusterID: s.info.ClusterID,
+		ServerID:  s.serverID,
+		Now:       time.Now(),
+		Changes:   changes,
+	}
+	s.sendResponse(w, r, reloadz)
+}
+
+type byMsgTrace []*MsgTracez
+
+func (a byMsgTrace) Len() int      { return (len(a)) }
+func (a byMsgTrace) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byMsgTrace) Less(i, j int) bool {
+	if a[i].Channel != a[j].Channel {
+		return a[i].Channel < a[j].Channel
+	}
+	if a[i].Sequence != a[j].Sequence {
+		return a[i].Sequence < a[j].Sequence
+	}
+	return a[i].GUID < a[j].GUID
+}
+
+// handleMsgTracez manages message traces. A message is identified with
+// `guid=<publisher guid>` or `channel=<name>&seq=<sequence>`.
+// POST starts tracing the message, DELETE stops tracing it, and GET returns
+// its timeline, or the timelines of all traced messages if no message is
+// specified.
+func (s *StanServer) handleMsgTracez(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	guid := query.Get("guid")
+	channel := query.Get("channel")
+	seq, _ := strconv.ParseUint(query.Get("seq"), 10, 64)
+	var (
+		mt  *MsgTracez
+		err error
+	)
+	switch r.Method {
+	case "POST":
+		mt, err = s.msgTracer.mark(guid, channel, seq)
+	case "DELETE":
+		err = s.msgTracer.unmark(guid, channel, seq)
+	default:
+		if guid == "" && channel == "" {
+			traces := s.msgTracer.getAll()
+			sort.Sort(byMsgTrace(traces))
+			msgTracesz := &MsgTracesz{
+				ClusterID: s.info.ClusterID,
+				ServerID:  s.serverID,
+				Now:       time.Now(),
+				Traces:    traces,
+			}
+			s.sendResponse(w, r, msgTracesz)
+			return
+		}
+		mt, err = s.msgTracer.get(guid, channel, seq)
+	}
+	switch err {
+	case nil:
+	case errMsgTraceNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mt == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.sendResponse(w, r, mt)
+}
+
 type byClientID []*Clientz
 
 func (c byClientID) Len() int           { return len(c) }
 func (c byClientID) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
 func (c byClientID) Less(i, j int) bool { return c[i].ID < c[j].ID }
 
+type byClientLag []*Clientz
+
+func (c byClientLag) Len() int           { return len(c) }
+func (c byClientLag) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byClientLag) Less(i, j int) bool { return clientMaxLag(c[i]) > clientMaxLag(c[j]) }
+
+func clientMaxLag(c *Clientz) uint64 {
+	max := uint64(0)
+	for _, subs := range c.Subscriptions {
+		if lag := subsMaxLag(subs); lag > max {
+			max = lag
+		}
+	}
+	return max
+}
+
 func (s *StanServer) handleClientsz(w http.ResponseWriter, r *http.Request) {
 	singleClient := r.URL.Query().Get("client")
 	subsOption, _ := strconv.Atoi(r.URL.Query().Get("subs"))
+	sortOption := r.URL.Query().Get("sort")
 	if singleClient != "" {
-		clientz := getMonitorClient(s, singleClient, subsOption)
+		clientz, err := getMonitorClient(s, singleClient, subsOption, sortOption)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error getting information about client %q: %v", singleClient, err), http.StatusInternalServerError)
+			return
+		}
 		if clientz == nil {
 			http.Error(w, fmt.Sprintf("Client %s not found", singleClient), http.StatusNotFound)
 			return
@@ -252,35 +515,99 @@ func (s *StanServer) handleClientsz(w http.ResponseWriter, r *http.Request) {
 		s.sendResponse(w, r, clientz)
 	} else {
 		offset, limit := getOffsetAndLimit(r)
+		filter, err := newMonitorFilter(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid filter: %v", err), http.StatusBadRequest)
+			return
+		}
 		clients := s.clients.getClients()
-		totalClients := len(clients)
+		// Offline durables of clients that are no longer registered,
+		// keyed by the ID of these clients.
+		var goneClients map[string][]*subState
+		if filter != nil {
+			if filter.offlineDurables {
+				filter.offlineCIDs = s.getClientsWithOfflineDurables()
+				for cID, subs := range filter.offlineCIDs {
+					if _, registered := clients[cID]; registered || !filter.matchGoneClient(cID, subs) {
+						continue
+					}
+					if goneClients == nil {
+						goneClients = make(map[string][]*subState)
+					}
+					goneClients[cID] = subs
+				}
+			}
+			for cID, c := range clients {
+				if !filter.matchClient(c) {
+					delete(clients, cID)
+				}
+			}
+		}
+		totalClients := len(clients) + len(goneClients)
 		carr := make([]*Clientz, 0, totalClients)
 		for cID := range clients {
 			cz := &Clientz{ID: cID}
 			carr = append(carr, cz)
 		}
+		for cID := range goneClients {
+			carr = append(carr, &Clientz{ID: cID})
+		}
 		sort.Sort(byClientID(carr))
 
-		minoff, maxoff := getMinMaxOffset(offset, limit, totalClients)
-		carr = carr[minoff:maxoff]
+		// When sorting by lag, we need the subscriptions of all clients
+		// before applying offset and limit.
+		sortLag := subsOption == 1 && sortOption == sortByLag
+		if !sortLag {
+			minoff, maxoff := getMinMaxOffset(offset, limit, totalClients)
+			carr = carr[minoff:maxoff]
+		}
 
 		// Since clients may be unregistered between the time we get the client IDs
 		// and the time we build carr array, lets count the number of elements
 		// actually intserted.
 		carrSize := 0
 		for _, c := range carr {
+			if subs, gone := goneClients[c.ID]; gone {
+				// Only the offline durables of such a client are known.
+				if subsOption == 1 {
+					subsz, err := getMonitorClientSubs(s, subs, sortOption)
+					if err != nil {
+						http.Error(w, fmt.Sprintf("Error getting information about client %q: %v", c.ID, err), http.StatusInternalServerError)
+						return
+					}
+					c.Subscriptions = subsz
+				}
+				carr[carrSize] = c
+				carrSize++
+				continue
+			}
 			client := s.clients.lookup(c.ID)
 			if client != nil {
+				var subs []*subState
 				client.RLock()
-				c.HBInbox = client.info.HbInbox
+				c.fillInfo(client)
 				if subsOption == 1 {
-					c.Subscriptions = getMonitorClientSubs(client)
+					subs = client.getSubsCopy()
 				}
 				client.RUnlock()
+				if subsOption == 1 {
+					subsz, err := getMonitorClientSubs(s, subs, sortOption)
+					if err != nil {
+						http.Error(w, fmt.Sprintf("Error getting information about client %q: %v", c.ID, err), http.StatusInternalServerError)
+						return
+					}
+					c.Subscriptions = subsz
+				}
+				carr[carrSize] = c
 				carrSize++
 			}
 		}
 		carr = carr[0:carrSize]
+		if sortLag {
+			sort.Stable(byClientLag(carr))
+			minoff, maxoff := getMinMaxOffset(offset, limit, len(carr))
+			carr = carr[minoff:maxoff]
+		}
 		clientsz := &Clientsz{
 			ClusterID: s.info.ClusterID,
 			ServerID:  s.serverID,
@@ -295,86 +622,232 @@ func (s *StanServer) handleClientsz(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getMonitorClient(s *StanServer, clientID string, subsOption int) *Clientz {
+func getMonitorClient(s *StanServer, clientID string, subsOption int, sortOption string) (*Clientz, error) {
 	cli := s.clients.lookup(clientID)
 	if cli == nil {
-		return nil
+		return nil, nil
 	}
+	var subs []*subState
 	cli.RLock()
-	defer cli.RUnlock()
-	cz := &Clientz{
-		HBInbox: cli.info.HbInbox,
-		ID:      cli.info.ID,
+	cz := &Clientz{ID: cli.info.ID}
+	cz.fillInfo(cli)
+	if subsOption == 1 {
+		subs = cli.getSubsCopy()
 	}
+	cli.RUnlock()
 	if subsOption == 1 {
-		cz.Subscriptions = getMonitorClientSubs(cli)
+		subsz, err := getMonitorClientSubs(s, subs, sortOption)
+		if err != nil {
+			return nil, err
+		}
+		cz.Subscriptions = subsz
+	}
+	return cz, nil
+}
+
+// fillInfo sets the information of the client `c`, other than its ID and
+// subscriptions. The client's Read-lock must be held by the caller.
+func (cz *Clientz) fillInfo(c *client) {
+	cz.HBInbox = c.info.HbInbox
+	cz.Name = c.info.Name
+	cz.Version = c.info.Version
+	cz.Hostname = c.info.Hostname
+	cz.Language = c.info.Language
+	if len(c.info.Labels) > 0 {
+		cz.Labels = make(map[string]string, len(c.info.Labels))
+		for _, label := range c.info.Labels {
+			kv := strings.SplitN(label, "=", 2)
+			cz.Labels[kv[0]] = kv[1]
+		}
+	}
+	// Clients recovered from a store created by an older version
+	// of the server do not have a connect time.
+	if c.info.ConnectTime != 0 {
+		ct := time.Unix(0, c.info.ConnectTime)
+		cz.ConnectTime = &ct
+	}
+	if !c.lastHB.IsZero() {
+		lastHB := c.lastHB
+		cz.LastHB = &lastHB
 	}
-	return cz
+	cz.Protocol = c.info.Protocol
+	for _, cn := range capabilityNames {
+		if c.info.Capabilities&uint32(cn.capability) != 0 {
+			cz.Capabilities = append(cz.Capabilities, cn.name)
+		}
+	}
+	cz.RejectedSubs = c.rejectedSubs
+	cz.RejectedPubs = c.rejectedPubs
+}
+
+// Names of the client capabilities reported in Clientz.
+var capabilityNames = []struct {
+	capability spb.Capability
+	name       string
+}{
+	{spb.Capability_CapPubAckSequence, "pub_ack_sequence"},
+	{spb.Capability_CapPubBatch, "pub_batch"},
+	{spb.Capability_CapPing, "ping"},
+	{spb.Capability_CapReplayEnd, "replay_end"},
+	{spb.Capability_CapSubPause, "sub_pause"},
 }
 
-func getMonitorClientSubs(client *client) map[string][]*Subscriptionz {
-	subs := client.subs
+// getMonitorClientSubs returns the given subscriptions, grouped by channel.
+// This is invoked without the client lock held since the lag computation
+// needs to acquire the channels and queue groups locks.
+func getMonitorClientSubs(s *StanServer, subs []*subState, sortOption string) (map[string][]*Subscriptionz, error) {
 	var subsz map[string][]*Subscriptionz
+	lastSeqs := make(map[string]uint64)
 	for _, sub := range subs {
 		if subsz == nil {
 			subsz = make(map[string][]*Subscriptionz)
 		}
+		var msgs stores.MsgStore
+		c := s.channels.get(sub.subject)
+		if c != nil {
+			msgs = c.store.Msgs
+		}
+		lastSeq, ok := lastSeqs[sub.subject]
+		if !ok {
+			if c != nil {
+				var err error
+				_, lastSeq, err = c.store.Msgs.FirstAndLastSequence()
+				if err != nil {
+					return nil, fmt.Errorf("unable to get first and last sequence: %v", err)
+				}
+			}
+			lastSeqs[sub.subject] = lastSeq
+		}
+		// qstate is immutable, so can be accessed without the sub's lock.
+		qLastSent := uint64(0)
+		if qs := sub.qstate; qs != nil {
+			qs.RLock()
+			qLastSent = qs.lastSent
+			qs.RUnlock()
+		}
 		array := subsz[sub.subject]
-		newArray := append(array, createSubscriptionz(sub))
+		newArray := append(array, createSubscriptionz(sub, msgs, lastSeq, qLastSent))
 		if &newArray != &array {
 			subsz[sub.subject] = newArray
 		}
 	}
-	return subsz
+	if sortOption == sortByLag {
+		for _, array := range subsz {
+			sort.Stable(bySubLag(array))
+		}
+	}
+	return subsz, nil
 }
 
-func getMonitorChannelSubs(ss *subStore) []*Subscriptionz {
+func getMonitorChannelSubs(ss *subStore, msgs stores.MsgStore, lastSeq uint64) []*Subscriptionz {
 	ss.RLock()
 	defer ss.RUnlock()
 	subsz := make([]*Subscriptionz, 0)
 	for _, sub := range ss.psubs {
-		subsz = append(subsz, createSubscriptionz(sub))
+		subsz = append(subsz, createSubscriptionz(sub, msgs, lastSeq, 0))
 	}
 	// Get only offline durables (the online also appear in ss.psubs)
 	for _, sub := range ss.durables {
 		if sub.ClientID == "" {
-			subsz = append(subsz, createSubscriptionz(sub))
+			subsz = append(subsz, createSubscriptionz(sub, msgs, lastSeq, 0))
+		}
+	}
+	for _, standbys := range ss.standbys {
+		for _, sub := range standbys {
+			subsz = append(subsz, createSubscriptionz(sub, msgs, lastSeq, 0))
 		}
 	}
 	for _, qsub := range ss.qsubs {
 		qsub.RLock()
 		for _, sub := range qsub.subs {
-			subsz = append(subsz, createSubscriptionz(sub))
+			subsz = append(subsz, createSubscriptionz(sub, msgs, lastSeq, qsub.lastSent))
 		}
 		// If this is a durable queue subscription and all members
 		// are offline, qsub.shadow will be not nil. Report this one.
 		if qsub.shadow != nil {
-			subsz = append(subsz, createSubscriptionz(qsub.shadow))
+			subsz = append(subsz, createSubscriptionz(qsub.shadow, msgs, lastSeq, qsub.lastSent))
 		}
 		qsub.RUnlock()
 	}
 	return subsz
 }
 
-func createSubscriptionz(sub *subState) *Subscriptionz {
+// createSubscriptionz returns the monitoring representation of `sub`.
+// The lag is computed from the channel's `lastSeq`. For queue subscribers,
+// `qLastSent` is the group's last sent sequence, since this is the position
+// shared by all members. The age of the oldest unacknowledged message is
+// computed from its timestamp, looked up in `msgs`, if not nil.
+func createSubscriptionz(sub *subState, msgs stores.MsgStore, lastSeq, qLastSent uint64) *Subscriptionz {
+	now := time.Now().UnixNano()
 	sub.RLock()
 	subz := &Subscriptionz{
-		Inbox:        sub.Inbox,
-		AckInbox:     sub.AckInbox,
-		DurableName:  sub.DurableName,
-		QueueName:    sub.QGroup,
-		IsDurable:    sub.IsDurable,
-		IsOffline:    (sub.ClientID == ""),
-		MaxInflight:  int(sub.MaxInFlight),
-		AckWait:      int(sub.AckWaitInSecs),
-		LastSent:     sub.LastSent,
-		PendingCount: len(sub.acksPending),
-		IsStalled:    sub.stalled,
+		Inbox:            sub.Inbox,
+		AckInbox:         sub.AckInbox,
+		DurableName:      sub.DurableName,
+		QueueName:        sub.QGroup,
+		IsDurable:        sub.IsDurable,
+		IsOffline:        (sub.ClientID == ""),
+		IsExclusive:      sub.Exclusive,
+		IsStandby:        sub.Standby,
+		MaxInflight:      int(sub.MaxInFlight),
+		MaxInflightBytes: sub.MaxInFlightBytes,
+		DeliveryRate:     sub.DeliveryRate,
+		DeliveryBurst:    sub.DeliveryBurst,
+		IsPaused:         sub.Paused,
+		RedeliveryPaused: sub.PauseRedeliveries,
+		AckWait:          int(sub.AckWaitInSecs),
+		LastSent:         sub.LastSent,
+		PendingCount:     len(sub.acksPending),
+		PendingBytes:     sub.pendingBytes,
+		IsStalled:        sub.stalled,
+		RedeliveryCount:  sub.redeliveries,
+	}
+	lastSent := sub.LastSent
+	if qLastSent > lastSent {
+		lastSent = qLastSent
+	}
+	// A standby does not have a position of its own.
+	if lastSeq > lastSent && !sub.Standby {
+		subz.Lag = lastSeq - lastSent
+	}
+	// Sequences are assigned in timestamp order, so the oldest unacknowledged
+	// message is the one with the lowest sequence.
+	oldestSeq := uint64(0)
+	for seq := range sub.acksPending {
+		if oldestSeq == 0 || seq < oldestSeq {
+			oldestSeq = seq
+		}
+	}
+	if sub.acksCount > 0 {
+		subz.AvgAckLatency = int64(time.Duration(sub.ackLatencyTotal/int64(sub.acksCount)) / time.Millisecond)
 	}
 	sub.RUnlock()
+	// Lookup the message outside of the sub's lock since this may
+	// require disk access.
+	if oldestSeq > 0 && msgs != nil {
+		if m, err := msgs.Lookup(oldestSeq); err == nil && m != nil {
+			subz.OldestUnackedAge = int64(time.Duration(now-m.Timestamp) / time.Millisecond)
+		}
+	}
 	return subz
 }
 
+type bySubLag []*Subscriptionz
+
+func (a bySubLag) Len() int           { return (len(a)) }
+func (a bySubLag) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a bySubLag) Less(i, j int) bool { return a[i].Lag > a[j].Lag }
+
+func subsMaxLag(subs []*Subscriptionz) uint64 {
+	max := uint64(0)
+	for _, sub := range subs {
+		if sub.Lag > max {
+			max = sub.Lag
+		}
+	}
+	return max
+}
+
 // When we support only Go 1.8+, replace sort with sort.Slice
 type byName []string
 
@@ -388,14 +861,40 @@ func (a byChannelName) Len() int           { return (len(a)) }
 func (a byChannelName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byChannelName) Less(i, j int) bool { return a[i].Name < a[j].Name }
 
+type byChannelLag []*Channelz
+
+func (a byChannelLag) Len() int      { return (len(a)) }
+func (a byChannelLag) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byChannelLag) Less(i, j int) bool {
+	return subsMaxLag(a[i].Subscriptions) > subsMaxLag(a[j].Subscriptions)
+}
+
 func (s *StanServer) handleChannelsz(w http.ResponseWriter, r *http.Request) {
 	channelName := r.URL.Query().Get("channel")
 	subsOption, _ := strconv.Atoi(r.URL.Query().Get("subs"))
+	sortOption := r.URL.Query().Get("sort")
 	if channelName != "" {
-		s.handleOneChannel(w, r, channelName, subsOption)
+		s.handleOneChannel(w, r, channelName, subsOption, sortOption)
 	} else {
 		offset, limit := getOffsetAndLimit(r)
+		filter, err := newMonitorFilter(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid filter: %v", err), http.StatusBadRequest)
+			return
+		}
 		channels := s.channels.getAll()
+		if filter != nil {
+			for cn, c := range channels {
+				match, err := filter.matchChannel(c)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("Error getting information about channel %q: %v", cn, err), http.StatusInternalServerError)
+					return
+				}
+				if !match {
+					delete(channels, cn)
+				}
+			}
+		}
 		totalChannels := len(channels)
 		minoff, maxoff := getMinMaxOffset(offset, limit, totalChannels)
 		channelsz := &Channelsz{
@@ -413,14 +912,23 @@ func (s *StanServer) handleChannelsz(w http.ResponseWriter, r *http.Request) {
 				carr = append(carr, cz)
 			}
 			sort.Sort(byChannelName(carr))
-			carr = carr[minoff:maxoff]
+			// When sorting by lag, we need the subscriptions of all channels
+			// before applying offset and limit.
+			sortLag := sortOption == sortByLag
+			if !sortLag {
+				carr = carr[minoff:maxoff]
+			}
 			for _, cz := range carr {
 				cs := channels[cz.Name]
-				if err := updateChannelz(cz, cs, subsOption); err != nil {
+				if err := updateChannelz(cz, cs, subsOption, sortOption); err != nil {
 					http.Error(w, fmt.Sprintf("Error getting information about channel %q: %v", channelName, err), http.StatusInternalServerError)
 					return
 				}
 			}
+			if sortLag {
+				sort.Stable(byChannelLag(carr))
+				carr = carr[minoff:maxoff]
+			}
 			channelsz.Count = len(carr)
 			channelsz.Channels = carr
 		} else {
@@ -437,21 +945,21 @@ func (s *StanServer) handleChannelsz(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *StanServer) handleOneChannel(w http.ResponseWriter, r *http.Request, name string, subsOption int) {
+func (s *StanServer) handleOneChannel(w http.ResponseWriter, r *http.Request, name string, subsOption int, sortOption string) {
 	cs := s.channels.get(name)
 	if cs == nil {
 		http.Error(w, fmt.Sprintf("Channel %s not found", name), http.StatusNotFound)
 		return
 	}
 	channelz := &Channelz{Name: name}
-	if err := updateChannelz(channelz, cs, subsOption); err != nil {
+	if err := updateChannelz(channelz, cs, subsOption, sortOption); err != nil {
 		http.Error(w, fmt.Sprintf("Error getting information about channel %q: %v", name, err), http.StatusInternalServerError)
 		return
 	}
 	s.sendResponse(w, r, channelz)
 }
 
-func updateChannelz(cz *Channelz, c *channel, subsOption int) error {
+func updateChannelz(cz *Channelz, c *channel, subsOption int, sortOption string) error {
 	msgs, bytes, err := c.store.Msgs.State()
 	if err != nil {
 		return fmt.Errorf("unable to get message state: %v", err)
@@ -464,8 +972,14 @@ func updateChannelz(cz *Channelz, c *channel, subsOption int) error {
 	cz.Bytes = bytes
 	cz.FirstSeq = fseq
 	cz.LastSeq = lseq
+	now := time.Now().Unix()
+	cz.InMsgsRate, cz.InBytesRate = c.stats.in.rates(now)
+	cz.OutMsgsRate, cz.OutBytesRate = c.stats.out.rates(now)
 	if subsOption == 1 {
-		cz.Subscriptions = getMonitorChannelSubs(c.ss)
+		cz.Subscriptions = getMonitorChannelSubs(c.ss, c.store.Msgs, lseq)
+		if sortOption == sortByLag {
+			sort.Stable(bySubLag(cz.Subscriptions))
+		}
 	}
 	return nil
 }
@@ -501,3 +1015,231 @@ func getMinMaxOffset(offset, limit, total int) (int, int) {
 	}
 	return minoff, maxoff
 }
+
+// monitorFilter holds the filters that can be specified with URL arguments
+// to restrict the list of channels (or clients) returned by channelsz
+// (or clientsz). Filters are applied before offset and limit.
+type monitorFilter struct {
+	subjects        *util.Sublist          // channels matching this subject (wildcards allowed)
+	hasSubs         bool                   // with at least a subscription
+	stalled         bool                   // with at least a stalled subscription
+	minMsgs         int                    // channels with at least that many messages
+	prefix          string                 // client IDs starting with this prefix
+	offlineDurables bool                   // clients with offline durable subscriptions
+	offlineCIDs     map[string][]*subState // offline durables keyed by the ID of their client
+	name            string                 // clients with this application name
+	version         string                 // clients with this application version
+	hostname        string                 // clients running on this host
+	language        string                 // clients using this language
+	labels          []string               // clients with all these labels ("key=value" or "key")
+}
+
+// newMonitorFilter returns a filter based on the request's URL arguments,
+// or nil if there is no filter.
+func newMonitorFilter(r *http.Request) (*monitorFilter, error) {
+	query := r.URL.Query()
+	f := &monitorFilter{
+		prefix:   query.Get("prefix"),
+		name:     query.Get("name"),
+		version:  query.Get("version"),
+		hostname: query.Get("hostname"),
+		language: query.Get("language"),
+		labels:   query["label"],
+	}
+	if subject := query.Get("subject"); subject != "" {
+		f.subjects = util.NewSublist()
+		if err := f.subjects.Insert(subject, struct{}{}); err != nil {
+			return nil, fmt.Errorf("invalid subject %q", subject)
+		}
+	}
+	hasSubs, _ := strconv.Atoi(query.Get("has_subs"))
+	f.hasSubs = hasSubs == 1
+	stalled, _ := strconv.Atoi(query.Get("stalled"))
+	f.stalled = stalled == 1
+	offlineDurables, _ := strconv.Atoi(query.Get("offline_durables"))
+	f.offlineDurables = offlineDurables == 1
+	if minMsgs := query.Get("min_msgs"); minMsgs != "" {
+		var err error
+		f.minMsgs, err = strconv.Atoi(minMsgs)
+		if err != nil || f.minMsgs < 0 {
+			return nil, fmt.Errorf("invalid min_msgs %q", minMsgs)
+		}
+	}
+	if f.subjects == nil && !f.hasSubs && !f.stalled && f.minMsgs == 0 &&
+		f.prefix == "" && !f.offlineDurables && !f.hasClientMetadata() {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// hasClientMetadata returns true if the filter has client metadata filters.
+func (f *monitorFilter) hasClientMetadata() bool {
+	return f.name != "" || f.version != "" || f.hostname != "" || f.language != "" || len(f.labels) > 0
+}
+
+// matchClientMetadata returns true if the client passes all client metadata
+// filters. The client's Read-lock must be held by the caller.
+func (f *monitorFilter) matchClientMetadata(c *client) bool {
+	info := c.info
+	if (f.name != "" && info.Name != f.name) || (f.version != "" && info.Version != f.version) ||
+		(f.hostname != "" && info.Hostname != f.hostname) || (f.language != "" && info.Language != f.language) {
+		return false
+	}
+	for _, label := range f.labels {
+		found := false
+		for _, l := range info.Labels {
+			if l == label || strings.HasPrefix(l, label+"=") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *monitorFilter) matchSubject(subject string) bool {
+	return f.subjects == nil || len(f.subjects.Match(subject)) > 0
+}
+
+// matchChannel returns true if the channel passes all channel filters.
+func (f *monitorFilter) matchChannel(c *channel) (bool, error) {
+	if !f.matchSubject(c.name) {
+		return false, nil
+	}
+	if f.hasSubs || f.stalled {
+		hasSubs, stalled := c.ss.hasSubsOrStalled()
+		if (f.hasSubs && !hasSubs) || (f.stalled && !stalled) {
+			return false, nil
+		}
+	}
+	if f.minMsgs > 0 {
+		msgs, _, err := c.store.Msgs.State()
+		if err != nil {
+			return false, fmt.Errorf("unable to get message state: %v", err)
+		}
+		if msgs < f.minMsgs {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchClient returns true if the client passes all client filters.
+// A client matches the subject filter if it has at least a subscription
+// on a channel matching that subject.
+func (f *monitorFilter) matchClient(c *client) bool {
+	c.RLock()
+	id := c.info.ID
+	subs := c.getSubsCopy()
+	matchMetadata := f.matchClientMetadata(c)
+	c.RUnlock()
+	if !matchMetadata || !strings.HasPrefix(id, f.prefix) {
+		return false
+	}
+	if f.offlineDurables {
+		if _, ok := f.offlineCIDs[id]; !ok {
+			return false
+		}
+	}
+	if f.hasSubs && len(subs) == 0 {
+		return false
+	}
+	if f.subjects == nil && !f.stalled {
+		return true
+	}
+	for _, sub := range subs {
+		if !f.matchSubject(sub.subject) {
+			continue
+		}
+		if !f.stalled {
+			return true
+		}
+		sub.RLock()
+		stalled := sub.stalled
+		sub.RUnlock()
+		if stalled {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGoneClient returns true if the client `id`, which owns the offline
+// durables `subs` but is no longer registered, passes all client filters.
+// Since nothing else is known about such a client, it can not match the
+// metadata, has_subs and stalled filters.
+func (f *monitorFilter) matchGoneClient(id string, subs []*subState) bool {
+	if !strings.HasPrefix(id, f.prefix) || f.hasClientMetadata() || f.hasSubs || f.stalled {
+		return false
+	}
+	if f.subjects == nil {
+		return true
+	}
+	for _, sub := range subs {
+		if f.matchSubject(sub.subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSubsOrStalled returns if there is at least a subscription (including
+// offline durables) and if at least one of the subscriptions is stalled.
+func (ss *subStore) hasSubsOrStalled() (bool, bool) {
+	ss.RLock()
+	defer ss.RUnlock()
+	hasSubs := len(ss.psubs) > 0 || len(ss.qsubs) > 0 || len(ss.durables) > 0
+	isStalled := func(sub *subState) bool {
+		sub.RLock()
+		stalled := sub.stalled
+		sub.RUnlock()
+		return stalled
+	}
+	for _, sub := range ss.psubs {
+		if isStalled(sub) {
+			return hasSubs, true
+		}
+	}
+	for _, qsub := range ss.qsubs {
+		qsub.RLock()
+		for _, sub := range qsub.subs {
+			if isStalled(sub) {
+				qsub.RUnlock()
+				return hasSubs, true
+			}
+		}
+		qsub.RUnlock()
+	}
+	return hasSubs, false
+}
+
+// getClientsWithOfflineDurables returns the offline durable subscriptions
+// keyed by the ID of the client that owns them. Since the ClientID of
+// offline durables is cleared, it is extracted from the durable key.
+// The owner may no longer be registered.
+func (s *StanServer) getClientsWithOfflineDurables() map[string][]*subState {
+	cids := make(map[string][]*subState)
+	for _, c := range s.channels.getAll() {
+		ss := c.ss
+		ss.RLock()
+		for key, sub := range ss.durables {
+			sub.RLock()
+			offline := sub.ClientID == ""
+			durName := sub.DurableName
+			sub.RUnlock()
+			if !offline {
+				continue
+			}
+			suffix := fmt.Sprintf("-%s-%s", c.name, durName)
+			if strings.HasSuffix(key, suffix) {
+				cID := key[:len(key)-len(suffix)]
+				cids[cID] = append(cids[cID], sub)
+			}
+		}
+		ss.RUnlock()
+	}
+	return cids
+}
@@ -10,8 +10,11 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"os"
 	"reflect"
 	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	natsdTest "github.com/nats-io/gnatsd/test"
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 )
 
@@ -591,6 +595,13 @@ func TestMonitorClientsz(t *testing.T) {
 		goal := *expected[i]
 		// We cannot assume Now, so remove it for comparison
 		cz.Now = time.Time{}
+		// Same for the connect and last heartbeat times
+		for _, c := range cz.Clients {
+			if c.ConnectTime == nil {
+				t.Fatalf("Iter=%v - Path=%q - Expected connect time for client %q", i, ClientsPath+paths[i], c.ID)
+			}
+			c.ConnectTime, c.LastHB = nil, nil
+		}
 		// We have only 1 sub per client, so DeepEqual will be ok.
 		if !reflect.DeepEqual(cz, goal) {
 			t.Fatalf("Iter=%v - Path=%q - Expected to get %v, got %v", i, ClientsPath+paths[i], goal, cz)
@@ -717,6 +728,11 @@ func TestMonitorClientz(t *testing.T) {
 		} else if cz.Subscriptions != nil {
 			t.Fatalf("Iter=%v - Path=%q - Did not expect to get subscriptions, got %v", i, ClientsPath+paths[i], cz.Subscriptions)
 		}
+		// We cannot assume the connect and last heartbeat times
+		if cz.ConnectTime == nil {
+			t.Fatalf("Iter=%v - Path=%q - Expected connect time", i, ClientsPath+paths[i])
+		}
+		cz.ConnectTime, cz.LastHB = nil, nil
 		if !reflect.DeepEqual(cz, goal) {
 			t.Fatalf("Iter=%v - Path=%q - Expected to get %v, got %v", i, ClientsPath+paths[i], goal, cz)
 		}
@@ -1119,3 +1135,876 @@ func TestMonitorDurableSubs(t *testing.T) {
 		}
 	}
 }
+
+func TestMonitorStatsAndSortByLag(t *testing.T) {
+	resetPreviousHTTPConnections()
+	s := runMonitorServer(t, GetDefaultOptions())
+	defer s.Shutdown()
+
+	sc1, err := stan.Connect(clusterName, "me1")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer sc1.Close()
+	sc2, err := stan.Connect(clusterName, "me2")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer sc2.Close()
+
+	// Channel "bar" has a subscription that keeps up.
+	if _, err := sc1.Subscribe("bar", func(_ *stan.Msg) {}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// Channel "foo" has one subscription that keeps up and one that never
+	// acks, with a MaxInflight of 1 and an AckWait small enough that the
+	// first message gets redelivered.
+	ch := make(chan bool, 1)
+	if _, err := sc1.Subscribe("foo", func(_ *stan.Msg) {}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if _, err := sc2.Subscribe("foo", func(m *stan.Msg) {
+		if m.Redelivered {
+			select {
+			case ch <- true:
+			default:
+			}
+		}
+	}, stan.SetManualAckMode(), stan.MaxInflight(1), stan.AckWait(ackWaitInMs(50))); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+
+	payload := []byte("hello")
+	total := 10
+	for i := 0; i < total; i++ {
+		if err := sc1.Publish("foo", payload); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+		if err := sc1.Publish("bar", payload); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	if err := Wait(ch); err != nil {
+		t.Fatal("Did not get our redelivered message")
+	}
+
+	resp, body := getBody(t, ChannelsPath+"?channel=foo&subs=1&sort=lag", expectedJSON)
+	defer resp.Body.Close()
+	cz := &Channelz{}
+	if err := json.Unmarshal(body, cz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	resp.Body.Close()
+	expectedMsgsRate := float64(total) / statsRateWindow
+	expectedBytesRate := float64(total*len(payload)) / statsRateWindow
+	if cz.InMsgsRate != expectedMsgsRate || cz.InBytesRate != expectedBytesRate {
+		t.Fatalf("Expected in rates to be %v msgs/sec and %v bytes/sec, got %v and %v",
+			expectedMsgsRate, expectedBytesRate, cz.InMsgsRate, cz.InBytesRate)
+	}
+	// Both subscriptions got at least one message, and the first one
+	// got them all.
+	if cz.OutMsgsRate <= expectedMsgsRate || cz.OutBytesRate <= expectedBytesRate {
+		t.Fatalf("Unexpected out rates: %v msgs/sec and %v bytes/sec", cz.OutMsgsRate, cz.OutBytesRate)
+	}
+	if len(cz.Subscriptions) != 2 {
+		t.Fatalf("Expected 2 subscriptions, got %v", len(cz.Subscriptions))
+	}
+	lagging := cz.Subscriptions[0]
+	if lagging.Lag != uint64(total-1) {
+		t.Fatalf("Expected lag to be %v, got %v", total-1, lagging.Lag)
+	}
+	if lagging.RedeliveryCount == 0 {
+		t.Fatal("Expected redelivery count to be positive")
+	}
+	// The age is from the message's timestamp, so it is not reset by the
+	// redelivery that happened after at least the 50ms AckWait.
+	if lagging.PendingCount != 1 || lagging.OldestUnackedAge < 50 {
+		t.Fatalf("Unexpected pending count or oldest unacked age: %v - %v", lagging.PendingCount, lagging.OldestUnackedAge)
+	}
+	if lagging.PendingBytes != int64(len(payload)) {
+		t.Fatalf("Expected %v pending bytes, got %v", len(payload), lagging.PendingBytes)
+	}
+	if sub := cz.Subscriptions[1]; sub.Lag != 0 || sub.RedeliveryCount != 0 {
+		t.Fatalf("Expected no lag nor redelivery, got %v - %v", sub.Lag, sub.RedeliveryCount)
+	}
+
+	// Without sorting by lag, "bar" would be first.
+	resp, body = getBody(t, ChannelsPath+"?subs=1&sort=lag&limit=1", expectedJSON)
+	defer resp.Body.Close()
+	channelsz := &Channelsz{}
+	if err := json.Unmarshal(body, channelsz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	resp.Body.Close()
+	if channelsz.Count != 1 || channelsz.Total != 2 || channelsz.Channels[0].Name != "foo" {
+		t.Fatalf("Expected channel foo first, got %v", channelsz.Channels[0].Name)
+	}
+
+	// Same for clients, without sorting by lag, "me1" would be first.
+	resp, body = getBody(t, ClientsPath+"?subs=1&sort=lag", expectedJSON)
+	defer resp.Body.Close()
+	clientsz := &Clientsz{}
+	if err := json.Unmarshal(body, clientsz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	resp.Body.Close()
+	if clientsz.Count != 2 || clientsz.Clients[0].ID != "me2" {
+		t.Fatalf("Expected client me2 first, got %v", clientsz.Clients[0].ID)
+	}
+	if lag := clientsz.Clients[0].Subscriptions["foo"][0].Lag; lag != uint64(total-1) {
+		t.Fatalf("Expected lag to be %v, got %v", total-1, lag)
+	}
+}
+
+func TestMonitorChannelsAndClientsFilters(t *testing.T) {
+	resetPreviousHTTPConnections()
+	s := runMonitorServer(t, GetDefaultOptions())
+	defer s.Shutdown()
+
+	sc1, err := stan.Connect(clusterName, "me1")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer sc1.Close()
+	sc2, err := stan.Connect(clusterName, "me2")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer sc2.Close()
+	sc3, err := stan.Connect(clusterName, "other")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer sc3.Close()
+
+	channelsLookupOrCreate(t, s, "orders.a.us")
+	for i := 0; i < 3; i++ {
+		if err := sc3.Publish("orders.a.eu", []byte("hello")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	if err := sc3.Publish("orders.b.eu", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	// Create a subscription that becomes stalled.
+	ch := make(chan bool, 1)
+	if _, err := sc1.Subscribe("orders.b.eu", func(_ *stan.Msg) {
+		ch <- true
+	}, stan.SetManualAckMode(), stan.MaxInflight(1), stan.DeliverAllAvailable()); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := Wait(ch); err != nil {
+		t.Fatal("Did not get our message")
+	}
+	// And an offline durable
+	dur, err := sc2.Subscribe("foo", func(_ *stan.Msg) {}, stan.DurableName("dur"))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	dur.Close()
+
+	channelsTests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"foo", "orders.a.eu", "orders.a.us", "orders.b.eu"}},
+		{"?subject=orders.*.eu", []string{"orders.a.eu", "orders.b.eu"}},
+		{"?subject=orders.>&min_msgs=2", []string{"orders.a.eu"}},
+		{"?has_subs=1", []string{"foo", "orders.b.eu"}},
+		{"?stalled=1", []string{"orders.b.eu"}},
+		{"?subject=orders.a.*&has_subs=1", []string{}},
+	}
+	for _, test := range channelsTests {
+		resp, body := getBody(t, ChannelsPath+test.query, expectedJSON)
+		defer resp.Body.Close()
+		channelsz := &Channelsz{}
+		if err := json.Unmarshal(body, channelsz); err != nil {
+			t.Fatalf("Got an error unmarshalling the body: %v", err)
+		}
+		resp.Body.Close()
+		if channelsz.Total != len(test.expected) || channelsz.Count != len(test.expected) {
+			t.Fatalf("Query %q: expected %v channels, got total=%v count=%v",
+				test.query, len(test.expected), channelsz.Total, channelsz.Count)
+		}
+		for i, name := range test.expected {
+			if channelsz.Names[i] != name {
+				t.Fatalf("Query %q: expected channels %v, got %v", test.query, test.expected, channelsz.Names)
+			}
+		}
+	}
+
+	clientsTests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"me1", "me2", "other"}},
+		{"?prefix=me", []string{"me1", "me2"}},
+		{"?offline_durables=1", []string{"me2"}},
+		{"?has_subs=1", []string{"me1"}},
+		{"?stalled=1", []string{"me1"}},
+		{"?subject=orders.*.eu", []string{"me1"}},
+		{"?prefix=other&has_subs=1", []string{}},
+	}
+	for _, test := range clientsTests {
+		resp, body := getBody(t, ClientsPath+test.query, expectedJSON)
+		defer resp.Body.Close()
+		clientsz := &Clientsz{}
+		if err := json.Unmarshal(body, clientsz); err != nil {
+			t.Fatalf("Got an error unmarshalling the body: %v", err)
+		}
+		resp.Body.Close()
+		if clientsz.Total != len(test.expected) || clientsz.Count != len(test.expected) {
+			t.Fatalf("Query %q: expected %v clients, got total=%v count=%v",
+				test.query, len(test.expected), clientsz.Total, clientsz.Count)
+		}
+		for i, id := range test.expected {
+			if clientsz.Clients[i].ID != id {
+				t.Fatalf("Query %q: expected clients %v, got %v", test.query, test.expected, clientsz.Clients)
+			}
+		}
+	}
+
+	// Invalid filters should return a bad request
+	monitorExpectStatus(t, ChannelsPath+"?subject=foo..bar", http.StatusBadRequest)
+	monitorExpectStatus(t, ChannelsPath+"?min_msgs=abc", http.StatusBadRequest)
+	monitorExpectStatus(t, ClientsPath+"?subject=foo.>.bar", http.StatusBadRequest)
+}
+
+func TestMonitorClientsOfflineDurablesOfGoneClient(t *testing.T) {
+	resetPreviousHTTPConnections()
+	s := runMonitorServer(t, GetDefaultOptions())
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	if _, err := sc.Subscribe("bar", func(_ *stan.Msg) {}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// The owner of these durables closes its connection without
+	// unsubscribing, so the durables are offline and the client gone.
+	gone, err := stan.Connect(clusterName, "gone")
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	for _, channel := range []string{"foo", "orders"} {
+		if _, err := gone.Subscribe(channel, func(_ *stan.Msg) {}, stan.DurableName("dur")); err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+	}
+	gone.Close()
+	waitForNumClients(t, s, 1)
+
+	for _, test := range []struct {
+		query    string
+		expected []string
+	}{
+		{"?offline_durables=1", []string{"gone"}},
+		{"?offline_durables=1&prefix=go", []string{"gone"}},
+		{"?offline_durables=1&prefix=me", []string{}},
+		{"?offline_durables=1&subject=orders", []string{"gone"}},
+		{"?offline_durables=1&subject=bar", []string{}},
+		{"?offline_durables=1&has_subs=1", []string{}},
+		{"?offline_durables=1&name=app", []string{}},
+		{"?has_subs=1", []string{clientName}},
+	} {
+		resp, body := getBody(t, ClientsPath+test.query, expectedJSON)
+		clientsz := &Clientsz{}
+		if err := json.Unmarshal(body, clientsz); err != nil {
+			t.Fatalf("Got an error unmarshalling the body: %v", err)
+		}
+		resp.Body.Close()
+		if clientsz.Total != len(test.expected) || clientsz.Count != len(test.expected) {
+			t.Fatalf("Query %q: expected %v clients, got total=%v count=%v",
+				test.query, len(test.expected), clientsz.Total, clientsz.Count)
+		}
+		for i, id := range test.expected {
+			if clientsz.Clients[i].ID != id {
+				t.Fatalf("Query %q: expected clients %v, got %v", test.query, test.expected, clientsz.Clients)
+			}
+		}
+	}
+
+	// With subs=1, the offline durables of the gone client are reported.
+	resp, body := getBody(t, ClientsPath+"?offline_durables=1&subs=1", expectedJSON)
+	defer resp.Body.Close()
+	clientsz := &Clientsz{}
+	if err := json.Unmarshal(body, clientsz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	if len(clientsz.Clients) != 1 {
+		t.Fatalf("Expected a single client, got %v", clientsz.Clients)
+	}
+	cz := clientsz.Clients[0]
+	if len(cz.Subscriptions) != 2 {
+		t.Fatalf("Expected subscriptions on 2 channels, got %v", cz.Subscriptions)
+	}
+	for _, channel := range []string{"foo", "orders"} {
+		subs := cz.Subscriptions[channel]
+		if len(subs) != 1 || subs[0].DurableName != "dur" || !subs[0].IsOffline {
+			t.Fatalf("Expected offline durable on %q, got %v", channel, subs)
+		}
+	}
+}
+
+func TestMonitorClientsMetadata(t *testing.T) {
+	resetPreviousHTTPConnections()
+	opts := GetDefaultOptions()
+	opts.ClientHBInterval = 50 * time.Millisecond
+	opts.ClientHBTimeout = 250 * time.Millisecond
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Error on connect: %v", err)
+	}
+	defer nc.Close()
+	connect := func(clientID string, md *spb.ClientMetadata) *spb.ConnectResponse {
+		return rawConnect(t, s, nc, clientID, func(req *spb.ConnectRequest) { req.Metadata = md })
+	}
+	for _, c := range []struct {
+		clientID string
+		md       *spb.ClientMetadata
+	}{
+		{"app1", &spb.ClientMetadata{Name: "orders", Version: "1.2.0", Hostname: "host1", Language: "go",
+			Labels: []string{"env=prod", "region=us"}}},
+		{"app2", &spb.ClientMetadata{Name: "billing", Version: "2.0.0", Hostname: "host2", Language: "go",
+			Labels: []string{"env=dev"}}},
+		{"plain", nil},
+	} {
+		if cr := connect(c.clientID, c.md); cr.Error != "" {
+			t.Fatalf("Error on connect: %v", cr.Error)
+		}
+	}
+
+	// Labels must be in the form key=value
+	if cr := connect("invalid", &spb.ClientMetadata{Labels: []string{"=x"}}); cr.Error != ErrInvalidConnReq.Error() {
+		t.Fatalf("Expected error %v, got %q", ErrInvalidConnReq, cr.Error)
+	}
+
+	// Wait for the client to respond to a heartbeat.
+	var cz *Clientz
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, body := getBody(t, ClientsPath+"?client=app1", expectedJSON)
+		resp.Body.Close()
+		cz = &Clientz{}
+		if err := json.Unmarshal(body, cz); err != nil {
+			t.Fatalf("Got an error unmarshalling the body: %v", err)
+		}
+		if cz.LastHB != nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if cz.Name != "orders" || cz.Version != "1.2.0" || cz.Hostname != "host1" || cz.Language != "go" ||
+		!reflect.DeepEqual(cz.Labels, map[string]string{"env": "prod", "region": "us"}) {
+		t.Fatalf("Unexpected client metadata: %+v", cz)
+	}
+	if cz.Protocol != int32(protocolVersion) ||
+		!reflect.DeepEqual(cz.Capabilities, []string{"pub_ack_sequence", "pub_batch", "ping", "replay_end", "sub_pause"}) {
+		t.Fatalf("Unexpected protocol and capabilities: %v %v", cz.Protocol, cz.Capabilities)
+	}
+	if cz.ConnectTime == nil || time.Since(*cz.ConnectTime) > 5*time.Second {
+		t.Fatalf("Unexpected connect time: %v", cz.ConnectTime)
+	}
+	if cz.LastHB == nil || cz.LastHB.Before(*cz.ConnectTime) {
+		t.Fatalf("Unexpected last heartbeat: %v", cz.LastHB)
+	}
+
+	clientsTests := []struct {
+		query    string
+		expected []string
+	}{
+		{"?name=orders", []string{"app1"}},
+		{"?version=2.0.0", []string{"app2"}},
+		{"?hostname=host1", []string{"app1"}},
+		{"?language=go", []string{"app1", "app2"}},
+		{"?label=env", []string{"app1", "app2"}},
+		{"?label=env=dev", []string{"app2"}},
+		{"?label=env&label=region=us", []string{"app1"}},
+		{"?label=region=eu", []string{}},
+		{"?name=orders&prefix=other", []string{}},
+	}
+	for _, test := range clientsTests {
+		resp, body := getBody(t, ClientsPath+test.query, expectedJSON)
+		clientsz := &Clientsz{}
+		if err := json.Unmarshal(body, clientsz); err != nil {
+			t.Fatalf("Got an error unmarshalling the body: %v", err)
+		}
+		resp.Body.Close()
+		if clientsz.Total != len(test.expected) || clientsz.Count != len(test.expected) {
+			t.Fatalf("Query %q: expected %v clients, got total=%v count=%v",
+				test.query, len(test.expected), clientsz.Total, clientsz.Count)
+		}
+		for i, id := range test.expected {
+			if clientsz.Clients[i].ID != id || clientsz.Clients[i].ConnectTime == nil {
+				t.Fatalf("Query %q: expected clients %v, got %v", test.query, test.expected, clientsz.Clients)
+			}
+		}
+	}
+}
+
+func TestMonitorHealthz(t *testing.T) {
+	resetPreviousHTTPConnections()
+	s := runMonitorServer(t, GetDefaultOptions())
+	defer s.Shutdown()
+
+	checkHealth := func(query string, expectedStatus int, expectedState string, expectedReady bool) *Healthz {
+		resp, body := getBodyEx(t, http.DefaultClient, "http", HealthzPath+query, expectedStatus, expectedJSON)
+		defer resp.Body.Close()
+		hz := &Healthz{}
+		if err := json.Unmarshal(body, hz); err != nil {
+			stackFatalf(t, "Got an error unmarshalling the body: %v", err)
+		}
+		if hz.State != expectedState || hz.Ready != expectedReady {
+			stackFatalf(t, "Expected state %v and ready %v, got %v and %v",
+				expectedState, expectedReady, hz.State, hz.Ready)
+		}
+		expectedRole := ""
+		switch expectedState {
+		case "FT_ACTIVE":
+			expectedRole = "active"
+		case "FT_STANDBY":
+			expectedRole = "standby"
+		}
+		if hz.FTRole != expectedRole {
+			stackFatalf(t, "Expected FT role %q, got %q", expectedRole, hz.FTRole)
+		}
+		return hz
+	}
+	checkHealth("", http.StatusOK, "STANDALONE", true)
+	checkHealth("?ready=1", http.StatusOK, "STANDALONE", true)
+
+	// Cause the store to fail storing messages
+	s.channels.Lock()
+	s.channels.store = &mockedStore{Store: s.channels.store}
+	s.channels.Unlock()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	if err := sc.Publish("foo", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	mms := channelsGet(t, s.channels, "foo").store.Msgs.(*mockedMsgStore)
+	mms.Lock()
+	mms.failStore = true
+	mms.Unlock()
+	if err := sc.Publish("foo", []byte("hello")); err == nil {
+		t.Fatal("Expected publish to fail")
+	}
+	hz := checkHealth("", http.StatusInsufficientStorage, "STANDALONE", true)
+	if hz.StoreError != errOnPurpose.Error() {
+		t.Fatalf("Expected store error %q, got %q", errOnPurpose.Error(), hz.StoreError)
+	}
+	// Once messages are stored again, the error should be cleared.
+	mms.Lock()
+	mms.failStore = false
+	mms.Unlock()
+	if err := sc.Publish("foo", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	checkHealth("", http.StatusOK, "STANDALONE", true)
+
+	// Same if the store fails to flush messages.
+	mms.Lock()
+	mms.failFlush = true
+	mms.Unlock()
+	if err := sc.Publish("foo", []byte("hello")); err == nil {
+		t.Fatal("Expected publish to fail")
+	}
+	hz = checkHealth("", http.StatusInsufficientStorage, "STANDALONE", true)
+	if hz.StoreError != errOnPurpose.Error() {
+		t.Fatalf("Expected store error %q, got %q", errOnPurpose.Error(), hz.StoreError)
+	}
+	mms.Lock()
+	mms.failFlush = false
+	mms.Unlock()
+	if err := sc.Publish("foo", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	checkHealth("", http.StatusOK, "STANDALONE", true)
+
+	// Simulate a recovery in progress
+	atomic.StoreInt64(&s.ready, 0)
+	checkHealth("", http.StatusOK, "STANDALONE", false)
+	checkHealth("?ready=1", http.StatusServiceUnavailable, "STANDALONE", false)
+	atomic.StoreInt64(&s.ready, 1)
+
+	setState := func(state State) {
+		s.mu.Lock()
+		s.setState(state)
+		s.mu.Unlock()
+	}
+	setState(FTStandby)
+	checkHealth("", http.StatusAccepted, "FT_STANDBY", false)
+	checkHealth("?ready=1", http.StatusServiceUnavailable, "FT_STANDBY", false)
+
+	setState(FTActive)
+	checkHealth("", http.StatusOK, "FT_ACTIVE", true)
+	checkHealth("?ready=1", http.StatusOK, "FT_ACTIVE", true)
+
+	s.mu.Lock()
+	s.lastError = errOnPurpose
+	s.setState(Failed)
+	s.mu.Unlock()
+	hz = checkHealth("?ready=1", http.StatusInternalServerError, "FAILED", false)
+	if hz.LastError != errOnPurpose.Error() {
+		t.Fatalf("Expected last error %q, got %q", errOnPurpose.Error(), hz.LastError)
+	}
+}
+
+func TestMonitorMsgTracez(t *testing.T) {
+	resetPreviousHTTPConnections()
+	s := runMonitorServer(t, GetDefaultOptions())
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	url := fmt.Sprintf("http://%s:%d%s", monitorHost, monitorPort, MsgTracePath)
+	doRequest := func(method, query string, expectedStatus int) *MsgTracez {
+		req, err := http.NewRequest(method, url+query, nil)
+		if err != nil {
+			stackFatalf(t, "Error creating request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			stackFatalf(t, "Error on request: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != expectedStatus {
+			stackFatalf(t, "Expected a %d response, got %d", expectedStatus, resp.StatusCode)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			stackFatalf(t, "Got an error reading the body: %v", err)
+		}
+		mt := &MsgTracez{}
+		if err := json.Unmarshal(body, mt); err != nil {
+			stackFatalf(t, "Got an error unmarshalling the body: %v", err)
+		}
+		return mt
+	}
+	waitForEvents := func(query string, expected ...string) *MsgTracez {
+		var mt *MsgTracez
+		timeout := time.Now().Add(5 * time.Second)
+		for time.Now().Before(timeout) {
+			mt = doRequest("GET", query, http.StatusOK)
+			if len(mt.Events) == len(expected) {
+				break
+			}
+			time.Sleep(15 * time.Millisecond)
+		}
+		got := make(map[string]int)
+		for _, e := range mt.Events {
+			got[e.Event]++
+		}
+		want := make(map[string]int)
+		for _, e := range expected {
+			want[e]++
+		}
+		if !reflect.DeepEqual(got, want) {
+			stackFatalf(t, "Expected events %v, got %v", expected, mt.Events)
+		}
+		return mt
+	}
+
+	// Ack the message only when redelivered.
+	ch := make(chan bool, 1)
+	if _, err := sc.Subscribe("foo", func(m *stan.Msg) {
+		if m.Redelivered {
+			m.Ack()
+			ch <- true
+		}
+	}, stan.SetManualAckMode(), stan.AckWait(ackWaitInMs(50))); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+
+	// Need a message identifier or a channel and sequence
+	doRequest("POST", "", http.StatusBadRequest)
+	doRequest("POST", "?channel=foo", http.StatusBadRequest)
+	if mt := doRequest("POST", "?guid=myguid", http.StatusOK); mt.GUID != "myguid" || len(mt.Events) != 0 {
+		t.Fatalf("Unexpected trace: %v", mt)
+	}
+
+	// Publish with our own GUID
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	pm := &spb.PubMsg{ClientID: clientName, Guid: "myguid", Subject: "foo", Data: []byte("hello")}
+	data, _ := pm.Marshal()
+	if _, err := nc.Request(s.info.Publish+".foo", data, time.Second); err != nil {
+		t.Fatalf("Error on publish: %v", err)
+	}
+	if err := Wait(ch); err != nil {
+		t.Fatal("Did not get our redelivered message")
+	}
+	mt := waitForEvents("?guid=myguid", msgTraceReceived, msgTraceBatched, msgTraceStored,
+		msgTraceDelivered, msgTracePublisherAcked, msgTraceRedelivered, msgTraceAcked)
+	if mt.Channel != "foo" || mt.Sequence != 1 {
+		t.Fatalf("Unexpected channel and sequence: %v - %v", mt.Channel, mt.Sequence)
+	}
+	for i, e := range []string{msgTraceReceived, msgTraceBatched, msgTraceStored} {
+		if mt.Events[i].Event != e {
+			t.Fatalf("Expected event %v to be %q, got %q", i, e, mt.Events[i].Event)
+		}
+	}
+	if last := mt.Events[len(mt.Events)-1]; last.Event != msgTraceAcked || !strings.Contains(last.Details, "client="+clientName) {
+		t.Fatalf("Unexpected last event: %v", last)
+	}
+	// The same trace can be retrieved with the channel and sequence.
+	if mt2 := doRequest("GET", "?channel=foo&seq=1", http.StatusOK); !reflect.DeepEqual(mt, mt2) {
+		t.Fatalf("Expected %v, got %v", mt, mt2)
+	}
+
+	// Trace the next message by channel and sequence.
+	doRequest("POST", "?channel=foo&seq=2", http.StatusOK)
+	guid, err := sc.PublishAsync("foo", []byte("hello"), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	if err := Wait(ch); err != nil {
+		t.Fatal("Did not get our redelivered message")
+	}
+	mt = waitForEvents("?channel=foo&seq=2", msgTraceStored, msgTraceDelivered, msgTraceRedelivered, msgTraceAcked)
+	if mt.GUID != guid {
+		t.Fatalf("Expected guid to be %q, got %q", guid, mt.GUID)
+	}
+
+	// Get all traces
+	resp, body := getBody(t, MsgTracePath, expectedJSON)
+	defer resp.Body.Close()
+	mtsz := &MsgTracesz{}
+	if err := json.Unmarshal(body, mtsz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	resp.Body.Close()
+	if len(mtsz.Traces) != 2 || mtsz.Traces[0].Sequence != 1 || mtsz.Traces[1].Sequence != 2 {
+		t.Fatalf("Unexpected traces: %v", mtsz.Traces)
+	}
+
+	// Stop tracing
+	doRequest("DELETE", "?guid=myguid", http.StatusNoContent)
+	doRequest("GET", "?guid=myguid", http.StatusNotFound)
+	doRequest("GET", "?channel=foo&seq=1", http.StatusNotFound)
+	doRequest("DELETE", "?channel=foo&seq=2", http.StatusNoContent)
+	doRequest("DELETE", "?channel=foo&seq=2", http.StatusNotFound)
+	if s.msgTracer.enabled() {
+		t.Fatal("Tracer should not be enabled")
+	}
+}
+
+func TestMonitorReloadz(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	writeReloadConfig(t, "hb_interval: \"10s\"")
+	opts := GetDefaultOptions()
+	opts.ConfigFile = reloadConfFile
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	resetPreviousHTTPConnections()
+
+	// Only POST requests are accepted.
+	monitorExpectStatus(t, ReloadPath, http.StatusMethodNotAllowed)
+
+	url := fmt.Sprintf("http://%s:%d%s", monitorHost, monitorPort, ReloadPath)
+	post := func(expectedStatus int) []byte {
+		resp, err := http.Post(url, "", nil)
+		if err != nil {
+			stackFatalf(t, "Error on POST: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != expectedStatus {
+			stackFatalf(t, "Expected status %v, got %v (%s)", expectedStatus, resp.StatusCode, body)
+		}
+		return body
+	}
+	reloadz := Reloadz{}
+	if err := json.Unmarshal(post(http.StatusOK), &reloadz); err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	if len(reloadz.Changes) != 1 || reloadz.Changes[0] != "hb_interval=10s" {
+		t.Fatalf("Unexpected changes: %v", reloadz.Changes)
+	}
+	// Unsafe changes are rejected.
+	writeReloadConfig(t, "store: file")
+	if body := post(http.StatusBadRequest); !strings.Contains(string(body), "store") {
+		t.Fatalf("Unexpected error: %s", body)
+	}
+}
+
+func TestMonitorSeekz(t *testing.T) {
+	defer os.Remove(credsFile)
+	writeCredentials(t, "admin_token: \"admin\"")
+	opts := GetDefaultOptions()
+	opts.CredentialsFile = credsFile
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	resetPreviousHTTPConnections()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	ch := make(chan uint64, 10)
+	if _, err := sc.Subscribe("foo", func(m *stan.Msg) { ch <- m.Sequence },
+		stan.DurableName("dur"), stan.DeliverAllAvailable()); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		<-ch
+	}
+
+	// Only POST requests are accepted.
+	monitorExpectStatus(t, SeekPath, http.StatusMethodNotAllowed)
+
+	postWithToken := func(token, query string, expectedStatus int) []byte {
+		url := fmt.Sprintf("http://%s:%d%s?%s", monitorHost, monitorPort, SeekPath, query)
+		req, err := http.NewRequest("POST", url, nil)
+		if err != nil {
+			stackFatalf(t, "Error creating request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			stackFatalf(t, "Error on POST: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != expectedStatus {
+			stackFatalf(t, "Expected status %v, got %v (%s)", expectedStatus, resp.StatusCode, body)
+		}
+		return body
+	}
+	post := func(query string, expectedStatus int) []byte {
+		return postWithToken("admin", query, expectedStatus)
+	}
+	// The admin token is required.
+	postWithToken("", "channel=foo&durable=dur&client_id="+clientName+"&seq=1", http.StatusUnauthorized)
+	postWithToken("wrong", "channel=foo&durable=dur&client_id="+clientName+"&seq=1", http.StatusUnauthorized)
+
+	post("channel=foo&durable=dur&client_id="+clientName, http.StatusBadRequest)
+	post("channel=foo&durable=dur&client_id="+clientName+"&seq=abc", http.StatusBadRequest)
+	post("channel=foo&durable=other&client_id="+clientName+"&seq=1", http.StatusNotFound)
+	post("channel=bar&durable=dur&client_id="+clientName+"&seq=1", http.StatusNotFound)
+
+	seekz := Seekz{}
+	if err := json.Unmarshal(post("channel=foo&durable=dur&client_id="+clientName+"&seq=3", http.StatusOK), &seekz); err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	if seekz.Channel != "foo" || seekz.DurableName != "dur" || seekz.ClientID != clientName || seekz.Sequence != 3 {
+		t.Fatalf("Unexpected response: %+v", seekz)
+	}
+	// The online durable receives the messages again from sequence 3.
+	for _, expected := range []uint64{3, 4, 5} {
+		select {
+		case seq := <-ch:
+			if seq != expected {
+				t.Fatalf("Expected message %v, got %v", expected, seq)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Did not get message %v", expected)
+		}
+	}
+}
+
+func TestMonitorPausez(t *testing.T) {
+	defer os.Remove(credsFile)
+	writeCredentials(t, "admin_token: \"admin\"")
+	opts := GetDefaultOptions()
+	opts.CredentialsFile = credsFile
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	resetPreviousHTTPConnections()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	ch := make(chan uint64, 10)
+	if _, err := sc.Subscribe("foo", func(m *stan.Msg) { ch <- m.Sequence },
+		stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+
+	// Only POST requests are accepted.
+	monitorExpectStatus(t, PausePath, http.StatusMethodNotAllowed)
+
+	postWithToken := func(token, query string, expectedStatus int) []byte {
+		url := fmt.Sprintf("http://%s:%d%s?%s", monitorHost, monitorPort, PausePath, query)
+		req, err := http.NewRequest("POST", url, nil)
+		if err != nil {
+			stackFatalf(t, "Error creating request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			stackFatalf(t, "Error on POST: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != expectedStatus {
+			stackFatalf(t, "Expected status %v, got %v (%s)", expectedStatus, resp.StatusCode, body)
+		}
+		return body
+	}
+	post := func(query string, expectedStatus int) []byte {
+		return postWithToken("admin", query, expectedStatus)
+	}
+	// The admin token is required.
+	postWithToken("", "channel=foo&durable=dur&client_id="+clientName+"&action=pause", http.StatusUnauthorized)
+	postWithToken("wrong", "channel=foo&durable=dur&client_id="+clientName+"&action=pause", http.StatusUnauthorized)
+
+	post("channel=foo&durable=dur&client_id="+clientName, http.StatusBadRequest)
+	post("channel=foo&durable=other&client_id="+clientName+"&action=pause", http.StatusNotFound)
+
+	pausez := Pausez{}
+	if err := json.Unmarshal(post("channel=foo&durable=dur&client_id="+clientName+"&action=pause&redeliveries=1",
+		http.StatusOK), &pausez); err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	if pausez.Channel != "foo" || pausez.DurableName != "dur" || !pausez.Paused || !pausez.Redeliveries {
+		t.Fatalf("Unexpected response: %+v", pausez)
+	}
+	if err := sc.Publish("foo", []byte("msg")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	// The paused state is reported in the subscriptions.
+	_, body := getBody(t, ChannelsPath+"?subs=1", expectedJSON)
+	cz := Channelsz{}
+	if err := json.Unmarshal(body, &cz); err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	if len(cz.Channels) != 1 || len(cz.Channels[0].Subscriptions) != 1 {
+		t.Fatalf("Unexpected channels: %+v", cz.Channels)
+	}
+	if sz := cz.Channels[0].Subscriptions[0]; !sz.IsPaused || !sz.RedeliveryPaused || sz.LastSent != 0 {
+		t.Fatalf("Unexpected subscription: %+v", sz)
+	}
+
+	post("channel=foo&durable=dur&client_id="+clientName+"&action=resume", http.StatusOK)
+	select {
+	case seq := <-ch:
+		if seq != 1 {
+			t.Fatalf("Expected message 1, got %v", seq)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Did not get message after resume")
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Maximum number of messages that can be traced at the same time.
+	maxMsgTraces = 1024
+	// Maximum number of events recorded for a given message.
+	maxMsgTraceEvents = 256
+)
+
+// Events recorded in a message's timeline.
+const (
+	msgTraceReceived       = "received"
+	msgTraceBatched        = "batched"
+	msgTraceStored         = "stored"
+	msgTraceStoreFailed    = "store_failed"
+	msgTracePublisherAcked = "publisher_acked"
+	msgTraceDelivered      = "delivered"
+	msgTraceRedelivered    = "redelivered"
+	msgTraceAcked          = "acked"
+)
+
+// Errors related to message tracing
+var (
+	errMsgTraceNotFound  = errors.New("message trace not found")
+	errTooManyMsgTraces  = errors.New("too many message traces")
+	errMsgTraceNoMsgInfo = errors.New("message guid or channel and sequence required")
+)
+
+// MsgTraceEvent is an event in the timeline of a traced message.
+type MsgTraceEvent struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Details string    `json:"details,omitempty"`
+}
+
+// MsgTracez is the timeline of a traced message. A message is traced
+// either by its publisher GUID (in which case the channel and sequence
+// are known once the message is stored), or by its channel and sequence.
+type MsgTracez struct {
+	GUID      string           `json:"guid,omitempty"`
+	Channel   string           `json:"channel,omitempty"`
+	Sequence  uint64           `json:"seq,omitempty"`
+	Truncated bool             `json:"truncated,omitempty"`
+	Events    []*MsgTraceEvent `json:"events"`
+}
+
+// msgTracer records the timeline of marked messages.
+type msgTracer struct {
+	// Number of traced messages, used to quickly check (without locking)
+	// if there is anything to trace. Keep this first for atomic alignment.
+	count int64
+
+	sync.Mutex
+	guids map[string]*MsgTracez
+	seqs  map[string]map[uint64]*MsgTracez
+}
+
+func newMsgTracer() *msgTracer {
+	return &msgTracer{
+		guids: make(map[string]*MsgTracez),
+		seqs:  make(map[string]map[uint64]*MsgTracez),
+	}
+}
+
+// enabled returns true if at least one message is being traced.
+func (t *msgTracer) enabled() bool {
+	return atomic.LoadInt64(&t.count) > 0
+}
+
+// lookup returns the trace for the given guid, or channel and sequence.
+// Lock held on entry.
+func (t *msgTracer) lookup(guid, channel string, seq uint64) *MsgTracez {
+	if guid != "" {
+		return t.guids[guid]
+	}
+	return t.seqs[channel][seq]
+}
+
+// linkSeq registers the trace under the given channel and sequence.
+// Lock held on entry.
+func (t *msgTracer) linkSeq(mt *MsgTracez, channel string, seq uint64) {
+	mt.Channel = channel
+	mt.Sequence = seq
+	cseqs := t.seqs[channel]
+	if cseqs == nil {
+		cseqs = make(map[uint64]*MsgTracez)
+		t.seqs[channel] = cseqs
+	}
+	cseqs[seq] = mt
+}
+
+// mark starts tracing the message with the given guid, or channel and
+// sequence. If the message is already traced, the existing timeline is
+// returned.
+func (t *msgTracer) mark(guid, channel string, seq uint64) (*MsgTracez, error) {
+	if guid == "" && (channel == "" || seq == 0) {
+		return nil, errMsgTraceNoMsgInfo
+	}
+	t.Lock()
+	defer t.Unlock()
+	if mt := t.lookup(guid, channel, seq); mt != nil {
+		return mt.copy(), nil
+	}
+	if atomic.LoadInt64(&t.count) >= maxMsgTraces {
+		return nil, errTooManyMsgTraces
+	}
+	mt := &MsgTracez{GUID: guid, Events: make([]*MsgTraceEvent, 0, 8)}
+	if guid != "" {
+		t.guids[guid] = mt
+	} else {
+		t.linkSeq(mt, channel, seq)
+	}
+	atomic.AddInt64(&t.count, 1)
+	return mt.copy(), nil
+}
+
+// unmark stops tracing the message and discards its timeline.
+func (t *msgTracer) unmark(guid, channel string, seq uint64) error {
+	t.Lock()
+	defer t.Unlock()
+	mt := t.lookup(guid, channel, seq)
+	if mt == nil {
+		return errMsgTraceNotFound
+	}
+	if mt.GUID != "" {
+		delete(t.guids, mt.GUID)
+	}
+	if cseqs := t.seqs[mt.Channel]; cseqs != nil && cseqs[mt.Sequence] == mt {
+		delete(cseqs, mt.Sequence)
+		if len(cseqs) == 0 {
+			delete(t.seqs, mt.Channel)
+		}
+	}
+	atomic.AddInt64(&t.count, -1)
+	return nil
+}
+
+// get returns a copy of the timeline of the traced message.
+func (t *msgTracer) get(guid, channel string, seq uint64) (*MsgTracez, error) {
+	t.Lock()
+	defer t.Unlock()
+	mt := t.lookup(guid, channel, seq)
+	if mt == nil {
+		return nil, errMsgTraceNotFound
+	}
+	return mt.copy(), nil
+}
+
+// getAll returns a copy of all the traced messages' timelines.
+func (t *msgTracer) getAll() []*MsgTracez {
+	t.Lock()
+	defer t.Unlock()
+	traces := make([]*MsgTracez, 0, len(t.guids))
+	for _, mt := range t.guids {
+		traces = append(traces, mt.copy())
+	}
+	for _, cseqs := range t.seqs {
+		for _, mt := range cseqs {
+			// Skip those traced by guid, they were already added above.
+			if t.guids[mt.GUID] != mt {
+				traces = append(traces, mt.copy())
+			}
+		}
+	}
+	return traces
+}
+
+// guidEvent records an event for the message with this guid, if traced.
+func (t *msgTracer) guidEvent(guid, event, details string) {
+	t.Lock()
+	if mt := t.guids[guid]; mt != nil {
+		mt.addEvent(event, details)
+	}
+	t.Unlock()
+}
+
+// stored records that the message with this guid has been stored with
+// the given sequence. From now on, events related to this channel and
+// sequence are recorded in the same timeline. If the message is not traced
+// by guid but its channel and sequence were marked, the guid is recorded.
+func (t *msgTracer) stored(guid, channel string, seq uint64) {
+	t.Lock()
+	mt := t.guids[guid]
+	if mt != nil {
+		// If this sequence was also marked, keep only the guid's trace.
+		if prev := t.seqs[channel][seq]; prev != nil && prev != mt {
+			atomic.AddInt64(&t.count, -1)
+		}
+		t.linkSeq(mt, channel, seq)
+	} else if mt = t.seqs[channel][seq]; mt != nil && mt.GUID == "" {
+		mt.GUID = guid
+	}
+	if mt != nil {
+		mt.addEvent(msgTraceStored, "")
+	}
+	t.Unlock()
+}
+
+// seqEvent records an event for the message with this channel and sequence,
+// if traced.
+func (t *msgTracer) seqEvent(channel string, seq uint64, event, details string) {
+	t.Lock()
+	if mt := t.seqs[channel][seq]; mt != nil {
+		mt.addEvent(event, details)
+	}
+	t.Unlock()
+}
+
+// addEvent appends an event to the timeline, unless the maximum
+// number of events has been reached.
+// Tracer lock held on entry.
+func (mt *MsgTracez) addEvent(event, details string) {
+	if len(mt.Events) >= maxMsgTraceEvents {
+		mt.Truncated = true
+		return
+	}
+	mt.Events = append(mt.Events, &MsgTraceEvent{Time: time.Now(), Event: event, Details: details})
+}
+
+// copy returns a copy of the timeline that can be used without
+// holding the tracer lock. Events are immutable.
+// Tracer lock held on entry.
+func (mt *MsgTracez) copy() *MsgTracez {
+	c := *mt
+	c.Events = make([]*MsgTraceEvent, len(mt.Events))
+	copy(c.Events, mt.Events)
+	return &c
+}
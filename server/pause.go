@@ -0,0 +1,317 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/audit"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/util"
+)
+
+// Pausez describes the state of a durable after a pause or resume request
+type Pausez struct {
+	ClusterID    string    `json:"cluster_id"`
+	ServerID     string    `json:"server_id"`
+	Now          time.Time `json:"now"`
+	Channel      string    `json:"channel"`
+	DurableName  string    `json:"durable_name"`
+	ClientID     string    `json:"client_id,omitempty"`
+	QGroup       string    `json:"queue_name,omitempty"`
+	Paused       bool      `json:"paused"`
+	Redeliveries bool      `json:"redelivery_paused,omitempty"`
+}
+
+// adminPauseSubject returns the subject administrative pause requests are
+// received on.
+func adminPauseSubject(clusterID string) string {
+	return fmt.Sprintf("%s.%s.pause", adminPrefix, clusterID)
+}
+
+// processSubPauseRequest processes a request from a client pausing or
+// resuming one of its subscriptions.
+func (s *StanServer) processSubPauseRequest(m *nats.Msg) {
+	req := &spb.SubPauseRequest{}
+	if err := req.Unmarshal(m.Data); err != nil {
+		s.log.Errorf("Invalid sub pause request from %s", m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidPauseReq)
+		return
+	}
+	if err := s.checkClientIdentity(req.ClientID, req.ConnID); err != nil {
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
+	// With partitioning, another server may be handling this channel.
+	if s.partitions != nil {
+		if r := s.partitions.sl.Match(req.Subject); len(r) == 0 {
+			return
+		}
+	}
+	// The members of a wildcard subscription share their AckInbox, and
+	// can't be paused.
+	if !util.IsSubjectLiteral(req.Subject) {
+		s.log.Errorf("[Client:%s] Sub pause request for wildcard subject %s", req.ClientID, req.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidPauseReq)
+		return
+	}
+	c := s.channels.get(req.Subject)
+	if c == nil {
+		s.log.Errorf("[Client:%s] Sub pause request missing subject %s", req.ClientID, req.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidSub)
+		return
+	}
+	sub := c.ss.LookupByAckInbox(req.Inbox)
+	if sub != nil {
+		sub.RLock()
+		if sub.ClientID != req.ClientID {
+			sub = nil
+		}
+		sub.RUnlock()
+	}
+	if sub == nil {
+		s.log.Errorf("[Client:%s] Sub pause request for missing inbox %s", req.ClientID, req.Inbox)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidSub)
+		return
+	}
+	if err := s.pauseSub(c, sub, req.ClientID, req.Pause, req.Redeliveries); err != nil {
+		s.log.Errorf("[Client:%s] Unable to pause subscription on %s: %v", req.ClientID, req.Subject, err)
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
+	sub.RLock()
+	details := fmt.Sprintf("channel=%s subid=%d", req.Subject, sub.ID)
+	if sub.QGroup != "" {
+		details = fmt.Sprintf("%s queue=%s", details, sub.QGroup)
+	} else if sub.DurableName != "" {
+		details = fmt.Sprintf("%s durable=%s", details, sub.DurableName)
+	}
+	sub.RUnlock()
+	s.logPause(req.ClientID, details, req.Pause, req.Redeliveries)
+
+	resp := &pb.SubscriptionResponse{AckInbox: req.Inbox}
+	b, _ := resp.Marshal()
+	s.ncs.Publish(m.Reply, b)
+}
+
+// processAdminPauseRequest processes an administrative pause request
+// received from NATS.
+func (s *StanServer) processAdminPauseRequest(m *nats.Msg) {
+	req := &spb.PauseRequest{}
+	if err := req.Unmarshal(m.Data); err != nil {
+		s.log.Errorf("Invalid pause request from %s: %v", m.Subject, err)
+		s.sendPauseResponse(m.Reply, ErrInvalidPauseReq)
+		return
+	}
+	// With partitioning, ignore requests for channels handled by other
+	// servers, so that only the server handling the channel replies.
+	if s.partitions != nil {
+		if r := s.partitions.sl.Match(req.Channel); len(r) == 0 {
+			return
+		}
+	}
+	if err := s.checkAdminToken(req.AdminToken); err != nil {
+		s.log.Errorf("Pause request on channel %s rejected: %v", req.Channel, err)
+		s.sendPauseResponse(m.Reply, err)
+		return
+	}
+	s.sendPauseResponse(m.Reply, s.pauseDurable(req))
+}
+
+func (s *StanServer) sendPauseResponse(reply string, err error) {
+	if reply == "" {
+		return
+	}
+	resp := &spb.PauseResponse{}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	b, _ := resp.Marshal()
+	s.nc.Publish(reply, b)
+}
+
+// handlePausez pauses or resumes a durable. The durable is identified
+// with `channel=<name>&durable=<name>` and either `client_id=<id>` or, for a
+// durable queue group, `queue=<name>`. The action is given with
+// `action=pause` or `action=resume`, and redeliveries are also paused
+// with `redeliveries=1`. Like /seekz, the request must carry the admin
+// token in an Authorization header.
+func (s *StanServer) handlePausez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "pause requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.checkAdminToken(bearerToken(r)); err != nil {
+		s.log.Errorf("Pause request from %s rejected: %v", r.RemoteAddr, err)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	req := &spb.PauseRequest{
+		Channel:     query.Get("channel"),
+		DurableName: query.Get("durable"),
+		ClientID:    query.Get("client_id"),
+		QGroup:      query.Get("queue"),
+	}
+	switch action := query.Get("action"); action {
+	case "pause":
+		req.Pause = true
+	case "resume":
+	default:
+		http.Error(w, fmt.Sprintf("Invalid action %q, should be pause or resume", action), http.StatusBadRequest)
+		return
+	}
+	req.Redeliveries, _ = strconv.ParseBool(query.Get("redeliveries"))
+	switch err := s.pauseDurable(req); err {
+	case nil:
+	case ErrUnknownDurable:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pausez := &Pausez{
+		ClusterID:    s.info.ClusterID,
+		ServerID:     s.serverID,
+		Now:          time.Now(),
+		Channel:      req.Channel,
+		DurableName:  req.DurableName,
+		ClientID:     req.ClientID,
+		QGroup:       req.QGroup,
+		Paused:       req.Pause,
+		Redeliveries: req.Pause && req.Redeliveries,
+	}
+	s.sendResponse(w, r, pausez)
+}
+
+// pauseDurable pauses or resumes the durable subscription, or durable
+// queue group, described by `req`, whether it is online or offline.
+func (s *StanServer) pauseDurable(req *spb.PauseRequest) error {
+	if req.Channel == "" || req.DurableName == "" || (req.ClientID == "" && req.QGroup == "") {
+		return ErrInvalidPauseReq
+	}
+	c := s.channels.get(req.Channel)
+	if c == nil {
+		return ErrUnknownDurable
+	}
+	ss := c.ss
+	ss.RLock()
+	var sub *subState
+	if req.QGroup != "" {
+		if qs := ss.qsubs[fmt.Sprintf("%s:%s", req.DurableName, req.QGroup)]; qs != nil {
+			qs.RLock()
+			if qs.shadow != nil {
+				sub = qs.shadow
+			} else if len(qs.subs) > 0 {
+				sub = qs.subs[0]
+			}
+			qs.RUnlock()
+		}
+	} else {
+		sub = ss.durables[fmt.Sprintf("%s-%s-%s", req.ClientID, req.Channel, req.DurableName)]
+	}
+	ss.RUnlock()
+	if sub == nil {
+		return ErrUnknownDurable
+	}
+	if err := s.pauseSub(c, sub, req.ClientID, req.Pause, req.Redeliveries); err != nil {
+		return err
+	}
+	s.logPause(req.ClientID, durableDetails(req.Channel, req.DurableName, req.QGroup), req.Pause, req.Redeliveries)
+	return nil
+}
+
+// logPause logs and audits that a subscription has been paused or resumed.
+func (s *StanServer) logPause(clientID, details string, pause, redeliveries bool) {
+	if pause {
+		s.log.Noticef("[Client:%s] Paused subscription %s (redeliveries=%v)", clientID, details, redeliveries)
+		s.auditf(audit.EventSubPause, clientID, "%s redeliveries=%v", details, redeliveries)
+	} else {
+		s.log.Noticef("[Client:%s] Resumed subscription %s", clientID, details)
+		s.auditf(audit.EventSubResume, clientID, "%s", details)
+	}
+}
+
+// pauseSub pauses or resumes the deliveries to `sub`, or to all the members
+// of its queue group, and persists the new state. When pausing, messages
+// pending acknowledgment are still redelivered unless `redeliveries` is true.
+// For an offline durable, `clientID` is the ClientID stored with the durable.
+func (s *StanServer) pauseSub(c *channel, sub *subState, clientID string, pause, redeliveries bool) error {
+	redeliveries = pause && redeliveries
+
+	sub.RLock()
+	qs := sub.qstate
+	sub.RUnlock()
+	if qs != nil {
+		qs.Lock()
+		members := qs.subs
+		if qs.shadow != nil {
+			members = append([]*subState{qs.shadow}, members...)
+		}
+		var err error
+		for _, m := range members {
+			m.Lock()
+			err = s.setSubPaused(m, pause, redeliveries, m.ClientID)
+			m.Unlock()
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			qs.paused, qs.pauseRedeliveries = pause, redeliveries
+		}
+		online := len(qs.subs) > 0
+		qs.Unlock()
+		if err != nil {
+			return err
+		}
+		if !pause && online {
+			s.sendAvailableMessagesToQueue(c, qs)
+		}
+		return nil
+	}
+	sub.Lock()
+	err := s.setSubPaused(sub, pause, redeliveries, clientID)
+	online := !sub.isOfflineDurableSubscriber()
+	sub.Unlock()
+	if err != nil {
+		return err
+	}
+	if !pause && online {
+		s.sendAvailableMessages(c, sub)
+	}
+	return nil
+}
+
+// setSubPaused sets the paused state of the subscription and persists it
+// with `clientID` as ClientID. If redeliveries were held, the messages
+// whose ack wait has expired are redelivered right away on resume.
+// sub's lock held on entry.
+func (s *StanServer) setSubPaused(sub *subState, pause, redeliveries bool, clientID string) error {
+	wasPaused, heldRedeliveries := sub.Paused, sub.Paused && sub.PauseRedeliveries
+	sub.Paused, sub.PauseRedeliveries = pause, redeliveries
+
+	memClientID := sub.ClientID
+	sub.ClientID = clientID
+	err := sub.store.UpdateSub(&sub.SubState)
+	sub.ClientID = memClientID
+	if err != nil {
+		sub.Paused, sub.PauseRedeliveries = wasPaused, heldRedeliveries
+		return err
+	}
+	if heldRedeliveries && !redeliveries && len(sub.acksPending) > 0 && !sub.isOfflineDurableSubscriber() {
+		if sub.ackTimer == nil {
+			s.setupAckTimer(sub, 0)
+		} else {
+			sub.ackTimer.Reset(0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats-streaming-server/util"
+)
+
+// ClientPermissions defines the channels that the clients whose ID
+// matches ClientID are allowed to publish to and subscribe to.
+// ClientID can end with `*` to match any client ID with the given prefix,
+// or simply be `*` to match all clients. Channels can contain wildcards.
+type ClientPermissions struct {
+	ClientID  string
+	Publish   []string
+	Subscribe []string
+}
+
+// clientIDPattern matches a client ID, or all client IDs starting
+// with a prefix if the pattern ends with `*`.
+type clientIDPattern struct {
+	clientID string
+	isPrefix bool
+}
+
+// clientPermissions is the compiled form of ClientPermissions.
+type clientPermissions struct {
+	clientIDPattern
+	pub *util.Sublist
+	sub *util.Sublist
+}
+
+// permissions holds the compiled clients permissions. When permissions
+// are configured, clients are allowed to publish to and subscribe to only
+// the channels that are explicitly granted to them.
+type permissions struct {
+	clients []*clientPermissions
+}
+
+// newPermissions validates and compiles the given clients permissions.
+// Returns nil (meaning that everything is allowed) if the list is empty.
+func newPermissions(list []*ClientPermissions) (*permissions, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	perms := &permissions{clients: make([]*clientPermissions, 0, len(list))}
+	for _, cp := range list {
+		pattern, err := newClientIDPattern(cp.ClientID, "permissions")
+		if err != nil {
+			return nil, err
+		}
+		pub, err := newPermissionsSublist(cp.Publish)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := newPermissionsSublist(cp.Subscribe)
+		if err != nil {
+			return nil, err
+		}
+		perms.clients = append(perms.clients, &clientPermissions{
+			clientIDPattern: pattern,
+			pub:             pub,
+			sub:             sub,
+		})
+	}
+	return perms, nil
+}
+
+// newPermissionsSublist returns a sublist containing the given channels.
+func newPermissionsSublist(channels []string) (*util.Sublist, error) {
+	sl := util.NewSublist()
+	for _, channel := range channels {
+		if !util.IsSubjectValid(channel, true) {
+			return nil, fmt.Errorf("invalid channel %q in permissions", channel)
+		}
+		if err := sl.Insert(channel, channel); err != nil {
+			return nil, fmt.Errorf("invalid channel %q in permissions: %v", channel, err)
+		}
+	}
+	return sl, nil
+}
+
+// newClientIDPattern validates and returns the client ID pattern `pattern`.
+// `where` is used in the error message.
+func newClientIDPattern(pattern, where string) (clientIDPattern, error) {
+	cid := pattern
+	isPrefix := strings.HasSuffix(cid, "*")
+	if isPrefix {
+		cid = cid[:len(cid)-1]
+	}
+	if cid != "" || !isPrefix {
+		if !clientIDRegEx.MatchString(cid) {
+			return clientIDPattern{}, fmt.Errorf("invalid client ID %q in %s", pattern, where)
+		}
+	}
+	return clientIDPattern{clientID: cid, isPrefix: isPrefix}, nil
+}
+
+// matches returns true if the pattern matches the given client ID.
+func (p *clientIDPattern) matches(clientID string) bool {
+	if p.isPrefix {
+		return strings.HasPrefix(clientID, p.clientID)
+	}
+	return clientID == p.clientID
+}
+
+// canPublish returns true if the client is allowed to publish to the channel.
+func (p *permissions) canPublish(clientID, channel string) bool {
+	if p == nil {
+		return true
+	}
+	for _, cp := range p.clients {
+		if cp.matches(clientID) && len(cp.pub.Match(channel)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// canSubscribe returns true if the client is allowed to subscribe to the channel.
+func (p *permissions) canSubscribe(clientID, channel string) bool {
+	if p == nil {
+		return true
+	}
+	for _, cp := range p.clients {
+		if cp.matches(clientID) && len(cp.sub.Match(channel)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getPermissions returns the clients permissions, which can be
+// changed on configuration reload.
+func (s *StanServer) getPermissions() *permissions {
+	s.optsMu.RLock()
+	perms := s.perms
+	s.optsMu.RUnlock()
+	return perms
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats-streaming"
+)
+
+func TestPermissionsInvalid(t *testing.T) {
+	for _, cp := range []*ClientPermissions{
+		{ClientID: "a.b"},
+		{ClientID: "**"},
+		{ClientID: ""},
+		{ClientID: "me", Publish: []string{"foo..bar"}},
+		{ClientID: "me", Subscribe: []string{"foo.>.bar"}},
+	} {
+		opts := GetDefaultOptions()
+		opts.Permissions = []*ClientPermissions{cp}
+		s, err := RunServerWithOpts(opts, nil)
+		if s != nil || err == nil || !strings.Contains(err.Error(), "in permissions") {
+			if s != nil {
+				s.Shutdown()
+			}
+			t.Fatalf("Expected error for permissions %v, got %v", cp, err)
+		}
+	}
+}
+
+func TestPermissionsMatch(t *testing.T) {
+	perms, err := newPermissions([]*ClientPermissions{
+		{ClientID: "me", Publish: []string{"foo", "bar.*"}},
+		{ClientID: "svc_*", Publish: []string{"svc.>"}, Subscribe: []string{">"}},
+		{ClientID: "*", Subscribe: []string{"public.*"}},
+	})
+	if err != nil {
+		t.Fatalf("Error creating permissions: %v", err)
+	}
+	for _, test := range []struct {
+		clientID string
+		channel  string
+		pub      bool
+		sub      bool
+	}{
+		{"me", "foo", true, false},
+		{"me", "bar.baz", true, false},
+		{"me", "bar.baz.bat", false, false},
+		{"me", "public.foo", false, true},
+		{"me2", "foo", false, false},
+		{"svc_a", "svc.a.b", true, true},
+		{"svc_a", "foo", false, true},
+		{"svc", "svc.a", false, false},
+		{"other", "public.foo", false, true},
+		{"other", "public", false, false},
+	} {
+		if pub := perms.canPublish(test.clientID, test.channel); pub != test.pub {
+			t.Fatalf("Expected publish by %q to %q to be %v, got %v", test.clientID, test.channel, test.pub, pub)
+		}
+		if sub := perms.canSubscribe(test.clientID, test.channel); sub != test.sub {
+			t.Fatalf("Expected subscribe by %q to %q to be %v, got %v", test.clientID, test.channel, test.sub, sub)
+		}
+	}
+	// No permissions means everything is allowed.
+	perms, _ = newPermissions(nil)
+	if !perms.canPublish("me", "foo") || !perms.canSubscribe("me", "foo") {
+		t.Fatal("Everything should be allowed without permissions")
+	}
+}
+
+func TestPermissionsPubSub(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.ID = clusterName
+	opts.Permissions = []*ClientPermissions{
+		{ClientID: clientName, Publish: []string{"foo"}, Subscribe: []string{"foo"}},
+	}
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	ch := make(chan bool, 1)
+	if _, err := sc.Subscribe("foo", func(_ *stan.Msg) { ch <- true }); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := sc.Publish("foo", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	if err := Wait(ch); err != nil {
+		t.Fatal("Did not get our message")
+	}
+	if _, err := sc.Subscribe("bar", func(_ *stan.Msg) {}); err == nil || err.Error() != ErrSubNotAuthorized.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrSubNotAuthorized, err)
+	}
+	if err := sc.Publish("bar", []byte("hello")); err == nil || err.Error() != ErrPubNotAuthorized.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrPubNotAuthorized, err)
+	}
+
+	// Other clients are not allowed anything.
+	sc2, err := stan.Connect(clusterName, "other", stan.PubAckWait(time.Second))
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer sc2.Close()
+	if err := sc2.Publish("foo", []byte("hello")); err == nil || err.Error() != ErrPubNotAuthorized.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrPubNotAuthorized, err)
+	}
+	if _, err := sc2.Subscribe("foo", func(_ *stan.Msg) {}); err == nil || err.Error() != ErrSubNotAuthorized.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrSubNotAuthorized, err)
+	}
+}
+
+func TestPermissionsReload(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, `permissions: [{client_id: "*", publish: "foo"}]`)
+	defer s.Shutdown()
+
+	if !s.getPermissions().canPublish("me", "foo") || s.getPermissions().canPublish("me", "bar") {
+		t.Fatal("Unexpected permissions")
+	}
+	writeReloadConfig(t, `permissions: [{client_id: "*", publish: "bar"}]`)
+	changes, err := s.reload()
+	if err != nil || len(changes) != 1 || changes[0] != "permissions" {
+		t.Fatalf("Unexpected result: changes=%v err=%v", changes, err)
+	}
+	if s.getPermissions().canPublish("me", "foo") || !s.getPermissions().canPublish("me", "bar") {
+		t.Fatal("Unexpected permissions")
+	}
+	// Invalid permissions are rejected.
+	writeReloadConfig(t, `permissions: [{client_id: "a.b", publish: "baz"}]`)
+	if err := s.Reload(); err == nil || !strings.Contains(err.Error(), "in permissions") {
+		t.Fatalf("Expected error, got %v", err)
+	}
+	if !s.getPermissions().canPublish("me", "bar") {
+		t.Fatal("Permissions should not have changed")
+	}
+	// Removing the permissions allows everything.
+	writeReloadConfig(t, `hb_interval: "10s"`)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	if !s.getPermissions().canPublish("me", "baz") {
+		t.Fatal("Everything should be allowed")
+	}
+}
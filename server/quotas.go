@@ -0,0 +1,153 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ClientLimits defines the quotas of a client. In Options.ClientLimits,
+// a zero value means unlimited. In Options.PerClientLimits, a zero value
+// means that the global limit is inherited and a negative value means
+// unlimited.
+type ClientLimits struct {
+	MaxSubscriptions int   // Maximum number of subscriptions of a client
+	MaxPubMsgs       int   // Maximum number of messages a client can publish per second
+	MaxPubBytes      int64 // Maximum number of bytes a client can publish per second
+}
+
+// clientLimitsEntry associates limits with the clients they apply to.
+type clientLimitsEntry struct {
+	clientIDPattern
+	limits ClientLimits
+}
+
+// clientLimits holds the validated clients limits.
+type clientLimits struct {
+	global    ClientLimits
+	perClient []*clientLimitsEntry
+}
+
+// Counters of the requests rejected because of the clients quotas.
+type quotaStats struct {
+	rejectedClients uint64
+	rejectedSubs    uint64
+	rejectedPubs    uint64
+}
+
+// newClientLimits validates the maximum number of clients and the global
+// and per client limits of `opts`. Returns nil (meaning that clients are
+// unlimited) if no limit is set.
+func newClientLimits(opts *Options) (*clientLimits, error) {
+	if opts.MaxClients < 0 {
+		return nil, fmt.Errorf("max clients cannot be negative (%v)", opts.MaxClients)
+	}
+	global := opts.ClientLimits
+	if global.MaxSubscriptions < 0 || global.MaxPubMsgs < 0 || global.MaxPubBytes < 0 {
+		return nil, fmt.Errorf("client limits cannot be negative (%+v)", global)
+	}
+	if global == (ClientLimits{}) && len(opts.PerClientLimits) == 0 {
+		return nil, nil
+	}
+	cl := &clientLimits{global: global}
+	for cid, limits := range opts.PerClientLimits {
+		pattern, err := newClientIDPattern(cid, "client limits")
+		if err != nil {
+			return nil, err
+		}
+		cl.perClient = append(cl.perClient, &clientLimitsEntry{clientIDPattern: pattern, limits: *limits})
+	}
+	return cl, nil
+}
+
+// get returns the limits that apply to the client `clientID`. If several
+// per client limits match, an exact client ID takes precedence over a
+// prefix, and a longer prefix over a shorter one.
+func (cl *clientLimits) get(clientID string) ClientLimits {
+	if cl == nil {
+		return ClientLimits{}
+	}
+	var best *clientLimitsEntry
+	for _, e := range cl.perClient {
+		if !e.matches(clientID) {
+			continue
+		}
+		if best == nil || (best.isPrefix && (!e.isPrefix || len(e.clientID) > len(best.clientID))) {
+			best = e
+		}
+	}
+	limits := cl.global
+	if best != nil {
+		inherit := func(v, global int64) int64 {
+			if v < 0 {
+				return 0
+			} else if v == 0 {
+				return global
+			}
+			return v
+		}
+		limits.MaxSubscriptions = int(inherit(int64(best.limits.MaxSubscriptions), int64(limits.MaxSubscriptions)))
+		limits.MaxPubMsgs = int(inherit(int64(best.limits.MaxPubMsgs), int64(limits.MaxPubMsgs)))
+		limits.MaxPubBytes = inherit(best.limits.MaxPubBytes, limits.MaxPubBytes)
+	}
+	return limits
+}
+
+// getClientLimits returns the limits that apply to the client `clientID`,
+// which can be changed on configuration reload.
+func (s *StanServer) getClientLimits(clientID string) ClientLimits {
+	s.optsMu.RLock()
+	limits := s.clientLimits.get(clientID)
+	s.optsMu.RUnlock()
+	return limits
+}
+
+// checkSubQuota returns an error if the client has reached its maximum
+// number of subscriptions.
+func (s *StanServer) checkSubQuota(clientID string) error {
+	maxSubs := s.getClientLimits(clientID).MaxSubscriptions
+	if maxSubs == 0 {
+		return nil
+	}
+	c := s.clients.lookup(clientID)
+	if c == nil {
+		// Reported by the subscription processing.
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	if len(c.subs) >= maxSubs {
+		c.rejectedSubs++
+		atomic.AddUint64(&s.quotas.rejectedSubs, 1)
+		return ErrTooManyClientSubs
+	}
+	return nil
+}
+
+// checkPubQuota accounts for a message of `size` bytes published by the
+// client `c` at time `now` (expressed in seconds), and returns an error
+// if that exceeds the client's publish rate.
+func (s *StanServer) checkPubQuota(c *client, now int64, size uint64) error {
+	limits := s.getClientLimits(c.info.ID)
+	if limits.MaxPubMsgs == 0 && limits.MaxPubBytes == 0 {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	ps := &c.pubSample
+	if ps.time != now {
+		ps.time = now
+		ps.msgs = 0
+		ps.bytes = 0
+	}
+	if (limits.MaxPubMsgs > 0 && ps.msgs >= uint64(limits.MaxPubMsgs)) ||
+		(limits.MaxPubBytes > 0 && ps.bytes+size > uint64(limits.MaxPubBytes)) {
+		c.rejectedPubs++
+		atomic.AddUint64(&s.quotas.rejectedPubs, 1)
+		return ErrPubRateExceeded
+	}
+	ps.msgs++
+	ps.bytes += size
+	return nil
+}
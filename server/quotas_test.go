@@ -0,0 +1,219 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/go-nats-streaming"
+)
+
+func TestQuotasInvalidLimits(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		update func(o *Options)
+	}{
+		{"negative max clients", func(o *Options) { o.MaxClients = -1 }},
+		{"negative global limits", func(o *Options) { o.ClientLimits.MaxPubMsgs = -1 }},
+		{"invalid client ID", func(o *Options) {
+			o.PerClientLimits = map[string]*ClientLimits{"a.b": {MaxSubscriptions: 1}}
+		}},
+	} {
+		opts := GetDefaultOptions()
+		test.update(opts)
+		if s, err := RunServerWithOpts(opts, nil); s != nil || err == nil {
+			if s != nil {
+				s.Shutdown()
+			}
+			t.Fatalf("%s: expected error", test.name)
+		}
+	}
+}
+
+func TestQuotasClientLimitsPrecedence(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.ClientLimits = ClientLimits{MaxSubscriptions: 10, MaxPubMsgs: 100, MaxPubBytes: 1000}
+	opts.PerClientLimits = map[string]*ClientLimits{
+		"*":        {MaxPubBytes: 2000},
+		"svc_*":    {MaxSubscriptions: 20},
+		"svc_db_*": {MaxPubMsgs: -1},
+		"svc_db_1": {MaxSubscriptions: 1},
+	}
+	cl, err := newClientLimits(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		clientID string
+		expected ClientLimits
+	}{
+		{"me", ClientLimits{MaxSubscriptions: 10, MaxPubMsgs: 100, MaxPubBytes: 2000}},
+		{"svc_a", ClientLimits{MaxSubscriptions: 20, MaxPubMsgs: 100, MaxPubBytes: 1000}},
+		{"svc_db_2", ClientLimits{MaxSubscriptions: 10, MaxPubMsgs: 0, MaxPubBytes: 1000}},
+		{"svc_db_1", ClientLimits{MaxSubscriptions: 1, MaxPubMsgs: 100, MaxPubBytes: 1000}},
+	} {
+		if limits := cl.get(test.clientID); limits != test.expected {
+			t.Fatalf("Client %q: expected limits %+v, got %+v", test.clientID, test.expected, limits)
+		}
+	}
+}
+
+func TestQuotasMaxClients(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.MaxClients = 2
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	sc1 := NewDefaultConnection(t)
+	defer sc1.Close()
+	sc2, err := stan.Connect(clusterName, "me2")
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer sc2.Close()
+	expectConnectErr(t, "me3", ErrTooManyClients)
+
+	// Once a client is closed, a new one can connect.
+	sc2.Close()
+	sc3, err := stan.Connect(clusterName, "me3")
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	sc3.Close()
+}
+
+func TestQuotasMaxSubscriptions(t *testing.T) {
+	resetPreviousHTTPConnections()
+	opts := GetDefaultOptions()
+	opts.ClientLimits.MaxSubscriptions = 2
+	opts.PerClientLimits = map[string]*ClientLimits{"admin": {MaxSubscriptions: -1}}
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	cb := func(_ *stan.Msg) {}
+	if _, err := sc.Subscribe("foo", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	sub, err := sc.Subscribe("bar", cb)
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if _, err := sc.Subscribe("baz", cb); err == nil || err.Error() != ErrTooManyClientSubs.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrTooManyClientSubs, err)
+	}
+	// Once a subscription is removed, a new one can be created.
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error on unsubscribe: %v", err)
+	}
+	if _, err := sc.Subscribe("baz", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+
+	admin, err := stan.Connect(clusterName, "admin")
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer admin.Close()
+	for i := 0; i < 5; i++ {
+		if _, err := admin.Subscribe("foo", cb); err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+	}
+
+	resp, body := getBody(t, ServerPath, expectedJSON)
+	resp.Body.Close()
+	serverz := &Serverz{}
+	if err := json.Unmarshal(body, serverz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	if serverz.Rejected == nil || *serverz.Rejected != (Quotaz{Subscriptions: 1}) {
+		t.Fatalf("Unexpected rejected requests: %+v", serverz.Rejected)
+	}
+	resp, body = getBody(t, ClientsPath+"?client="+clientName, expectedJSON)
+	resp.Body.Close()
+	clientz := &Clientz{}
+	if err := json.Unmarshal(body, clientz); err != nil {
+		t.Fatalf("Got an error unmarshalling the body: %v", err)
+	}
+	if clientz.RejectedSubs != 1 || clientz.RejectedPubs != 0 {
+		t.Fatalf("Unexpected rejected requests: subs=%v pubs=%v", clientz.RejectedSubs, clientz.RejectedPubs)
+	}
+}
+
+func TestQuotasPublishRate(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.ClientLimits = ClientLimits{MaxPubMsgs: 2, MaxPubBytes: 10}
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	// A message bigger than the bytes rate can never be published.
+	if err := sc.Publish("foo", []byte("this is too big")); err == nil || err.Error() != ErrPubRateExceeded.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrPubRateExceeded, err)
+	}
+
+	// Check the accounting for a given second.
+	c := s.clients.lookup(clientName)
+	for i, test := range []struct {
+		now      int64
+		size     uint64
+		expected error
+	}{
+		{100, 4, nil},
+		{100, 7, ErrPubRateExceeded}, // bytes
+		{100, 6, nil},
+		{100, 0, ErrPubRateExceeded}, // messages
+		{101, 10, nil},
+	} {
+		if err := s.checkPubQuota(c, test.now, test.size); err != test.expected {
+			t.Fatalf("Test %v: expected %v, got %v", i, test.expected, err)
+		}
+	}
+	c.RLock()
+	rejected := c.rejectedPubs
+	c.RUnlock()
+	if rejected != 3 {
+		t.Fatalf("Expected 3 rejected publishes, got %v", rejected)
+	}
+}
+
+func TestQuotasReload(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, "client_limits: {max_subs: 1}")
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	cb := func(_ *stan.Msg) {}
+	if _, err := sc.Subscribe("foo", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if _, err := sc.Subscribe("foo", cb); err == nil {
+		t.Fatal("Expected subscription to fail")
+	}
+
+	writeReloadConfig(t, "client_limits: {max_clients: 1, max_subs: 2}")
+	changes, err := s.reload()
+	if err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	if strings.Join(changes, ",") != "client_limits" {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+	if _, err := sc.Subscribe("foo", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	expectConnectErr(t, "other", ErrTooManyClients)
+
+	// Invalid limits are rejected
+	writeReloadConfig(t, "client_limits: {max_subs: -1}")
+	if err := s.Reload(); err == nil {
+		t.Fatal("Expected reload to fail")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"time"
+)
+
+// deliveryLimiter is a token bucket limiting the rate at which new messages
+// are delivered to a subscription or a queue group. It is protected by the
+// lock of the subscription or queue group it belongs to.
+type deliveryLimiter struct {
+	rate   float64 // tokens added per second
+	burst  float64 // maximum number of tokens
+	tokens float64
+	last   int64       // time at which tokens were last added
+	timer  *time.Timer // fires when the next token is available
+}
+
+// newDeliveryLimiter returns a limiter for the given rate, in messages per
+// second, and burst, or nil if the rate is 0 (no limit).
+func newDeliveryLimiter(rate, burst uint32) *deliveryLimiter {
+	if rate == 0 {
+		return nil
+	}
+	if burst == 0 {
+		burst = 1
+	}
+	return &deliveryLimiter{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now().UnixNano(),
+	}
+}
+
+// wait returns 0 if a message can be delivered now, otherwise the duration
+// until it can.
+func (dl *deliveryLimiter) wait() time.Duration {
+	now := time.Now().UnixNano()
+	dl.tokens += float64(now-dl.last) * dl.rate / float64(time.Second)
+	if dl.tokens > dl.burst {
+		dl.tokens = dl.burst
+	}
+	dl.last = now
+	if dl.tokens >= 1 {
+		return 0
+	}
+	// Round up so that the token is available when the timer fires.
+	return time.Duration((1-dl.tokens)*float64(time.Second)/dl.rate) + time.Millisecond
+}
+
+// take accounts for a message that has been delivered.
+func (dl *deliveryLimiter) take() {
+	dl.tokens--
+}
+
+// schedule calls `f` after `d`, unless a call is already scheduled.
+// `f` is invoked without lock held and must clear the timer.
+func (dl *deliveryLimiter) schedule(d time.Duration, f func()) {
+	if dl.timer == nil {
+		dl.timer = time.AfterFunc(d, f)
+	}
+}
+
+// stop cancels the scheduled call, if any.
+func (dl *deliveryLimiter) stop() {
+	if dl.timer != nil {
+		dl.timer.Stop()
+		dl.timer = nil
+	}
+}
+
+// checkDeliveryRate returns true if a new message can be delivered to the
+// subscription now, otherwise schedules a new delivery attempt when the rate
+// allows it.
+// sub's lock held on entry.
+func (s *StanServer) checkDeliveryRate(c *channel, sub *subState) bool {
+	dl := sub.limiter
+	if dl == nil {
+		return true
+	}
+	d := dl.wait()
+	if d == 0 {
+		return true
+	}
+	dl.schedule(d, func() {
+		sub.Lock()
+		dl.timer = nil
+		removed := sub.removed
+		sub.Unlock()
+		if !removed {
+			s.sendAvailableMessages(c, sub)
+		}
+	})
+	return false
+}
+
+// checkQueueDeliveryRate is like checkDeliveryRate, for a queue group.
+// qs's lock held on entry.
+func (s *StanServer) checkQueueDeliveryRate(c *channel, qs *queueState) bool {
+	dl := qs.limiter
+	if dl == nil {
+		return true
+	}
+	d := dl.wait()
+	if d == 0 {
+		return true
+	}
+	dl.schedule(d, func() {
+		qs.Lock()
+		dl.timer = nil
+		qs.Unlock()
+		s.sendAvailableMessagesToQueue(c, qs)
+	})
+	return false
+}
@@ -0,0 +1,242 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nats-io/nats-streaming-server/audit"
+)
+
+// Errors related to configuration reload
+var (
+	ErrReloadNoConfigFile = errors.New("no configuration file to reload")
+	ErrReloadNotReady     = errors.New("server is not ready")
+)
+
+// Reload processes the configuration file the server was started with
+// (Options.ConfigFile) and applies the changes that can be made while the
+// server is running: store limits, clients permissions, credentials and
+// limits, client heartbeat settings and debug/trace. If the configuration file contains changes to
+// other options, the reload is rejected and nothing is applied.
+//
+// Options set from the command line keep precedence over the configuration
+// file. New store limits also apply to existing channels, while new
+// permissions and clients limits do not affect existing clients and
+// subscriptions.
+func (s *StanServer) Reload() error {
+	_, err := s.reload()
+	return err
+}
+
+// reload applies the changes found in the configuration file and
+// returns a description of these changes.
+func (s *StanServer) reload() ([]string, error) {
+	changes, err := s.reloadConfig()
+	if err != nil {
+		s.log.Errorf("Configuration reload failed: %v", err)
+		s.auditf(audit.EventConfigReloadFail, "", "error=%v", err)
+		return nil, err
+	}
+	if len(changes) == 0 {
+		s.log.Noticef("Configuration reloaded: no change")
+	} else {
+		s.log.Noticef("Configuration reloaded: %s", strings.Join(changes, ", "))
+	}
+	s.auditf(audit.EventConfigReload, "", "changes=%s", strings.Join(changes, ","))
+	return changes, nil
+}
+
+func (s *StanServer) reloadConfig() ([]string, error) {
+	state := State(atomic.LoadInt64(&s.healthState))
+	if atomic.LoadInt64(&s.ready) == 0 || state == Shutdown || state == Failed {
+		return nil, ErrReloadNotReady
+	}
+
+	s.optsMu.Lock()
+	defer s.optsMu.Unlock()
+
+	if s.opts.ConfigFile == "" {
+		return nil, ErrReloadNoConfigFile
+	}
+	// Start from the current options so that values that are not in the
+	// configuration file are preserved. Per-channel and per-client limits,
+	// permissions and tenants, however, are lists defined in the configuration
+	// file, so start with none.
+	newOpts := s.opts.Clone()
+	newOpts.PerChannel = nil
+	newOpts.PerClientLimits = nil
+	newOpts.Permissions = nil
+	newOpts.Tenants = nil
+	if err := ProcessConfigFile(s.opts.ConfigFile, newOpts); err != nil {
+		return nil, err
+	}
+	newOpts.applyCmdLineOptions()
+	limitsChanged := !reflect.DeepEqual(s.opts.StoreLimits, newOpts.StoreLimits)
+	if unsafe := s.opts.unsafeChanges(newOpts, limitsChanged); len(unsafe) > 0 {
+		return nil, fmt.Errorf("changes to %s require a restart", strings.Join(unsafe, ", "))
+	}
+
+	// Validate everything before applying any change.
+	var (
+		perms        *permissions
+		permsChanged = !reflect.DeepEqual(s.opts.Permissions, newOpts.Permissions)
+	)
+	if permsChanged {
+		var err error
+		if perms, err = newPermissions(newOpts.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		cliLimits        *clientLimits
+		cliLimitsChanged = newOpts.MaxClients != s.opts.MaxClients ||
+			newOpts.ClientLimits != s.opts.ClientLimits ||
+			!reflect.DeepEqual(s.opts.PerClientLimits, newOpts.PerClientLimits)
+	)
+	if cliLimitsChanged {
+		var err error
+		if cliLimits, err = newClientLimits(newOpts); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		creds        *credentials
+		credsChanged bool
+	)
+	if newOpts.CredentialsFile != "" {
+		// The content of the file may have changed, so always load it.
+		var err error
+		if creds, err = loadCredentials(newOpts.CredentialsFile); err != nil {
+			return nil, fmt.Errorf("unable to load credentials: %v", err)
+		}
+		credsChanged = !reflect.DeepEqual(creds, s.creds)
+	}
+	if limitsChanged {
+		// A failed SetLimits() may leave the store with partially
+		// applied limits, so validate them here.
+		limits := newOpts.StoreLimits.Clone()
+		if err := limits.Build(); err != nil {
+			return nil, fmt.Errorf("invalid store limits: %v", err)
+		}
+	}
+
+	var changes []string
+	if limitsChanged {
+		if err := s.store.SetLimits(&newOpts.StoreLimits); err != nil {
+			return nil, fmt.Errorf("unable to set store limits: %v", err)
+		}
+		s.opts.StoreLimits = newOpts.StoreLimits
+		changes = append(changes, "store_limits")
+		for _, l := range (&s.opts.StoreLimits).Print() {
+			s.log.Noticef(l)
+		}
+	}
+	if permsChanged {
+		s.opts.Permissions = newOpts.Permissions
+		s.perms = perms
+		changes = append(changes, "permissions")
+	}
+	if cliLimitsChanged {
+		s.opts.MaxClients = newOpts.MaxClients
+		s.opts.ClientLimits = newOpts.ClientLimits
+		s.opts.PerClientLimits = newOpts.PerClientLimits
+		s.clientLimits = cliLimits
+		s.clients.setMaxClients(s.opts.MaxClients)
+		changes = append(changes, "client_limits")
+	}
+	if credsChanged {
+		s.opts.CredentialsFile = newOpts.CredentialsFile
+		s.creds = creds
+		changes = append(changes, "credentials")
+	}
+	if newOpts.ClientHBInterval != s.opts.ClientHBInterval {
+		s.opts.ClientHBInterval = newOpts.ClientHBInterval
+		changes = append(changes, fmt.Sprintf("hb_interval=%v", s.opts.ClientHBInterval))
+	}
+	if newOpts.ClientHBTimeout != s.opts.ClientHBTimeout {
+		s.opts.ClientHBTimeout = newOpts.ClientHBTimeout
+		changes = append(changes, fmt.Sprintf("hb_timeout=%v", s.opts.ClientHBTimeout))
+	}
+	if newOpts.ClientHBFailCount != s.opts.ClientHBFailCount {
+		s.opts.ClientHBFailCount = newOpts.ClientHBFailCount
+		changes = append(changes, fmt.Sprintf("hb_fail_count=%v", s.opts.ClientHBFailCount))
+	}
+	if newOpts.Debug != s.opts.Debug || newOpts.Trace != s.opts.Trace {
+		if newOpts.Debug != s.opts.Debug {
+			changes = append(changes, fmt.Sprintf("stan_debug=%v", newOpts.Debug))
+		}
+		if newOpts.Trace != s.opts.Trace {
+			changes = append(changes, fmt.Sprintf("stan_trace=%v", newOpts.Trace))
+		}
+		s.opts.Debug, s.opts.Trace = newOpts.Debug, newOpts.Trace
+		s.reloadLogger()
+	}
+	return changes, nil
+}
+
+// reloadLogger applies the debug and trace options to the logger.
+// Options lock held on entry.
+func (s *StanServer) reloadLogger() {
+	s.setDebugAndTrace(s.opts.Debug, s.opts.Trace)
+	if s.opts.CustomLogger != nil {
+		s.log.SetLogger(s.opts.CustomLogger, s.opts.Debug, s.opts.Trace)
+	} else if s.opts.EnableLogging {
+		// The underlying logger filters debug and trace statements
+		// based on the flags it was created with, so recreate it.
+		s.configureLogger(s.natsOpts)
+		if s.natsServer != nil {
+			s.natsServer.SetLogger(s.log.GetLogger(), s.natsOpts.Debug, s.natsOpts.Trace)
+		}
+	}
+}
+
+// applyCmdLineOptions sets the options that were set from the command
+// line, which take precedence over the configuration file, to the value
+// they were given.
+func (o *Options) applyCmdLineOptions() {
+	if o.cmdLine == nil {
+		return
+	}
+	dst := reflect.ValueOf(o).Elem()
+	src := reflect.ValueOf(o.cmdLine.opts).Elem()
+	for _, field := range o.cmdLine.fields {
+		d, s := dst, src
+		for _, name := range strings.Split(field, ".") {
+			d, s = d.FieldByName(name), s.FieldByName(name)
+		}
+		d.Set(s)
+	}
+}
+
+// unsafeChanges returns the name of the options that differ between
+// `o` and `newOpts` and that cannot be changed while the server is running.
+func (o *Options) unsafeChanges(newOpts *Options, limitsChanged bool) []string {
+	var unsafe []string
+	check := func(name string, changed bool) {
+		if changed {
+			unsafe = append(unsafe, name)
+		}
+	}
+	check("cluster_id", o.ID != newOpts.ID)
+	check("discover_prefix", o.DiscoverPrefix != newOpts.DiscoverPrefix)
+	check("store", !strings.EqualFold(o.StoreType, newOpts.StoreType))
+	check("dir", o.FilestoreDir != newOpts.FilestoreDir)
+	check("file_options", o.FileStoreOpts != newOpts.FileStoreOpts)
+	check("nats_server_url", o.NATSServerURL != newOpts.NATSServerURL)
+	check("secure", o.Secure != newOpts.Secure)
+	check("tls", o.ClientCert != newOpts.ClientCert || o.ClientKey != newOpts.ClientKey || o.ClientCA != newOpts.ClientCA)
+	check("ack_subs_pool_size", o.AckSubsPoolSize != newOpts.AckSubsPoolSize)
+	check("ft_group", o.FTGroupName != newOpts.FTGroupName)
+	check("partitioning", o.Partitioning != newOpts.Partitioning)
+	check("tenants", !reflect.DeepEqual(o.Tenants, newOpts.Tenants))
+	check("audit_log", o.AuditLog != newOpts.AuditLog || o.AuditLogMaxSize != newOpts.AuditLogMaxSize)
+	// With partitioning, the list of channels is defined by the store
+	// limits and has been checked against the other servers on startup.
+	check("store_limits (partitioning)", o.Partitioning && newOpts.Partitioning && limitsChanged)
+	return unsafe
+}
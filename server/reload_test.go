@@ -0,0 +1,296 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const reloadConfFile = "reload.conf"
+
+func writeReloadConfig(t *testing.T, content string) {
+	if err := ioutil.WriteFile(reloadConfFile, []byte(content), 0660); err != nil {
+		stackFatalf(t, "Unexpected error creating conf file: %v", err)
+	}
+}
+
+func runReloadServer(t *testing.T, content string) *StanServer {
+	writeReloadConfig(t, content)
+	opts := GetDefaultOptions()
+	opts.ID = clusterName
+	if err := ProcessConfigFile(reloadConfFile, opts); err != nil {
+		t.Fatalf("Unexpected error processing config file: %v", err)
+	}
+	opts.ConfigFile = reloadConfFile
+	return runServerWithOpts(t, opts, nil)
+}
+
+func TestReloadNoConfigFile(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	if err := s.Reload(); err != ErrReloadNoConfigFile {
+		t.Fatalf("Expected error %v, got %v", ErrReloadNoConfigFile, err)
+	}
+}
+
+func TestReloadNotReady(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, "hb_interval: \"10s\"")
+	s.Shutdown()
+
+	if err := s.Reload(); err != ErrReloadNotReady {
+		t.Fatalf("Expected error %v, got %v", ErrReloadNotReady, err)
+	}
+}
+
+func TestReloadSafeChanges(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, `
+		hb_interval: "10s"
+		stan_debug: true
+		stan_trace: true
+		store_limits: {
+			max_msgs: 10
+			channels: {
+				foo: {max_msgs: 5}
+				bar: {max_msgs: 5}
+			}
+		}
+	`)
+	defer s.Shutdown()
+
+	writeReloadConfig(t, `
+		hb_interval: "1s"
+		hb_timeout: "500ms"
+		hb_fail_count: 5
+		stan_debug: false
+		stan_trace: false
+		store_limits: {
+			max_msgs: 20
+			channels: {
+				"foo.>": {max_msgs: 3}
+				baz: {max_msgs: 2}
+			}
+		}
+	`)
+	changes, err := s.reload()
+	if err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	expected := []string{"store_limits", "hb_interval=1s", "hb_timeout=500ms",
+		"hb_fail_count=5", "stan_debug=false", "stan_trace=false"}
+	if strings.Join(changes, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected changes %v, got %v", expected, changes)
+	}
+	if hbi, hbt, hbf := s.getClientHBOpts(); hbi != time.Second || hbt != 500*time.Millisecond || hbf != 5 {
+		t.Fatalf("Unexpected heartbeat options: %v %v %v", hbi, hbt, hbf)
+	}
+	if s.isDebug() || s.isTrace() {
+		t.Fatal("Debug and trace should be disabled")
+	}
+
+	checkMaxMsgs := func(name string, expected int) {
+		c := channelsLookupOrCreate(t, s, name)
+		for i := 0; i < 30; i++ {
+			if _, err := c.store.Msgs.Store([]byte("hello")); err != nil {
+				stackFatalf(t, "Error storing message: %v", err)
+			}
+		}
+		if n, _ := msgStoreState(t, c.store.Msgs); n != expected {
+			stackFatalf(t, "Expected %v messages in channel %q, got %v", expected, name, n)
+		}
+	}
+	// "foo" and "bar" limits were removed from the configuration.
+	checkMaxMsgs("foo", 20)
+	checkMaxMsgs("bar", 20)
+	checkMaxMsgs("baz", 2)
+	checkMaxMsgs("foo.bar", 3)
+
+	// Reloading the same configuration does not change anything.
+	changes, err = s.reload()
+	if err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no change, got %v", changes)
+	}
+}
+
+func TestReloadRejectsUnsafeChanges(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, "hb_interval: \"10s\"")
+	defer s.Shutdown()
+
+	writeReloadConfig(t, `
+		hb_interval: "1s"
+		cluster_id: "other"
+		ack_subs_pool_size: 2
+	`)
+	err := s.Reload()
+	if err == nil || !strings.Contains(err.Error(), "cluster_id, ack_subs_pool_size") {
+		t.Fatalf("Expected error about unsafe changes, got %v", err)
+	}
+	// Nothing should have been applied.
+	if hbi, _, _ := s.getClientHBOpts(); hbi != 10*time.Second {
+		t.Fatalf("Heartbeat interval should not have changed, got %v", hbi)
+	}
+
+	// Invalid configuration files are rejected too.
+	writeReloadConfig(t, "hb_interval: 10")
+	if err := s.Reload(); err == nil {
+		t.Fatal("Expected error on reload of invalid configuration")
+	}
+}
+
+func TestReloadRejectsLimitsChangeWithPartitioning(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, `
+		partitioning: true
+		store_limits: {channels: {foo: {}}}
+	`)
+	defer s.Shutdown()
+
+	writeReloadConfig(t, `
+		partitioning: true
+		store_limits: {channels: {foo: {}, bar: {}}}
+	`)
+	err := s.Reload()
+	if err == nil || !strings.Contains(err.Error(), "store_limits") {
+		t.Fatalf("Expected error about store limits, got %v", err)
+	}
+}
+
+func TestReloadLimitsOfExistingChannels(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	s := runReloadServer(t, `
+		store_limits: {
+			max_msgs: 10
+			channels: {
+				"foo.*": {max_msgs: 5}
+			}
+		}
+	`)
+	defer s.Shutdown()
+
+	storeMsgs := func(c *channel, count int) {
+		for i := 0; i < count; i++ {
+			if _, err := c.store.Msgs.Store([]byte("hello")); err != nil {
+				stackFatalf(t, "Error storing message: %v", err)
+			}
+		}
+	}
+	checkMsgs := func(c *channel, expected int) {
+		if n, _ := msgStoreState(t, c.store.Msgs); n != expected {
+			stackFatalf(t, "Expected %v messages, got %v", expected, n)
+		}
+	}
+	foobar := channelsLookupOrCreate(t, s, "foo.bar")
+	baz := channelsLookupOrCreate(t, s, "baz")
+	storeMsgs(foobar, 5)
+	storeMsgs(baz, 10)
+
+	// The global limits apply to "baz", and the wildcard limits to
+	// "foo.bar". Messages that exceed the new limits are removed.
+	writeReloadConfig(t, `
+		store_limits: {
+			max_msgs: 20
+			channels: {
+				"foo.*": {max_msgs: 3}
+			}
+		}
+	`)
+	changes, err := s.reload()
+	if err != nil || len(changes) != 1 || changes[0] != "store_limits" {
+		t.Fatalf("Unexpected result: changes=%v err=%v", changes, err)
+	}
+	checkMsgs(foobar, 3)
+	if first, _ := msgStoreFirstAndLastSequence(t, foobar.store.Msgs); first != 3 {
+		t.Fatalf("Expected first sequence to be 3, got %v", first)
+	}
+	storeMsgs(foobar, 5)
+	checkMsgs(foobar, 3)
+	storeMsgs(baz, 15)
+	checkMsgs(baz, 20)
+
+	// Per-channel limits of an existing channel.
+	writeReloadConfig(t, `
+		store_limits: {
+			max_msgs: 20
+			channels: {
+				"foo.*": {max_msgs: 3}
+				baz: {max_msgs: 2}
+			}
+		}
+	`)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	checkMsgs(baz, 2)
+	storeMsgs(baz, 5)
+	checkMsgs(baz, 2)
+	checkMsgs(foobar, 3)
+}
+
+func TestReloadKeepsCmdLineOptions(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	writeReloadConfig(t, `
+		cluster_id: "other"
+		stan_debug: false
+		hb_interval: "10s"
+		store_limits: {max_msgs: 5}
+	`)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	noPrint := func() {}
+	args := []string{"-sc", reloadConfFile, "-cid", clusterName, "-SD", "-mm", "10", "-hbf", "5"}
+	opts, _, err := ConfigureOptions(fs, args, noPrint, noPrint, noPrint)
+	if err != nil {
+		t.Fatalf("Error on configure: %v", err)
+	}
+	// The usage of the streaming flags must be the path of a field of
+	// Options so that the value can be re-applied on reload.
+	fs.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Usage, "stan.") {
+			return
+		}
+		v := reflect.ValueOf(opts).Elem()
+		for _, name := range strings.Split(strings.TrimPrefix(f.Usage, "stan."), ".") {
+			if v = v.FieldByName(name); !v.IsValid() {
+				t.Fatalf("Flag %q has invalid usage %q", f.Name, f.Usage)
+			}
+		}
+	})
+	opts.EnableLogging = false
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	// The cluster ID and debug set from the command line are not
+	// considered changed by the configuration file.
+	writeReloadConfig(t, `
+		cluster_id: "other"
+		stan_debug: false
+		hb_interval: "1s"
+		hb_fail_count: 2
+		store_limits: {max_msgs: 5}
+	`)
+	changes, err := s.reload()
+	if err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	if len(changes) != 1 || changes[0] != "hb_interval=1s" {
+		t.Fatalf("Expected only the heartbeat interval to change, got %v", changes)
+	}
+	s.optsMu.RLock()
+	id, debug, maxMsgs, hbf := s.opts.ID, s.opts.Debug, s.opts.MaxMsgs, s.opts.ClientHBFailCount
+	s.optsMu.RUnlock()
+	if id != clusterName || !debug || maxMsgs != 10 || hbf != 5 {
+		t.Fatalf("Unexpected options after reload: id=%v debug=%v max_msgs=%v hb_fail_count=%v",
+			id, debug, maxMsgs, hbf)
+	}
+}
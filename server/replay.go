@@ -0,0 +1,151 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"time"
+
+	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+// isPastStop returns true if the message `m` with sequence `seq`, or
+// no message if `m` is nil, is past the stop position of the subscription.
+// Without a message, the stop time is past if it is in the past, since any
+// message stored from now on will have a later timestamp.
+// sub's lock held on entry.
+func (sub *subState) isPastStop(seq uint64, m *pb.MsgProto) bool {
+	if sub.StopSequence > 0 && seq > sub.StopSequence {
+		return true
+	}
+	if sub.StopTime != 0 {
+		if m != nil {
+			return m.Timestamp > sub.StopTime
+		}
+		return time.Now().UnixNano() > sub.StopTime
+	}
+	return false
+}
+
+// Clear the stopTimer.
+// sub Lock held in entry.
+func (sub *subState) clearStopTimer() {
+	if sub.stopTimer != nil {
+		sub.stopTimer.Stop()
+		sub.stopTimer = nil
+	}
+}
+
+// setupStopTimer sets a timer that checks the stop position of the
+// subscription when its stop time is reached, since there may not be any
+// message published after that time to trigger the check.
+// sub's lock held on entry.
+func (s *StanServer) setupStopTimer(c *channel, sub *subState) {
+	sub.stopTimer = time.AfterFunc(time.Duration(sub.StopTime-time.Now().UnixNano()), func() {
+		sub.Lock()
+		sub.stopTimer = nil
+		removed := sub.removed
+		sub.Unlock()
+		if !removed {
+			s.sendAvailableMessages(c, sub)
+		}
+	})
+}
+
+// checkReplayEnd closes the subscription, in a separate go routine, if it
+// has reached its stop position and all delivered messages are acknowledged.
+// sub's lock held on entry.
+func (s *StanServer) checkReplayEnd(c *channel, sub *subState) {
+	if !sub.stopReached || sub.replayEnded || !sub.initialized || sub.removed || len(sub.acksPending) > 0 {
+		return
+	}
+	sub.replayEnded = true
+	s.startGoRoutine(func() {
+		defer s.wg.Done()
+		s.endReplay(c, sub)
+	})
+}
+
+// endReplay notifies the client that the subscription has reached its stop
+// position, if the client supports it, and closes the subscription.
+func (s *StanServer) endReplay(c *channel, sub *subState) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	sub.RLock()
+	removed := sub.removed
+	clientID, inbox, subid := sub.ClientID, sub.Inbox, sub.ID
+	sub.RUnlock()
+	if removed {
+		return
+	}
+	client := s.clients.lookup(clientID)
+	if client == nil {
+		return
+	}
+	if client.hasCapability(spb.Capability_CapReplayEnd) {
+		// Marshal of a spb.MsgProto cannot fail
+		b, _ := (&spb.MsgProto{Subject: c.name, ReplayEnd: true}).Marshal()
+		if err := s.ncs.Publish(inbox, b); err != nil {
+			s.log.Errorf("[Client:%s] Failed sending end of replay to subid=%d, subject=%s, err=%v",
+				clientID, subid, c.name, err)
+		}
+	}
+	if !s.clients.removeSub(clientID, sub) {
+		return
+	}
+	// Durable subscriptions are closed, not removed.
+	c.ss.Remove(c, sub, false)
+	s.monMu.Lock()
+	s.numSubs--
+	s.monMu.Unlock()
+	if s.isDebug() {
+		s.log.Debugf("[Client:%s] Closed subid=%d, subject=%s after reaching its stop position",
+			clientID, subid, c.name)
+	}
+}
+
+// Clear the replayTimer.
+// sub Lock held in entry.
+func (sub *subState) clearReplayTimer() {
+	if sub.replayTimer != nil {
+		sub.replayTimer.Stop()
+		sub.replayTimer = nil
+	}
+}
+
+// replayDueTime returns the time at which the message `m` is due to be
+// delivered to a subscription replaying messages at their original publish
+// rate. This is the time the previous message was due, plus the time between
+// the publication of both messages divided by the replay speed. If `m` is not
+// due yet, a timer is set to deliver it and 0 is returned.
+// sub's lock held on entry.
+func (s *StanServer) replayDueTime(c *channel, sub *subState, m *pb.MsgProto) int64 {
+	now := time.Now().UnixNano()
+	if sub.replayMsgTime == 0 {
+		return now
+	}
+	// After a stall, the time between messages applies from the
+	// time delivery resumes.
+	if sub.replayStall {
+		sub.replayStall = false
+		sub.replayTime = now
+	}
+	gap := float64(m.Timestamp-sub.replayMsgTime) * 100 / float64(sub.ReplaySpeedPercent)
+	due := sub.replayTime + int64(gap)
+	if due <= now {
+		return due
+	}
+	if sub.replayTimer == nil {
+		sub.replayTimer = time.AfterFunc(time.Duration(due-now), func() {
+			sub.Lock()
+			sub.replayTimer = nil
+			removed := sub.removed
+			sub.Unlock()
+			if !removed {
+				s.sendAvailableMessages(c, sub)
+			}
+		})
+	}
+	return 0
+}
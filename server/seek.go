@@ -0,0 +1,272 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/nats-streaming-server/audit"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+// Prefix of the subject administrative requests are received on. It is
+// followed by the cluster ID and the name of the operation.
+const adminPrefix = "_STAN.admin"
+
+// Seekz describes the new position of a durable after a seek request
+type Seekz struct {
+	ClusterID   string    `json:"cluster_id"`
+	ServerID    string    `json:"server_id"`
+	Now         time.Time `json:"now"`
+	Channel     string    `json:"channel"`
+	DurableName string    `json:"durable_name"`
+	ClientID    string    `json:"client_id,omitempty"`
+	QGroup      string    `json:"queue_name,omitempty"`
+	Sequence    uint64    `json:"sequence"`
+}
+
+// adminSeekSubject returns the subject seek requests are received on.
+func adminSeekSubject(clusterID string) string {
+	return fmt.Sprintf("%s.%s.seek", adminPrefix, clusterID)
+}
+
+// processSeekRequest processes a seek request received from NATS.
+func (s *StanServer) processSeekRequest(m *nats.Msg) {
+	req := &spb.SeekRequest{}
+	if err := req.Unmarshal(m.Data); err != nil {
+		s.log.Errorf("Invalid seek request from %s: %v", m.Subject, err)
+		s.sendSeekResponse(m.Reply, 0, ErrInvalidSeekReq)
+		return
+	}
+	// With partitioning, ignore requests for channels handled by other
+	// servers, so that only the server handling the channel replies.
+	if s.partitions != nil {
+		if r := s.partitions.sl.Match(req.Channel); len(r) == 0 {
+			return
+		}
+	}
+	if err := s.checkAdminToken(req.AdminToken); err != nil {
+		s.log.Errorf("Seek request on channel %s rejected: %v", req.Channel, err)
+		s.sendSeekResponse(m.Reply, 0, err)
+		return
+	}
+	seq, err := s.seekDurable(req)
+	s.sendSeekResponse(m.Reply, seq, err)
+}
+
+func (s *StanServer) sendSeekResponse(reply string, seq uint64, err error) {
+	if reply == "" {
+		return
+	}
+	resp := &spb.SeekResponse{Sequence: seq}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	b, _ := resp.Marshal()
+	s.nc.Publish(reply, b)
+}
+
+// handleSeekz moves the position of a durable. The durable is identified
+// with `channel=<name>&durable=<name>` and either `client_id=<id>` or, for a
+// durable queue group, `queue=<name>`. The position is given with `seq=<n>`,
+// `time=<RFC3339 time>` or `ago=<duration>`, and pending messages are
+// cleared with `clear_pending=1`. Unlike the other monitoring endpoints,
+// the request must carry the admin token in an Authorization header.
+func (s *StanServer) handleSeekz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "seek requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.checkAdminToken(bearerToken(r)); err != nil {
+		s.log.Errorf("Seek request from %s rejected: %v", r.RemoteAddr, err)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	req := &spb.SeekRequest{
+		Channel:     query.Get("channel"),
+		DurableName: query.Get("durable"),
+		ClientID:    query.Get("client_id"),
+		QGroup:      query.Get("queue"),
+	}
+	req.ClearPending, _ = strconv.ParseBool(query.Get("clear_pending"))
+	var err error
+	if v := query.Get("seq"); v != "" {
+		req.Sequence, err = strconv.ParseUint(v, 10, 64)
+	} else if v := query.Get("time"); v != "" {
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, v); err == nil {
+			req.Time = t.UnixNano()
+		}
+	} else if v := query.Get("ago"); v != "" {
+		var d time.Duration
+		if d, err = time.ParseDuration(v); err == nil {
+			req.Time = time.Now().Add(-d).UnixNano()
+		}
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %v", err), http.StatusBadRequest)
+		return
+	}
+	seq, err := s.seekDurable(req)
+	switch err {
+	case nil:
+	case ErrUnknownDurable:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seekz := &Seekz{
+		ClusterID:   s.info.ClusterID,
+		ServerID:    s.serverID,
+		Now:         time.Now(),
+		Channel:     req.Channel,
+		DurableName: req.DurableName,
+		ClientID:    req.ClientID,
+		QGroup:      req.QGroup,
+		Sequence:    seq,
+	}
+	s.sendResponse(w, r, seekz)
+}
+
+// seekDurable moves the position of the durable subscription, or durable
+// queue group, described by `req`, whether it is online or offline, so that
+// the next message delivered is the one with the requested sequence or the
+// first one stored at or after the requested time. Returns the sequence of
+// this next message.
+func (s *StanServer) seekDurable(req *spb.SeekRequest) (uint64, error) {
+	if req.Channel == "" || req.DurableName == "" || (req.ClientID == "" && req.QGroup == "") ||
+		(req.Sequence == 0 && req.Time == 0) {
+		return 0, ErrInvalidSeekReq
+	}
+	c := s.channels.get(req.Channel)
+	if c == nil {
+		return 0, ErrUnknownDurable
+	}
+	next, err := s.seekSequence(c, req)
+	if err != nil {
+		return 0, err
+	}
+	lastSent := next - 1
+
+	ss := c.ss
+	ss.RLock()
+	defer ss.RUnlock()
+	if req.QGroup != "" {
+		qs := ss.qsubs[fmt.Sprintf("%s:%s", req.DurableName, req.QGroup)]
+		if qs == nil {
+			return 0, ErrUnknownDurable
+		}
+		qs.Lock()
+		members := qs.subs
+		if qs.shadow != nil {
+			members = append([]*subState{qs.shadow}, members...)
+		}
+		for _, sub := range members {
+			sub.Lock()
+			wasStalled := sub.stalled
+			err = s.seekSub(sub, lastSent, req.ClearPending, sub.ClientID)
+			if wasStalled && !sub.stalled && qs.stalledSubCount > 0 {
+				qs.stalledSubCount--
+			}
+			sub.Unlock()
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			qs.lastSent = lastSent
+		}
+		online := len(qs.subs) > 0
+		qs.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		if online {
+			s.sendAvailableMessagesToQueue(c, qs)
+		}
+	} else {
+		sub := ss.durables[fmt.Sprintf("%s-%s-%s", req.ClientID, req.Channel, req.DurableName)]
+		if sub == nil {
+			return 0, ErrUnknownDurable
+		}
+		sub.Lock()
+		// The ClientID of an offline durable is cleared in memory, but
+		// is required in the store to recover the durable.
+		err = s.seekSub(sub, lastSent, req.ClearPending, req.ClientID)
+		online := !sub.isOfflineDurableSubscriber()
+		sub.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		if online {
+			s.sendAvailableMessages(c, sub)
+		}
+	}
+	s.log.Noticef("Moved durable %s to seq=%d (clear_pending=%v)",
+		durableDetails(req.Channel, req.DurableName, req.QGroup), next, req.ClearPending)
+	s.auditf(audit.EventDurableSeek, req.ClientID, "%s seq=%d clear_pending=%v",
+		durableDetails(req.Channel, req.DurableName, req.QGroup), next, req.ClearPending)
+	return next, nil
+}
+
+// seekSequence returns the sequence of the next message to deliver after a
+// seek request, which is bounded by the first available message and the
+// sequence of the next message to be stored.
+func (s *StanServer) seekSequence(c *channel, req *spb.SeekRequest) (uint64, error) {
+	firstSeq, lastSeq, err := c.store.Msgs.FirstAndLastSequence()
+	if err != nil {
+		return 0, err
+	}
+	next := req.Sequence
+	if next == 0 {
+		// If there is no message at or after this time, seq will be
+		// last sequence + 1, or 0 if there is no message at all.
+		if next, err = c.store.Msgs.GetSequenceFromTimestamp(req.Time); err != nil {
+			return 0, err
+		}
+	}
+	if next < firstSeq {
+		next = firstSeq
+	}
+	if next > lastSeq+1 || next == 0 {
+		next = lastSeq + 1
+	}
+	return next, nil
+}
+
+// seekSub sets the LastSent of the subscription, clears its pending messages
+// if requested, and persists the change with `clientID` as ClientID.
+// sub's lock held on entry.
+func (s *StanServer) seekSub(sub *subState, lastSent uint64, clearPending bool, clientID string) error {
+	if clearPending {
+		for seq := range sub.acksPending {
+			if err := sub.store.AckSeqPending(sub.ID, seq); err != nil {
+				return err
+			}
+			delete(sub.acksPending, seq)
+			sub.clearPendingSize(seq)
+		}
+		sub.clearAckTimer()
+		sub.stalled = false
+		sub.newOnHold = false
+	}
+	sub.LastSent = lastSent
+	// Messages up to the stop position, if any, can be delivered again,
+	// and replay at the original rate starts over.
+	sub.stopReached = false
+	sub.replayMsgTime = 0
+
+	memClientID := sub.ClientID
+	sub.ClientID = clientID
+	err := sub.store.UpdateSub(&sub.SubState)
+	sub.ClientID = memClientID
+	return err
+}
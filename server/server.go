@@ -21,6 +21,7 @@ import (
 	"github.com/nats-io/gnatsd/server"
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/audit"
 	"github.com/nats-io/nats-streaming-server/logger"
 	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
@@ -47,6 +48,9 @@ const (
 	// The prefixes should not have been made public (since we do not expose ways
 	// to change them). Add the new ones as private.
 	acksSubsPoolPrefix = "_STAN.subacks"
+	pubBatchPrefix     = "_STAN.pubbatch"
+	pingPrefix         = "_STAN.ping"
+	subPausePrefix     = "_STAN.subpause"
 
 	// Prefix of subject active server is sending HBs to
 	ftHBPrefix = "_STAN.ft"
@@ -65,6 +69,9 @@ const (
 	// Timeout used to ping the known client when processing a connection
 	// request for a duplicate client ID.
 	defaultCheckDupCIDTimeout = 500 * time.Millisecond
+	// Number of ping intervals, as observed between the last two pings of
+	// a client, without a ping after which the client's health is checked.
+	missedPingsCount = 3
 
 	// DefaultIOBatchSize is the maximum number of messages to accumulate before flushing a store.
 	DefaultIOBatchSize = 1024
@@ -73,6 +80,12 @@ const (
 	// before starting processing. Set to 0 (or negative) to disable the wait.
 	DefaultIOSleepTime = int64(0)
 
+	// MaxPubBatchMsgs is the maximum number of messages of a batch publish request.
+	MaxPubBatchMsgs = 1000
+	// MaxPubBatchBytes is the maximum total size of the payloads of the messages
+	// of a batch publish request.
+	MaxPubBatchBytes = 512 * 1024
+
 	// Length of the channel used to schedule subscriptions start requests.
 	// Subscriptions requests are processed from the same NATS subscription.
 	// When a subscriber starts and it has pending messages, the server
@@ -92,6 +105,13 @@ const (
 	natsInboxLen = 29 // _INBOX.<nuid: 22 characters>
 )
 
+// Highest client protocol version and capabilities supported by this server.
+const (
+	protocolVersion    = spb.Protocol_ProtocolOne
+	serverCapabilities = spb.Capability_CapPubAckSequence | spb.Capability_CapPubBatch | spb.Capability_CapPing |
+		spb.Capability_CapReplayEnd | spb.Capability_CapSubPause
+)
+
 // Constant to indicate that sendMsgToSub() should check number of acks pending
 // against MaxInFlight to know if message should be sent out.
 const (
@@ -101,23 +121,43 @@ const (
 
 // Errors.
 var (
-	ErrInvalidSubject     = errors.New("stan: invalid subject")
-	ErrInvalidStart       = errors.New("stan: invalid start position")
-	ErrInvalidSub         = errors.New("stan: invalid subscription")
-	ErrInvalidClient      = errors.New("stan: clientID already registered")
-	ErrMissingClient      = errors.New("stan: clientID missing")
-	ErrInvalidClientID    = errors.New("stan: invalid clientID: only alphanumeric and `-` or `_` characters allowed")
-	ErrInvalidAckWait     = errors.New("stan: invalid ack wait time, should be >= 1s")
-	ErrInvalidMaxInflight = errors.New("stan: invalid MaxInflight, should be >= 1")
-	ErrInvalidConnReq     = errors.New("stan: invalid connection request")
-	ErrInvalidPubReq      = errors.New("stan: invalid publish request")
-	ErrInvalidSubReq      = errors.New("stan: invalid subscription request")
-	ErrInvalidUnsubReq    = errors.New("stan: invalid unsubscribe request")
-	ErrInvalidCloseReq    = errors.New("stan: invalid close request")
-	ErrDupDurable         = errors.New("stan: duplicate durable registration")
-	ErrInvalidDurName     = errors.New("stan: durable name of a durable queue subscriber can't contain the character ':'")
-	ErrUnknownClient      = errors.New("stan: unknown clientID")
-	ErrNoChannel          = errors.New("stan: no configured channel")
+	ErrInvalidSubject          = errors.New("stan: invalid subject")
+	ErrInvalidStart            = errors.New("stan: invalid start position")
+	ErrInvalidSub              = errors.New("stan: invalid subscription")
+	ErrInvalidClient           = errors.New("stan: clientID already registered")
+	ErrMissingClient           = errors.New("stan: clientID missing")
+	ErrInvalidClientID         = errors.New("stan: invalid clientID: only alphanumeric and `-` or `_` characters allowed")
+	ErrInvalidAckWait          = errors.New("stan: invalid ack wait time, should be >= 1s")
+	ErrInvalidMaxInflight      = errors.New("stan: invalid MaxInflight, should be >= 1")
+	ErrInvalidMaxInflightBytes = errors.New("stan: invalid MaxInflightBytes, should be >= 0")
+	ErrInvalidDeliveryRate     = errors.New("stan: invalid delivery rate, burst requires a rate")
+	ErrQueueDeliveryRate       = errors.New("stan: delivery rate differs from the one of the queue group")
+	ErrInvalidConnReq          = errors.New("stan: invalid connection request")
+	ErrInvalidPubReq           = errors.New("stan: invalid publish request")
+	ErrPubBatchTooBig          = errors.New("stan: batch publish request exceeds the maximum number of messages or bytes")
+	ErrInvalidSubReq           = errors.New("stan: invalid subscription request")
+	ErrInvalidUnsubReq         = errors.New("stan: invalid unsubscribe request")
+	ErrInvalidCloseReq         = errors.New("stan: invalid close request")
+	ErrDupDurable              = errors.New("stan: duplicate durable registration")
+	ErrInvalidDurName          = errors.New("stan: durable name of a durable queue subscriber can't contain the character ':'")
+	ErrUnknownClient           = errors.New("stan: unknown clientID")
+	ErrNoChannel               = errors.New("stan: no configured channel")
+	ErrPubNotAuthorized        = errors.New("stan: not authorized to publish to this channel")
+	ErrSubNotAuthorized        = errors.New("stan: not authorized to subscribe to this channel")
+	ErrInvalidCredentials      = errors.New("stan: invalid credentials")
+	ErrTooManyClients          = errors.New("stan: too many clients")
+	ErrTooManyClientSubs       = errors.New("stan: too many subscriptions for this client")
+	ErrPubRateExceeded         = errors.New("stan: publish rate limit exceeded for this client")
+	ErrInvalidPingReq          = errors.New("stan: invalid ping request")
+	ErrClientReplaced          = errors.New("stan: client has been replaced by a new connection")
+	ErrInvalidWildcardSub      = errors.New("stan: wildcard subscriptions can't be queue subscriptions and are not supported with partitioning")
+	ErrInvalidStop             = errors.New("stan: stop position not supported for queue or wildcard subscriptions")
+	ErrInvalidReplay           = errors.New("stan: replay at original rate not supported for queue subscriptions")
+	ErrInvalidSeekReq          = errors.New("stan: invalid seek request")
+	ErrAdminNotAuthorized      = errors.New("stan: administrative request requires a valid admin token")
+	ErrInvalidPauseReq         = errors.New("stan: invalid pause request")
+	ErrUnknownDurable          = errors.New("stan: unknown durable subscription")
+	ErrInvalidExclusive        = errors.New("stan: exclusive subscriptions must be durable subscriptions on a single channel, not queue subscriptions")
 )
 
 // Shared regular expression to check clientID validity.
@@ -150,8 +190,23 @@ func init() {
 // producer.
 type ioPendingMsg struct {
 	m  *nats.Msg
-	pm pb.PubMsg
-	pa pb.PubAck
+	pm spb.PubMsg
+	pa spb.PubAck
+	// Set if the publisher supports the sequence of the stored message
+	// in the ack.
+	ackSeq bool
+	// Set if this is a batch publish request, in which case pm and pa
+	// are not used.
+	batch *ioPendingBatch
+}
+
+// ioPendingBatch holds the messages of a batch publish request and
+// their acks, which have the same index in their respective slices.
+// An ack with an error set at the time the batch is queued corresponds
+// to a message that is not stored.
+type ioPendingBatch struct {
+	req spb.PubBatch
+	ack spb.PubBatchAck
 }
 
 // Constant that defines the size of the channel that feeds the IO thread.
@@ -293,6 +348,7 @@ type channel struct {
 	name  string
 	store *stores.Channel
 	ss    *subStore
+	stats channelStats
 }
 
 // StanServer structure represents the STAN server
@@ -302,6 +358,11 @@ type StanServer struct {
 	// atomic.* functions crash on 32bit machines if operand is not aligned
 	// at 64bit. See https://github.com/golang/go/issues/599
 	ioChannelStatsMaxBatchSize int64 // stats of the max number of messages than went into a single batch
+	// The health endpoint uses those instead of `state` since the server
+	// lock is held for the whole duration of the recovery.
+	healthState int64 // mirrors `state`
+	ready       int64 // set to 1 once recovery and post recovery processing are complete
+	quotas      quotaStats
 
 	mu         sync.RWMutex
 	shutdown   bool
@@ -311,6 +372,21 @@ type StanServer struct {
 	opts       *Options
 	startTime  time.Time
 
+	// Protects the options that can be changed on configuration reload.
+	optsMu sync.RWMutex
+	// NATS options, used to reconfigure the logger on configuration reload.
+	natsOpts *server.Options
+	// Clients permissions, protected by optsMu. Nil if everything is allowed.
+	perms *permissions
+	// Clients credentials, protected by optsMu. Nil if clients are not authenticated.
+	creds *credentials
+	// Clients limits, protected by optsMu. Nil if clients are unlimited.
+	clientLimits *clientLimits
+
+	// Servers of the additional cluster IDs hosted by this server.
+	tenantsMu sync.RWMutex
+	tenants   map[string]*StanServer
+
 	// For scalability, a dedicated connection is used to publish
 	// messages to subscribers.
 	nc  *nats.Conn // used for most protocol messages
@@ -318,6 +394,17 @@ type StanServer struct {
 
 	wg sync.WaitGroup // Wait on go routines during shutdown
 
+	// Last error returned by the store when storing messages, cleared
+	// once messages are successfully stored again.
+	storeErrMu sync.Mutex
+	storeErr   error
+
+	// Records the timeline of messages marked for tracing.
+	msgTracer *msgTracer
+
+	// Audit log, nil if not enabled.
+	auditLog *audit.Log
+
 	// Used when processing connect requests for client ID already registered
 	dupCIDGuard       sync.RWMutex
 	dupCIDMap         map[string]struct{}
@@ -332,6 +419,9 @@ type StanServer struct {
 	// channels
 	channels *channelStore
 
+	// Subscriptions on wildcard subjects
+	wildcards *wildcardStore
+
 	// Store
 	store stores.Store
 
@@ -393,8 +483,10 @@ type StanServer struct {
 	// may be memory allocations to format the string passed to these
 	// calls. So in those situations, use these flags to surround the
 	// calls to Debugf/Tracef.
-	trace bool
-	debug bool
+	// They are accessed atomically since they can be changed on
+	// configuration reload.
+	trace int32
+	debug int32
 	log   *logger.StanLogger
 }
 
@@ -404,6 +496,7 @@ type subStore struct {
 	psubs    []*subState            // plain subscribers
 	qsubs    map[string]*queueState // queue subscribers
 	durables map[string]*subState   // durables lookup
+	standbys map[string][]*subState // standbys of exclusive durables, by durable key
 	acks     map[string]*subState   // ack inbox lookup
 	stan     *StanServer            // back link to Stan server
 }
@@ -417,6 +510,15 @@ type queueState struct {
 	shadow          *subState // For durable case, when last member leaves and group is not closed.
 	stalledSubCount int       // number of stalled members
 	newOnHold       bool
+	// Delivery rate of the group, set when the group is created, and
+	// limiter enforcing it, if set.
+	rate    uint32
+	burst   uint32
+	limiter *deliveryLimiter
+	// Set when the deliveries to the group are paused. The state is also
+	// kept in the SubState of each member, to survive a restart.
+	paused            bool
+	pauseRedeliveries bool
 }
 
 // When doing message redelivery due to ack expiration, the function
@@ -435,9 +537,27 @@ type subState struct {
 	qstate       *queueState
 	ackWait      time.Duration // SubState.AckWaitInSecs expressed as a time.Duration
 	ackTimer     *time.Timer
+	stopTimer    *time.Timer      // Fires at the stop time, if any, in case no message is published after it.
+	replayTimer  *time.Timer      // Fires when the next message is due, when replaying at the original rate.
+	idleTimer    *time.Timer      // Fires when an offline durable, or the shadow of a durable queue group, expires.
+	limiter      *deliveryLimiter // Limits the delivery rate of a plain subscription, if set.
 	ackSub       *nats.Subscription
 	acksPending  map[uint64]int64 // key is message sequence, value is expiration time.
+	pendingSizes map[uint64]int   // key is message sequence, value is payload size, for messages in acksPending.
+	pendingBytes int64            // sum of the payload sizes in pendingSizes.
 	store        stores.SubStore  // for easy access to the store interface
+	chStats      *channelStats    // for easy access to the channel's stats
+
+	// When replaying at the original publish rate, timestamp of the last
+	// message delivered (0 if the next message is due right away) and
+	// time at which it was due.
+	replayMsgTime int64
+	replayTime    int64
+
+	// Statistics reported by the monitoring endpoints.
+	redeliveries    uint64 // number of messages redelivered
+	acksCount       uint64 // number of acks with a known delivery time
+	ackLatencyTotal int64  // sum of the time between delivery and ack for those acks
 
 	// So far, compacting these booleans into a byte flag would not save space.
 	// May change if we need to add more.
@@ -446,6 +566,10 @@ type subState struct {
 	newOnHold   bool // Prevents delivery of new msgs until old are redelivered (on restart)
 	hasFailedHB bool // This is set when server sends heartbeat to this subscriber's client.
 	removed     bool // This is true when subStore.Remove() has been invoked for this subscription.
+	stopReached bool // This is true when no more message will be delivered due to the stop position.
+	replayEnded bool // This is true once the subscription has been scheduled to be closed after reaching its stop position.
+	replayStall bool // This is true if the subscription stalled while replaying at the original rate.
+	expired     bool // This is true once the offline durable has been removed due to inactivity.
 }
 
 // Looks up, or create a new channel if it does not exist
@@ -454,7 +578,14 @@ func (s *StanServer) lookupOrCreateChannel(name string) (*channel, error) {
 	if c != nil {
 		return c, nil
 	}
-	return s.channels.createChannel(s, name)
+	c, err := s.channels.createChannel(s, name)
+	if err != nil {
+		return nil, err
+	}
+	// The wildcard subscriptions matching this channel start receiving
+	// its messages.
+	s.addWildcardMembers(c)
+	return c, nil
 }
 
 // createSubStore creates a new instance of `subStore`.
@@ -463,6 +594,7 @@ func (s *StanServer) createSubStore() *subStore {
 		psubs:    make([]*subState, 0, 4),
 		qsubs:    make(map[string]*queueState),
 		durables: make(map[string]*subState),
+		standbys: make(map[string][]*subState),
 		acks:     make(map[string]*subState),
 		stan:     s,
 	}
@@ -500,8 +632,14 @@ func (ss *subStore) updateState(sub *subState) {
 		// Queue subscriber.
 		qs := ss.qsubs[sub.QGroup]
 		if qs == nil {
+			// The delivery rate of the group is the one of the member
+			// that creates it (or of the recovered shadow), and that all
+			// members, which persist it, share.
 			qs = &queueState{
-				subs: make([]*subState, 0, 4),
+				subs:    make([]*subState, 0, 4),
+				rate:    sub.DeliveryRate,
+				burst:   sub.DeliveryBurst,
+				limiter: newDeliveryLimiter(sub.DeliveryRate, sub.DeliveryBurst),
 			}
 			ss.qsubs[sub.QGroup] = qs
 		}
@@ -540,6 +678,10 @@ func (ss *subStore) updateState(sub *subState) {
 		if sub.stalled {
 			qs.stalledSubCount++
 		}
+		// Update paused (on recovery)
+		if sub.Paused {
+			qs.paused, qs.pauseRedeliveries = true, sub.PauseRedeliveries
+		}
 		qs.Unlock()
 		sub.qstate = qs
 	} else {
@@ -548,9 +690,11 @@ func (ss *subStore) updateState(sub *subState) {
 
 		// Plain subscriber.
 		ss.psubs = append(ss.psubs, sub)
+		sub.limiter = newDeliveryLimiter(sub.DeliveryRate, sub.DeliveryBurst)
 
-		// Hold onto durables in special lookup.
-		if sub.isDurableSubscriber() {
+		// Hold onto durables in special lookup. Members of wildcard
+		// subscriptions are looked up through their wildcard subscription.
+		if sub.isDurableSubscriber() && sub.WildcardID == 0 {
 			ss.durables[sub.durableKey()] = sub
 		}
 	}
@@ -562,12 +706,21 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 	if sub == nil {
 		return
 	}
+	// Standbys of exclusive durables are not part of the delivery state.
+	if ss.removeStandby(sub) {
+		return
+	}
 
 	sub.Lock()
 	subject := sub.subject
 	clientID := sub.ClientID
 	sub.removed = true
 	sub.clearAckTimer()
+	sub.clearStopTimer()
+	sub.clearReplayTimer()
+	if sub.limiter != nil {
+		sub.limiter.stop()
+	}
 	durableKey := ""
 	// Do this before clearing the sub.ClientID since this is part of the key!!!
 	if sub.isDurableSubscriber() {
@@ -585,8 +738,15 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 	subid := sub.ID
 	store := sub.store
 	qgroup := sub.QGroup
+	exclusive := sub.Exclusive && durableKey != ""
 	sub.Unlock()
 
+	// The state of an exclusive durable is kept for its standbys, which
+	// take over even if the active instance unsubscribes.
+	if exclusive && unsubscribe && ss.hasStandbys(durableKey) {
+		unsubscribe = false
+	}
+
 	reportError := func(err error) {
 		ss.stan.log.Errorf("Error deleting subscription subid=%d, subject=%s, err=%v", subid, subject, err)
 	}
@@ -607,14 +767,14 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 	)
 
 	ss.Lock()
-	if ss.stan.debug {
+	if ss.stan.isDebug() {
 		log = ss.stan.log
 	}
 	// Delete from ackInbox lookup.
 	delete(ss.acks, ackInbox)
 
 	// Delete from durable if needed
-	if unsubscribe && durableKey != "" {
+	if unsubscribe && durableKey != "" && ss.durables[durableKey] == sub {
 		delete(ss.durables, durableKey)
 	}
 
@@ -630,6 +790,9 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 		qs.subs, _ = sub.deleteFromList(qs.subs)
 		if len(qs.subs) == 0 {
 			queueGroupIsEmpty = true
+			if qs.limiter != nil {
+				qs.limiter.stop()
+			}
 			// If it was the last being removed, also remove the
 			// queue group from the subStore map, but only if
 			// non durable or explicit unsubscribe.
@@ -705,6 +868,7 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 				}
 				// Store in ackPending.
 				qsub.acksPending[m.Sequence] = expirationTime
+				qsub.setPendingSize(m.Sequence, len(m.Data))
 				// Keep track of this qsub
 				if qsubs == nil {
 					qsubs = make(map[uint64]*subState)
@@ -738,6 +902,11 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 			}
 			qsub.Lock()
 			qsub.LastSent = qs.lastSent
+			// The group is offline from now on.
+			if qsub == qs.shadow {
+				qsub.OfflineTime = time.Now().UnixNano()
+				ss.stan.setupIdleTimer(c, qsub)
+			}
 			qsub.store.UpdateSub(&qsub.SubState)
 			qsub.Unlock()
 		}
@@ -756,6 +925,12 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 			// "compute" the durable key (clientID+subject+durable name).
 			sub.ClientID = clientID
 			sub.IsClosed = true
+			// Members of wildcard subscriptions are resumed, or removed,
+			// with their wildcard subscription.
+			if sub.WildcardID == 0 {
+				sub.OfflineTime = time.Now().UnixNano()
+				ss.stan.setupIdleTimer(c, sub)
+			}
 			store.UpdateSub(&sub.SubState)
 			// After storage, clear the ClientID.
 			sub.ClientID = ""
@@ -775,6 +950,10 @@ func (ss *subStore) Remove(c *channel, sub *subState, unsubscribe bool) {
 		traceCtx := subStateTraceCtx{clientID: clientID, isRemove: true, isUnsubscribe: unsubscribe, isGroupEmpty: queueGroupIsEmpty}
 		traceSubState(log, sub, &traceCtx)
 	}
+
+	if exclusive && !unsubscribe {
+		ss.stan.promoteStandby(c, sub)
+	}
 }
 
 // Lookup by durable name.
@@ -809,25 +988,41 @@ type Options struct {
 	StoreType          string
 	FilestoreDir       string
 	FileStoreOpts      stores.FileStoreOptions
-	stores.StoreLimits               // Store limits (MaxChannels, etc..)
-	EnableLogging      bool          // Enables logging
-	CustomLogger       logger.Logger // Server will start with the provided logger
-	Trace              bool          // Verbose trace
-	Debug              bool          // Debug trace
-	HandleSignals      bool          // Should the server setup a signal handler (for Ctrl+C, etc...)
-	Secure             bool          // Create a TLS enabled connection w/o server verification
-	ClientCert         string        // Client Certificate for TLS
-	ClientKey          string        // Client Key for TLS
-	ClientCA           string        // Client CAs for TLS
-	IOBatchSize        int           // Maximum number of messages collected from clients before starting their processing.
-	IOSleepTime        int64         // Duration (in micro-seconds) the server waits for more message to fill up a batch.
-	NATSServerURL      string        // URL for external NATS Server to connect to. If empty, NATS Server is embedded.
-	ClientHBInterval   time.Duration // Interval at which server sends heartbeat to a client.
-	ClientHBTimeout    time.Duration // How long server waits for a heartbeat response.
-	ClientHBFailCount  int           // Number of failed heartbeats before server closes client connection.
-	AckSubsPoolSize    int           // Number of internal subscriptions handling incoming ACKs (0 means one per client's subscription).
-	FTGroupName        string        // Name of the FT Group. A group can be 2 or more servers with a single active server and all sharing the same datastore.
-	Partitioning       bool          // Specify if server only accepts messages/subscriptions on channels defined in StoreLimits.
+	stores.StoreLimits                          // Store limits (MaxChannels, etc..)
+	EnableLogging      bool                     // Enables logging
+	CustomLogger       logger.Logger            // Server will start with the provided logger
+	Trace              bool                     // Verbose trace
+	Debug              bool                     // Debug trace
+	HandleSignals      bool                     // Should the server setup a signal handler (for Ctrl+C, etc...)
+	Secure             bool                     // Create a TLS enabled connection w/o server verification
+	ClientCert         string                   // Client Certificate for TLS
+	ClientKey          string                   // Client Key for TLS
+	ClientCA           string                   // Client CAs for TLS
+	IOBatchSize        int                      // Maximum number of messages collected from clients before starting their processing.
+	IOSleepTime        int64                    // Duration (in micro-seconds) the server waits for more message to fill up a batch.
+	NATSServerURL      string                   // URL for external NATS Server to connect to. If empty, NATS Server is embedded.
+	ClientHBInterval   time.Duration            // Interval at which server sends heartbeat to a client.
+	ClientHBTimeout    time.Duration            // How long server waits for a heartbeat response.
+	ClientHBFailCount  int                      // Number of failed heartbeats before server closes client connection.
+	AckSubsPoolSize    int                      // Number of internal subscriptions handling incoming ACKs (0 means one per client's subscription).
+	FTGroupName        string                   // Name of the FT Group. A group can be 2 or more servers with a single active server and all sharing the same datastore.
+	Partitioning       bool                     // Specify if server only accepts messages/subscriptions on channels defined in StoreLimits.
+	ConfigFile         string                   // Configuration file, re-processed on configuration reload.
+	Permissions        []*ClientPermissions     // Channels that clients are allowed to publish to and subscribe to. Everything is allowed if empty.
+	CredentialsFile    string                   // File containing the clients credentials. If set, clients must present a valid token to connect.
+	Tenants            []*Options               // Additional cluster IDs hosted by this server, sharing its NATS Server.
+	AuditLog           string                   // File of the audit log of administrative and security-relevant actions. Disabled if empty.
+	AuditLogMaxSize    int64                    // Size at which the audit log file is rotated (0 means no rotation).
+	MaxClients         int                      // Maximum number of clients (0 means unlimited).
+	ClientLimits       ClientLimits             // Quotas of each client.
+	PerClientLimits    map[string]*ClientLimits // Quotas of the clients whose ID matches the key (a client ID, or a prefix followed by `*`).
+	cmdLine            *cmdLineOptions          // Options set from the command line, re-applied on configuration reload.
+}
+
+// cmdLineOptions holds the value of the options set from the command line.
+type cmdLineOptions struct {
+	opts   *Options
+	fields []string // Name, or path for nested structures, of the fields of Options.
 }
 
 // Clone returns a deep copy of the Options object.
@@ -837,6 +1032,26 @@ func (o *Options) Clone() *Options {
 	// But we have the problem of the PerChannel map that needs
 	// to be copied.
 	clone.PerChannel = (&o.StoreLimits).ClonePerChannelMap()
+	if o.Permissions != nil {
+		clone.Permissions = make([]*ClientPermissions, 0, len(o.Permissions))
+		for _, cp := range o.Permissions {
+			cpc := *cp
+			clone.Permissions = append(clone.Permissions, &cpc)
+		}
+	}
+	if o.PerClientLimits != nil {
+		clone.PerClientLimits = make(map[string]*ClientLimits, len(o.PerClientLimits))
+		for cid, cl := range o.PerClientLimits {
+			clc := *cl
+			clone.PerClientLimits[cid] = &clc
+		}
+	}
+	if o.Tenants != nil {
+		clone.Tenants = make([]*Options, 0, len(o.Tenants))
+		for _, t := range o.Tenants {
+			clone.Tenants = append(clone.Tenants, t.Clone())
+		}
+	}
 	return &clone
 }
 
@@ -1045,20 +1260,22 @@ func RunServerWithOpts(stanOpts *Options, natsOpts *server.Options) (newServer *
 	s := StanServer{
 		serverID:          nuid.Next(),
 		opts:              sOpts,
+		natsOpts:          nOpts,
 		dupCIDMap:         make(map[string]struct{}),
 		dupMaxCIDRoutines: defaultMaxDupCIDRoutines,
 		dupCIDTimeout:     defaultCheckDupCIDTimeout,
 		ioChannelQuit:     make(chan struct{}, 1),
 		ctrlMsgRefIDs:     make(map[string]int),
-		trace:             sOpts.Trace,
-		debug:             sOpts.Debug,
 		subStartCh:        make(chan *subStartInfo, defaultSubStartChanLen),
 		subStartQuit:      make(chan struct{}, 1),
 		acksSubsPoolSize:  sOpts.AckSubsPoolSize,
 		startTime:         time.Now(),
 		log:               logger.NewStanLogger(),
+		msgTracer:         newMsgTracer(),
 	}
 
+	s.setDebugAndTrace(sOpts.Debug, sOpts.Trace)
+
 	// If a custom logger is provided, use this one, otherwise, check
 	// if we should configure the logger or not.
 	if sOpts.CustomLogger != nil {
@@ -1111,6 +1328,31 @@ func RunServerWithOpts(stanOpts *Options, natsOpts *server.Options) (newServer *
 		store stores.Store
 	)
 
+	if err := validateTenants(sOpts); err != nil {
+		return nil, err
+	}
+	if s.perms, err = newPermissions(sOpts.Permissions); err != nil {
+		return nil, err
+	}
+	if s.clientLimits, err = newClientLimits(sOpts); err != nil {
+		return nil, err
+	}
+	if sOpts.CredentialsFile != "" {
+		if s.creds, err = loadCredentials(sOpts.CredentialsFile); err != nil {
+			return nil, fmt.Errorf("unable to load credentials: %v", err)
+		}
+	}
+	if sOpts.AuditLog != "" {
+		s.auditLog = audit.New(sOpts.AuditLog, sOpts.AuditLogMaxSize)
+		// In FT mode, the audit log is opened once the server is active
+		// since the standby servers share the same file.
+		if sOpts.FTGroupName == "" {
+			if err := s.auditLog.Open(); err != nil {
+				return nil, fmt.Errorf("unable to open audit log: %v", err)
+			}
+		}
+	}
+
 	// Ensure store type option is in upper-case
 	sOpts.StoreType = strings.ToUpper(sOpts.StoreType)
 
@@ -1138,7 +1380,9 @@ func RunServerWithOpts(stanOpts *Options, natsOpts *server.Options) (newServer *
 	s.store = store
 
 	s.clients = newClientStore(s.store)
+	s.clients.setMaxClients(sOpts.MaxClients)
 	s.channels = newChannelStore(s.store)
+	s.wildcards = newWildcardStore()
 
 	// If no NATS server url is provided, it means that we embed the NATS Server
 	if sOpts.NATSServerURL == "" {
@@ -1181,6 +1425,9 @@ func RunServerWithOpts(stanOpts *Options, natsOpts *server.Options) (newServer *
 		if err := s.start(Standalone); err != nil {
 			return nil, err
 		}
+		if err := s.startTenants(nOpts); err != nil {
+			return nil, err
+		}
 	}
 	if s.opts.HandleSignals {
 		s.handleSignals()
@@ -1278,10 +1525,29 @@ func (s *StanServer) start(runningState State) error {
 			s.info.AcksSubs = fmt.Sprintf("%s.%s", acksSubsPoolPrefix, subjID)
 			callStoreInit = true
 		}
+		// Same for PubBatch (batch publish requests)
+		if s.info.PubBatch == "" {
+			s.info.PubBatch = fmt.Sprintf("%s.%s", pubBatchPrefix, subjID)
+			callStoreInit = true
+		}
+		// Same for Ping (client pings)
+		if s.info.Ping == "" {
+			s.info.Ping = fmt.Sprintf("%s.%s", pingPrefix, subjID)
+			callStoreInit = true
+		}
+		// Same for SubPause (subscription pause requests)
+		if s.info.SubPause == "" {
+			s.info.SubPause = fmt.Sprintf("%s.%s", subPausePrefix, subjID)
+			callStoreInit = true
+		}
 
 		// Restore clients state
 		s.processRecoveredClients(recoveredState.Clients)
 
+		// Restore the wildcard subscriptions, whose members are
+		// recovered with the channels.
+		s.processRecoveredWildcardSubs(recoveredState.WildcardSubs)
+
 		// Process recovered channels (if any).
 		recoveredSubs = s.processRecoveredChannels(recoveredState.Channels)
 	} else {
@@ -1294,6 +1560,9 @@ func (s *StanServer) start(runningState State) error {
 		s.info.Unsubscribe = fmt.Sprintf("%s.%s", DefaultUnSubPrefix, subjID)
 		s.info.Close = fmt.Sprintf("%s.%s", DefaultClosePrefix, subjID)
 		s.info.AcksSubs = fmt.Sprintf("%s.%s", acksSubsPoolPrefix, subjID)
+		s.info.PubBatch = fmt.Sprintf("%s.%s", pubBatchPrefix, subjID)
+		s.info.Ping = fmt.Sprintf("%s.%s", pingPrefix, subjID)
+		s.info.SubPause = fmt.Sprintf("%s.%s", subPausePrefix, subjID)
 
 		callStoreInit = true
 	}
@@ -1329,6 +1598,9 @@ func (s *StanServer) start(runningState State) error {
 		if err := s.postRecoveryProcessing(recoveredState.Clients, recoveredSubs); err != nil {
 			return fmt.Errorf("error during post recovery processing: %v", err)
 		}
+		// Offline durables are removed once they have been offline for
+		// longer than their channel's limit.
+		s.setupRecoveredIdleTimers()
 	}
 
 	// Flush to make sure all subscriptions are processed before
@@ -1347,7 +1619,8 @@ func (s *StanServer) start(runningState State) error {
 	// and release newOnHold
 	s.wg.Add(1)
 	go s.performRedeliveryOnStartup(recoveredSubs)
-	s.state = runningState
+	s.setState(runningState)
+	atomic.StoreInt64(&s.ready, 1)
 	return nil
 }
 
@@ -1480,7 +1753,7 @@ func (s *StanServer) ensureRunningStandAlone() error {
 	// We cannot use the client's API here as it will create a dependency
 	// cycle in the streaming client, so build our request and see if we
 	// get a response.
-	req := &pb.ConnectRequest{ClientID: clusterID, HeartbeatInbox: hbInbox}
+	req := &spb.ConnectRequest{ClientID: clusterID, HeartbeatInbox: hbInbox}
 	b, _ := req.Marshal()
 	reply, err := s.nc.Request(s.info.Discovery, b, timeout)
 	if err == nats.ErrTimeout {
@@ -1491,7 +1764,7 @@ func (s *StanServer) ensureRunningStandAlone() error {
 		return fmt.Errorf("request error detecting another server instance: %v", err)
 	}
 	// See if the response is valid and can be unmarshalled.
-	cr := &pb.ConnectResponse{}
+	cr := &spb.ConnectResponse{}
 	err = cr.Unmarshal(reply.Data)
 	if err != nil {
 		// Something other than a compatible streaming server responded.
@@ -1500,7 +1773,7 @@ func (s *StanServer) ensureRunningStandAlone() error {
 		return fmt.Errorf("unmarshall error while detecting another server instance: %v", err)
 	}
 	// Another streaming server was found, cleanup then return error.
-	clreq := &pb.CloseRequest{ClientID: clusterID}
+	clreq := &spb.CloseRequest{ClientID: clusterID}
 	b, _ = clreq.Marshal()
 	s.nc.Request(cr.CloseRequests, b, timeout)
 	return fmt.Errorf("discovered another streaming server with cluster ID %q", clusterID)
@@ -1537,6 +1810,7 @@ func (s *StanServer) processRecoveredChannels(channels map[string]*stores.Recove
 				subject: channel.name,
 				ackWait: computeAckWait(recSub.Sub.AckWaitInSecs),
 				store:   channel.store.Subs,
+				chStats: &channel.stats,
 			}
 			sub.acksPending = make(map[uint64]int64, len(recSub.Pending))
 			for seq := range recSub.Pending {
@@ -1553,6 +1827,24 @@ func (s *StanServer) processRecoveredChannels(channels map[string]*stores.Recove
 			}
 			// Copy over fields from SubState protobuf
 			sub.SubState = *recSub.Sub
+			// Standbys of exclusive durables wait for the active instance
+			// to leave.
+			if sub.Standby {
+				if s.recoverStandby(channel, sub) {
+					s.monMu.Lock()
+					s.numSubs++
+					s.monMu.Unlock()
+				}
+				continue
+			}
+			// Members of wildcard subscriptions are attached to their
+			// wildcard subscription.
+			if sub.WildcardID != 0 {
+				if s.recoverWildcardMember(channel, sub) {
+					allSubs = append(allSubs, sub)
+				}
+				continue
+			}
 			// When recovering older stores, IsDurable may not exist for
 			// durable subscribers. Set it now.
 			durableSub := sub.isDurableSubscriber() // not a durable queue sub!
@@ -1610,7 +1902,8 @@ func (s *StanServer) postRecoveryProcessing(recoveredClients []*stores.Client, r
 		sub.Lock()
 		// To be on the safe side, just check that the ackSub has not
 		// been created (may happen with durables that may reconnect maybe?)
-		if sub.ackSub == nil {
+		// Members of wildcard subscriptions don't have their own ackSub.
+		if sub.ackSub == nil && sub.WildcardID == 0 {
 			if len(sub.AckInbox) <= natsInboxPrefixLen {
 				err = fmt.Errorf("invalid ack inbox: %s", sub.AckInbox)
 			} else {
@@ -1669,10 +1962,11 @@ func (s *StanServer) postRecoveryProcessing(recoveredClients []*stores.Client, r
 		sub.Unlock()
 	}
 	// Go through the list of clients and ensure their Hb timer is set.
+	_, hbTimeout, _ := s.getClientHBOpts()
 	for _, sc := range recoveredClients {
 		// Because of the loop, we need to make copy for the closure
 		cID := sc.ID
-		s.clients.setClientHB(cID, s.opts.ClientHBTimeout, func() {
+		s.clients.setClientHB(cID, hbTimeout, func() {
 			s.checkClientHealth(cID)
 		})
 	}
@@ -1753,6 +2047,14 @@ func (s *StanServer) initSubscriptions() error {
 			return fmt.Errorf("could not subscribe to publish subject, %v", err)
 		}
 		pubSub.SetPendingLimits(-1, -1)
+		// Receive batch publish requests from clients. Not supported with
+		// partitioning since a batch may contain messages for channels
+		// handled by other servers.
+		batchSub, err := s.nc.Subscribe(s.info.PubBatch, s.processPubBatchRequest)
+		if err != nil {
+			return fmt.Errorf("could not subscribe to batch publish subject, %v", err)
+		}
+		batchSub.SetPendingLimits(-1, -1)
 	}
 	// Receive subscription requests from clients.
 	_, err = s.nc.Subscribe(s.info.Subscribe, s.processSubscriptionRequest)
@@ -1774,10 +2076,37 @@ func (s *StanServer) initSubscriptions() error {
 	if err != nil {
 		return fmt.Errorf("could not subscribe to close request subject, %v", err)
 	}
+	// Receive pings from clients.
+	_, err = s.nc.Subscribe(s.info.Ping, s.processPingRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to ping subject, %v", err)
+	}
+	// Receive subscription pause requests from clients.
+	_, err = s.nc.Subscribe(s.info.SubPause, s.processSubPauseRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to subscription pause request subject, %v", err)
+	}
+	// Receive administrative seek requests.
+	_, err = s.nc.Subscribe(adminSeekSubject(s.info.ClusterID), s.processSeekRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to seek request subject, %v", err)
+	}
+	// Receive administrative pause requests.
+	_, err = s.nc.Subscribe(adminPauseSubject(s.info.ClusterID), s.processAdminPauseRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to pause request subject, %v", err)
+	}
 	// We need to set this regardless if server is currently running
 	// with the pool or not (since we may need those when recovering subscriptions)
 	s.acksSubsPrefix = s.info.AcksSubs + "."
 	s.acksSubsPrefixLen = len(s.acksSubsPrefix)
+	// Receive ACKs of the wildcard subscriptions, whose members share the
+	// AckInbox of their wildcard subscription.
+	wcAckSub, err := s.nc.Subscribe(s.acksSubsPrefix+wildcardAckInboxPrefix+">", s.processAckMsg)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to wildcard subscriptions acks subject: %v", err)
+	}
+	wcAckSub.SetPendingLimits(-1, -1)
 	// Optionally receive ACKs from clients using this pool of ack subscribers.
 	if s.acksSubsPoolSize > 0 {
 		for i := 0; i < s.acksSubsPoolSize; i++ {
@@ -1797,17 +2126,22 @@ func (s *StanServer) initSubscriptions() error {
 		s.log.Debugf("Publish subjects root:      %s", s.info.Publish)
 	} else {
 		s.log.Debugf("Publish subject:            %s.>", s.info.Publish)
+		s.log.Debugf("Batch publish subject:      %s", s.info.PubBatch)
 	}
 	s.log.Debugf("Subscribe subject:          %s", s.info.Subscribe)
 	s.log.Debugf("Subscription Close subject: %s", s.info.SubClose)
 	s.log.Debugf("Unsubscribe subject:        %s", s.info.Unsubscribe)
 	s.log.Debugf("Close subject:              %s", s.info.Close)
+	s.log.Debugf("Ping subject:               %s", s.info.Ping)
+	s.log.Debugf("Subscription Pause subject: %s", s.info.SubPause)
+	s.log.Debugf("Seek subject:               %s", adminSeekSubject(s.info.ClusterID))
+	s.log.Debugf("Pause subject:              %s", adminPauseSubject(s.info.ClusterID))
 	return nil
 }
 
 // Process a client connect request
 func (s *StanServer) connectCB(m *nats.Msg) {
-	req := &pb.ConnectRequest{}
+	req := &spb.ConnectRequest{}
 	err := req.Unmarshal(m.Data)
 	if err != nil || req.HeartbeatInbox == "" {
 		s.log.Errorf("[Client:?] Invalid conn request: ClientID=%s, Inbox=%s, err=%v",
@@ -1821,15 +2155,53 @@ func (s *StanServer) connectCB(m *nats.Msg) {
 		return
 	}
 
-	// Try to register
-	client, isNew, err := s.clients.register(req.ClientID, req.HeartbeatInbox)
+	var identity *clientIdentity
+	if creds := s.getCredentials(); creds != nil && creds.authClients() {
+		if identity, err = creds.authenticate(req.ClientID, req.AuthToken, req.ConnID); err != nil {
+			s.log.Errorf("[Client:%s] Connect failed; invalid credentials", req.ClientID)
+			s.sendConnectErr(m.Reply, err.Error())
+			return
+		}
+		// The connection ID identifies the requests of the client.
+		if req.ConnID == "" {
+			s.log.Errorf("[Client:%s] Invalid conn request: missing connection ID", req.ClientID)
+			s.sendConnectErr(m.Reply, ErrInvalidConnReq.Error())
+			return
+		}
+	}
+
+	info, err := newClientInfo(req, s.capabilities())
 	if err != nil {
+		s.log.Errorf("[Client:%s] Invalid conn request: %v", req.ClientID, err)
+		s.sendConnectErr(m.Reply, ErrInvalidConnReq.Error())
+		return
+	}
+
+	// Try to register
+	client, isNew, err := s.clients.register(info)
+	if err == ErrTooManyClients {
+		atomic.AddUint64(&s.quotas.rejectedClients, 1)
+		s.log.Errorf("[Client:%s] Connect failed; maximum number of clients reached", req.ClientID)
+		s.sendConnectErr(m.Reply, err.Error())
+		return
+	} else if err != nil {
 		s.log.Errorf("[Client:%s] Error registering client: %v", req.ClientID, err)
 		s.sendConnectErr(m.Reply, err.Error())
 		return
 	}
 	// Handle duplicate IDs in a dedicated go-routine
 	if !isNew {
+		// A client ID bound to an identity can only be taken over by
+		// a client authenticated with the same credential.
+		client.RLock()
+		boundIdentity := client.identity
+		client.RUnlock()
+		if boundIdentity != nil && !boundIdentity.sameCredential(identity) {
+			s.log.Errorf("[Client:%s] Connect failed; credentials do not match the connected client", req.ClientID)
+			s.sendConnectErr(m.Reply, ErrInvalidCredentials.Error())
+			return
+		}
+
 		// Do we have a routine in progress for this client ID?
 		s.dupCIDGuard.RLock()
 		_, inProgress := s.dupCIDMap[req.ClientID]
@@ -1878,35 +2250,63 @@ func (s *StanServer) connectCB(m *nats.Msg) {
 		}
 		// Start a go-routine to handle this connect request
 		go func() {
-			s.processConnectRequestWithDupID(client, req, m.Reply)
+			s.processConnectRequestWithDupID(client, req, info, identity, m.Reply)
 		}()
 		return
 	}
 
 	// Here, we accept this client's incoming connect request.
+	client.Lock()
+	client.identity = identity
+	client.Unlock()
 	s.finishConnectRequest(client, req, m.Reply)
 }
 
-func (s *StanServer) finishConnectRequest(client *client, req *pb.ConnectRequest, replyInbox string) {
-	cr := &pb.ConnectResponse{
+func (s *StanServer) finishConnectRequest(client *client, req *spb.ConnectRequest, replyInbox string) {
+	cr := &spb.ConnectResponse{
 		PubPrefix:        s.info.Publish,
 		SubRequests:      s.info.Subscribe,
 		UnsubRequests:    s.info.Unsubscribe,
 		SubCloseRequests: s.info.SubClose,
 		CloseRequests:    s.info.Close,
 	}
+	client.RLock()
+	cr.Protocol = spb.Protocol(client.info.Protocol)
+	cr.Capabilities = client.info.Capabilities
+	client.RUnlock()
+	// Only send the subjects of the features the client supports.
+	if client.hasCapability(spb.Capability_CapPubBatch) {
+		cr.PubBatchRequests = s.info.PubBatch
+	}
+	if client.hasCapability(spb.Capability_CapPing) {
+		cr.PingRequests = s.info.Ping
+	}
+	if client.hasCapability(spb.Capability_CapSubPause) {
+		cr.SubPauseRequests = s.info.SubPause
+	}
 	b, _ := cr.Marshal()
 	s.nc.Publish(replyInbox, b)
 
 	clientID := req.ClientID
 	hbInbox := req.HeartbeatInbox
 	// Heartbeat timer.
-	s.clients.setClientHB(clientID, s.opts.ClientHBInterval, func() { s.checkClientHealth(clientID) })
+	hbInterval, _, _ := s.getClientHBOpts()
+	s.clients.setClientHB(clientID, hbInterval, func() { s.checkClientHealth(clientID) })
 
 	s.log.Debugf("[Client:%s] Connected (Inbox=%v)", clientID, hbInbox)
+	if s.auditLog != nil {
+		client.RLock()
+		identity := client.identity
+		client.RUnlock()
+		if identity != nil {
+			s.auditf(audit.EventClientConnect, clientID, "hb_inbox=%s identity=%s", hbInbox, identity.method)
+		} else {
+			s.auditf(audit.EventClientConnect, clientID, "hb_inbox=%s", hbInbox)
+		}
+	}
 }
 
-func (s *StanServer) processConnectRequestWithDupID(c *client, req *pb.ConnectRequest, replyInbox string) {
+func (s *StanServer) processConnectRequestWithDupID(c *client, req *spb.ConnectRequest, info *spb.ClientInfo, identity *clientIdentity, replyInbox string) {
 	sendErr := true
 
 	c.RLock()
@@ -1939,10 +2339,14 @@ func (s *StanServer) processConnectRequestWithDupID(c *client, req *pb.ConnectRe
 
 		// Need to re-register now based on the new request info.
 		var isNew bool
-		c, isNew, err = s.clients.register(req.ClientID, req.HeartbeatInbox)
+		c, isNew, err = s.clients.register(info)
 		if err == nil && isNew {
 			// We could register the new client.
+			c.Lock()
+			c.identity = identity
+			c.Unlock()
 			s.log.Debugf("[Client:%s] Replaced old client (Inbox=%v)", req.ClientID, hbInbox)
+			s.auditf(audit.EventClientReplaced, req.ClientID, "old_hb_inbox=%s", hbInbox)
 			sendErr = false
 		}
 	}
@@ -1957,8 +2361,51 @@ func (s *StanServer) processConnectRequestWithDupID(c *client, req *pb.ConnectRe
 	s.finishConnectRequest(c, req, replyInbox)
 }
 
+// capabilities returns the capabilities that this server can negotiate
+// with clients. Batch publish requests are not supported with partitioning.
+func (s *StanServer) capabilities() uint32 {
+	caps := uint32(serverCapabilities)
+	if s.partitions != nil {
+		caps &^= uint32(spb.Capability_CapPubBatch)
+	}
+	return caps
+}
+
+// newClientInfo returns the information stored for the client sending
+// the connect request `req`, including its optional metadata, and the
+// protocol version and capabilities negotiated with the client among
+// the server's capabilities `caps`.
+func newClientInfo(req *spb.ConnectRequest, caps uint32) (*spb.ClientInfo, error) {
+	info := &spb.ClientInfo{
+		ID:          req.ClientID,
+		HbInbox:     req.HeartbeatInbox,
+		ConnectTime: time.Now().UnixNano(),
+	}
+	// Clients using the initial protocol do not send capabilities.
+	if req.Protocol >= spb.Protocol_ProtocolOne {
+		info.Protocol = int32(req.Protocol)
+		if req.Protocol > protocolVersion {
+			info.Protocol = int32(protocolVersion)
+		}
+		info.Capabilities = req.Capabilities & caps
+	}
+	if md := req.Metadata; md != nil {
+		for _, label := range md.Labels {
+			if strings.IndexByte(label, '=') <= 0 {
+				return nil, fmt.Errorf("invalid label %q, expected key=value", label)
+			}
+		}
+		info.Name = md.Name
+		info.Version = md.Version
+		info.Hostname = md.Hostname
+		info.Language = md.Language
+		info.Labels = md.Labels
+	}
+	return info, nil
+}
+
 func (s *StanServer) sendConnectErr(replyInbox, err string) {
-	cr := &pb.ConnectResponse{Error: err}
+	cr := &spb.ConnectResponse{Error: err}
 	b, _ := cr.Marshal()
 	s.nc.Publish(replyInbox, b)
 }
@@ -1978,7 +2425,8 @@ func (s *StanServer) checkClientHealth(clientID string) {
 	hasFailedHB := false
 	// Sends the HB request. This call blocks for ClientHBTimeout,
 	// do not hold the lock for that long!
-	_, err := s.nc.Request(hbInbox, nil, s.opts.ClientHBTimeout)
+	hbInterval, hbTimeout, hbFailCount := s.getClientHBOpts()
+	_, err := s.nc.Request(hbInbox, nil, hbTimeout)
 	// Grab the lock now.
 	client.Lock()
 	// Client could have been unregistered, in which case
@@ -1991,24 +2439,29 @@ func (s *StanServer) checkClientHealth(clientID string) {
 	// failed heartbeats.
 	if err != nil {
 		client.fhb++
-		// If we have reached the max number of failures
-		if client.fhb > s.opts.ClientHBFailCount {
+		// If we have reached the max number of failures, or if the
+		// client has also stopped sending pings.
+		if client.fhb > hbFailCount || client.pingsMissed {
 			s.log.Debugf("[Client:%s] Timed out on heartbeats", clientID)
 			// close the client (connection). This locks the
 			// client object internally so unlock here.
 			client.Unlock()
-			s.closeClient(clientID)
+			if s.closeClient(clientID) {
+				s.auditf(audit.EventClientClose, clientID, "reason=heartbeat_timeout")
+			}
 			return
 		}
 	} else {
 		// We got the reply, reset the number of failed heartbeats.
 		client.fhb = 0
+		client.pingsMissed = false
+		client.lastHB = time.Now()
 	}
 	// Get a copy of subscribers and client.fhb while under lock
 	subs = client.getSubsCopy()
 	hasFailedHB = client.fhb > 0
 	// Reset the timer to fire again.
-	client.hbt.Reset(s.opts.ClientHBInterval)
+	client.hbt.Reset(hbInterval)
 	client.Unlock()
 	if len(subs) > 0 {
 		// Push the info about presence of failed heartbeats down to
@@ -2041,8 +2494,10 @@ func (s *StanServer) closeClient(clientID string) bool {
 
 	// Remove all non-durable subscribers.
 	s.removeAllNonDurableSubscribers(client)
+	// And update the wildcard subscriptions those belonged to.
+	s.closeClientWildcardSubs(clientID)
 
-	if s.debug {
+	if s.isDebug() {
 		client.RLock()
 		hbInbox := client.info.HbInbox
 		client.RUnlock()
@@ -2063,13 +2518,17 @@ func (s *StanServer) createCtrlMsg(reqType spb.CtrlMsg_Type, useRefID bool, repl
 
 // processCloseRequest will process connection close requests from clients.
 func (s *StanServer) processCloseRequest(m *nats.Msg) {
-	req := &pb.CloseRequest{}
+	req := &spb.CloseRequest{}
 	err := req.Unmarshal(m.Data)
 	if err != nil {
 		s.log.Errorf("Received invalid close request, subject=%s", m.Subject)
 		s.sendCloseErr(m.Reply, ErrInvalidCloseReq.Error())
 		return
 	}
+	if err := s.checkClientIdentity(req.ClientID, req.ConnID); err != nil {
+		s.sendCloseErr(m.Reply, err.Error())
+		return
+	}
 
 	// Create the control and corresponding NATS message
 	ctrlMsg, ctrlNatsMsg := s.createCtrlMsg(spb.CtrlMsg_ConnClose, true, m.Reply, []byte(req.ClientID))
@@ -2096,7 +2555,8 @@ func (s *StanServer) processCloseRequest(m *nats.Msg) {
 		// close request to subscriber's ackInbox subscribers.
 		for _, sub := range subs {
 			sub.Lock()
-			if !sub.removed {
+			// Standbys of exclusive durables don't receive acks.
+			if !sub.removed && !sub.Standby {
 				// Don't use sub.AckInbox directly since it may
 				// need to be prefixed with s.acksSubsPrefix
 				ctrlNatsMsg.Subject = s.getAckSubject(sub)
@@ -2130,6 +2590,7 @@ func (s *StanServer) performConnClose(m *nats.Msg, clientID string) {
 		s.sendCloseErr(m.Reply, ErrUnknownClient.Error())
 		return
 	}
+	s.auditf(audit.EventClientClose, clientID, "reason=close_request")
 
 	resp := &pb.CloseResponse{}
 	b, _ := resp.Marshal()
@@ -2143,6 +2604,71 @@ func (s *StanServer) sendCloseErr(subj, err string) {
 	}
 }
 
+// processPingRequest replies to a client's ping. The response carries an
+// error if the client is no longer registered (for instance because it has
+// been closed after missed heartbeats) or if its connection has been
+// replaced by a new one with the same client ID, so that the client knows
+// that it has to reconnect.
+func (s *StanServer) processPingRequest(m *nats.Msg) {
+	if m.Reply == "" {
+		return
+	}
+	req := &spb.Ping{}
+	var err error
+	if req.Unmarshal(m.Data) != nil || req.ClientID == "" {
+		err = ErrInvalidPingReq
+	} else if client := s.clients.lookup(req.ClientID); client == nil {
+		err = ErrUnknownClient
+	} else {
+		authRequired := s.authRequired()
+		client.Lock()
+		if req.HeartbeatInbox != client.info.HbInbox {
+			err = ErrClientReplaced
+		} else if !client.identityMatches(req.ConnID, authRequired) {
+			err = ErrInvalidCredentials
+		} else {
+			s.trackClientPings(client)
+		}
+		client.Unlock()
+	}
+	resp := &spb.PingResponse{}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	b, _ := resp.Marshal()
+	s.nc.Publish(m.Reply, b)
+}
+
+// trackClientPings records the ping just received from `client` and arms
+// a timer that checks the client's health if no other ping is received
+// within missedPingsCount intervals, as observed between the last two pings.
+// When it fires, the heartbeat is sent right away and the client is closed
+// at the first failed heartbeat, instead of after the configured number
+// of failures.
+// client's lock held on entry.
+func (s *StanServer) trackClientPings(client *client) {
+	now := time.Now()
+	if !client.lastPing.IsZero() {
+		timeout := missedPingsCount * now.Sub(client.lastPing)
+		if client.pingTimer == nil {
+			client.pingTimer = time.AfterFunc(timeout, func() {
+				client.Lock()
+				if client.hbt != nil {
+					client.pingsMissed = true
+					if client.hbt.Stop() {
+						client.hbt.Reset(0)
+					}
+				}
+				client.Unlock()
+			})
+		} else {
+			client.pingTimer.Reset(timeout)
+		}
+	}
+	client.pingsMissed = false
+	client.lastPing = now
+}
+
 // processClientPublish process inbound messages from clients.
 func (s *StanServer) processClientPublish(m *nats.Msg) {
 	iopm := &ioPendingMsg{m: m}
@@ -2155,15 +2681,126 @@ func (s *StanServer) processClientPublish(m *nats.Msg) {
 	}
 
 	// Make sure we have a clientID, guid, etc.
-	if pm.Guid == "" || !s.clients.isValid(pm.ClientID) || !util.IsSubjectValid(pm.Subject, false) {
+	var c *client
+	if pm.Guid != "" {
+		c = s.clients.lookup(pm.ClientID)
+	}
+	if c == nil || !util.IsSubjectValid(pm.Subject, false) {
 		s.log.Errorf("Received invalid client publish message %v", pm)
 		s.sendPublishErr(m.Reply, pm.Guid, ErrInvalidPubReq)
 		return
 	}
 
+	if !c.checkIdentity(pm.ConnID, s.authRequired()) {
+		s.log.Errorf("[Client:%s] Publish rejected; it does not come from the client's connection", pm.ClientID)
+		s.sendPublishErr(m.Reply, pm.Guid, ErrInvalidCredentials)
+		return
+	}
+
+	if !s.getPermissions().canPublish(pm.ClientID, pm.Subject) {
+		s.log.Errorf("[Client:%s] Not authorized to publish to %q", pm.ClientID, pm.Subject)
+		s.sendPublishErr(m.Reply, pm.Guid, ErrPubNotAuthorized)
+		return
+	}
+
+	if err := s.checkPubQuota(c, time.Now().Unix(), uint64(len(pm.Data))); err != nil {
+		s.log.Debugf("[Client:%s] Publish to %q rejected: %v", pm.ClientID, pm.Subject, err)
+		s.sendPublishErr(m.Reply, pm.Guid, err)
+		return
+	}
+
+	if s.msgTracer.enabled() {
+		s.msgTracer.guidEvent(pm.Guid, msgTraceReceived,
+			fmt.Sprintf("client=%s subject=%s", pm.ClientID, pm.Subject))
+	}
+
+	iopm.ackSeq = c.hasCapability(spb.Capability_CapPubAckSequence)
 	s.ioChannel <- iopm
 }
 
+// processPubBatchRequest processes a batch publish request. The messages
+// that can not be accepted are acked with an error, the others are
+// passed to the ioLoop, which stores them in the same batch and sends
+// back a single ack for the whole request. For an atomic batch, the
+// whole request is rejected if any message can not be accepted.
+func (s *StanServer) processPubBatchRequest(m *nats.Msg) {
+	batch := &ioPendingBatch{}
+	req := &batch.req
+	if req.Unmarshal(m.Data) != nil || len(req.Msgs) == 0 {
+		s.log.Errorf("Received invalid batch publish request")
+		s.sendPubBatchErr(m.Reply, ErrInvalidPubReq)
+		return
+	}
+	size := 0
+	for _, pm := range req.Msgs {
+		size += len(pm.Data)
+	}
+	if len(req.Msgs) > MaxPubBatchMsgs || size > MaxPubBatchBytes {
+		s.log.Errorf("[Client:%s] Batch publish request too big: %v messages, %v bytes", req.ClientID, len(req.Msgs), size)
+		s.sendPubBatchErr(m.Reply, ErrPubBatchTooBig)
+		return
+	}
+	c := s.clients.lookup(req.ClientID)
+	if c == nil {
+		s.log.Errorf("Received batch publish request from unknown client %q", req.ClientID)
+		s.sendPubBatchErr(m.Reply, ErrInvalidPubReq)
+		return
+	}
+	if !c.checkIdentity(req.ConnID, s.authRequired()) {
+		s.log.Errorf("[Client:%s] Batch publish rejected; it does not come from the client's connection", req.ClientID)
+		s.sendPubBatchErr(m.Reply, ErrInvalidCredentials)
+		return
+	}
+	var (
+		perms   = s.getPermissions()
+		now     = time.Now().Unix()
+		tracing = s.msgTracer.enabled()
+		acks    = make([]spb.PubAck, len(req.Msgs))
+	)
+	batch.ack.Acks = make([]*spb.PubAck, len(req.Msgs))
+	for i, pm := range req.Msgs {
+		pm.ClientID = req.ClientID
+		ack := &acks[i]
+		ack.Guid = pm.Guid
+		batch.ack.Acks[i] = ack
+		if pm.Guid == "" || !util.IsSubjectValid(pm.Subject, false) {
+			s.log.Errorf("[Client:%s] Invalid message in batch publish request: %v", pm.ClientID, pm)
+			ack.Error = ErrInvalidPubReq.Error()
+			continue
+		}
+		if !perms.canPublish(pm.ClientID, pm.Subject) {
+			s.log.Errorf("[Client:%s] Not authorized to publish to %q", pm.ClientID, pm.Subject)
+			ack.Error = ErrPubNotAuthorized.Error()
+			continue
+		}
+		if err := s.checkPubQuota(c, now, uint64(len(pm.Data))); err != nil {
+			s.log.Debugf("[Client:%s] Publish to %q rejected: %v", pm.ClientID, pm.Subject, err)
+			ack.Error = err.Error()
+			continue
+		}
+		if tracing {
+			s.msgTracer.guidEvent(pm.Guid, msgTraceReceived,
+				fmt.Sprintf("client=%s subject=%s", pm.ClientID, pm.Subject))
+		}
+	}
+	if req.Atomic {
+		for _, ack := range acks {
+			if ack.Error != "" {
+				s.sendPubBatchErr(m.Reply, errors.New(ack.Error))
+				return
+			}
+		}
+	}
+	s.ioChannel <- &ioPendingMsg{m: m, batch: batch}
+}
+
+func (s *StanServer) sendPubBatchErr(subj string, err error) {
+	badBatchAck := &spb.PubBatchAck{Error: err.Error()}
+	if b, err := badBatchAck.Marshal(); err == nil {
+		s.ncs.Publish(subj, b)
+	}
+}
+
 // processCtrlMsg processes the incoming message has a CtrlMsg.
 // If this is not a CtrlMsg, returns false to indicate an error.
 // If the CtrlMsg's ServerID is not this server, the request is simply
@@ -2207,7 +2844,7 @@ func (s *StanServer) processCtrlMsg(m *nats.Msg) bool {
 		// to differentiate between unsubscribe and close.
 		fallthrough
 	case spb.CtrlMsg_SubClose:
-		req := &pb.UnsubscribeRequest{}
+		req := &spb.UnsubscribeRequest{}
 		req.Unmarshal(cm.Data)
 		s.performSubUnsubOrClose(cm.MsgType, processRequest, m, req)
 	case spb.CtrlMsg_ConnClose:
@@ -2220,7 +2857,7 @@ func (s *StanServer) processCtrlMsg(m *nats.Msg) bool {
 }
 
 func (s *StanServer) sendPublishErr(subj, guid string, err error) {
-	badMsgAck := &pb.PubAck{Guid: guid, Error: err.Error()}
+	badMsgAck := &spb.PubAck{Guid: guid, Error: err.Error()}
 	if b, err := badMsgAck.Marshal(); err == nil {
 		s.ncs.Publish(subj, b)
 	}
@@ -2228,7 +2865,7 @@ func (s *StanServer) sendPublishErr(subj, guid string, err error) {
 
 // FIXME(dlc) - place holder to pick sub that has least outstanding, should just sort,
 // or use insertion sort, etc.
-func findBestQueueSub(sl []*subState) *subState {
+func findBestQueueSub(sl []*subState, size int) *subState {
 	var (
 		leastOutstanding = int(^uint(0) >> 1)
 		rsub             *subState
@@ -2239,10 +2876,12 @@ func findBestQueueSub(sl []*subState) *subState {
 		sOut := len(sub.acksPending)
 		sStalled := sub.stalled
 		sHasFailedHB := sub.hasFailedHB
+		sFits := sub.inflightBytesAllow(size)
 		sub.RUnlock()
 
-		// Favor non stalled subscribers and clients that do not have failed heartbeats
-		if !sStalled && !sHasFailedHB {
+		// Favor non stalled subscribers, with room for this message, and
+		// clients that do not have failed heartbeats
+		if !sStalled && sFits && !sHasFailedHB {
 			if sOut < leastOutstanding {
 				leastOutstanding = sOut
 				rsub = sub
@@ -2265,7 +2904,7 @@ func findBestQueueSub(sl []*subState) *subState {
 // Send a message to the queue group
 // Assumes qs lock held for write
 func (s *StanServer) sendMsgToQueueGroup(qs *queueState, m *pb.MsgProto, force bool) (*subState, bool, bool) {
-	sub := findBestQueueSub(qs.subs)
+	sub := findBestQueueSub(qs.subs, len(m.Data))
 	if sub == nil {
 		return nil, false, false
 	}
@@ -2355,9 +2994,13 @@ func (s *StanServer) performDurableRedelivery(c *channel, sub *subState) {
 	clientID := sub.ClientID
 	newOnHold := sub.newOnHold
 	subID := sub.ID
+	// Pending messages are redelivered on ack expiration once resumed.
+	if sub.Paused && sub.PauseRedeliveries {
+		sortedSeqs = nil
+	}
 	sub.RUnlock()
 
-	if s.debug && len(sortedSeqs) > 0 {
+	if s.isDebug() && len(sortedSeqs) > 0 {
 		sub.RLock()
 		durName := sub.DurableName
 		if durName == "" {
@@ -2379,7 +3022,7 @@ func (s *StanServer) performDurableRedelivery(c *channel, sub *subState) {
 			continue
 		}
 
-		if s.trace {
+		if s.isTrace() {
 			s.log.Tracef("[Client:%s] Redelivering to subid=%d, seq=%d", clientID, subID, m.Sequence)
 		}
 
@@ -2417,13 +3060,20 @@ func (s *StanServer) performAckExpirationRedelivery(sub *subState, isStartup boo
 	if sub.ackTimer == nil {
 		s.setupAckTimer(sub, sub.ackWait)
 	}
+	// Redeliveries are held while the subscription is paused with its
+	// redeliveries.
+	if sub.Paused && sub.PauseRedeliveries {
+		sub.ackTimer.Reset(sub.ackWait)
+		sub.Unlock()
+		return
+	}
 	if qs == nil {
 		// If the client has some failed heartbeats, ignore this request.
 		if sub.hasFailedHB {
 			// Reset the timer
 			sub.ackTimer.Reset(sub.ackWait)
 			sub.Unlock()
-			if s.debug {
+			if s.isDebug() {
 				s.log.Debugf("[Client:%s] Skipping redelivery to subid=%d due to missed client heartbeat", clientID, subID)
 			}
 			return
@@ -2469,7 +3119,7 @@ func (s *StanServer) performAckExpirationRedelivery(sub *subState, isStartup boo
 			if nextExpirationTIme == 0 {
 				nextExpirationTIme = expireTime
 			}
-			if !tracePrinted && s.trace {
+			if !tracePrinted && s.isTrace() {
 				tracePrinted = true
 				s.log.Tracef("[Client:%s] Redelivery for subid=%d, skipping seq=%d", clientID, subID, m.Sequence)
 			}
@@ -2550,14 +3200,15 @@ func (s *StanServer) sendMsgToSub(sub *subState, m *pb.MsgProto, force bool) (bo
 		return false, false
 	}
 
-	// Don't send if we have too many outstanding already, unless forced to send.
+	// Don't send if we have too many outstanding already, or not enough
+	// room for the payload, unless forced to send.
 	ap := int32(len(sub.acksPending))
-	if !force && (ap >= sub.MaxInFlight) {
+	if !force && (ap >= sub.MaxInFlight || !sub.inflightBytesAllow(len(m.Data))) {
 		sub.stalled = true
 		return false, false
 	}
 
-	if s.trace {
+	if s.isTrace() {
 		var action string
 		if m.Redelivered {
 			action = "Redelivering"
@@ -2581,6 +3232,19 @@ func (s *StanServer) sendMsgToSub(sub *subState, m *pb.MsgProto, force bool) (bo
 		return false, false
 	}
 
+	now := time.Now()
+	sub.chStats.out.add(now.Unix(), uint64(len(m.Data)))
+	if m.Redelivered {
+		sub.redeliveries++
+	}
+	if s.msgTracer.enabled() {
+		event := msgTraceDelivered
+		if m.Redelivered {
+			event = msgTraceRedelivered
+		}
+		s.msgTracer.seqEvent(m.Subject, m.Sequence, event, sub.traceDetails())
+	}
+
 	// Setup the ackTimer as needed now. I don't want to use defer in this
 	// function, and want to make sure that if we exit before the end, the
 	// timer is set. It will be adjusted/stopped as needed.
@@ -2594,11 +3258,13 @@ func (s *StanServer) sendMsgToSub(sub *subState, m *pb.MsgProto, force bool) (bo
 		if expTime == 0 {
 			// That can happen after a server restart, so need to use
 			// the current time.
-			expTime = time.Now().UnixNano()
+			expTime = now.UnixNano()
 		}
 		// bump the next expiration time with the sub's ackWait.
 		expTime += int64(sub.ackWait)
 		sub.acksPending[m.Sequence] = expTime
+		// The size is not known for messages recovered from the store.
+		sub.setPendingSize(m.Sequence, len(m.Data))
 		return true, true
 	}
 	// Store in storage
@@ -2618,12 +3284,13 @@ func (s *StanServer) sendMsgToSub(sub *subState, m *pb.MsgProto, force bool) (bo
 	// A message can be persisted in the log and send much later to a
 	// new subscriber. Basing expiration time on m.Timestamp would
 	// likely set the expiration time in the past!
-	sub.acksPending[m.Sequence] = time.Now().UnixNano() + int64(sub.ackWait)
+	sub.acksPending[m.Sequence] = now.UnixNano() + int64(sub.ackWait)
+	sub.setPendingSize(m.Sequence, len(m.Data))
 
 	// Now that we have added to acksPending, check again if we
 	// have reached the max and tell the caller that it should not
 	// be sending more at this time.
-	if !force && (ap+1 == sub.MaxInFlight) {
+	if !force && (ap+1 == sub.MaxInFlight || sub.inflightBytesFull()) {
 		sub.stalled = true
 		return true, false
 	}
@@ -2631,6 +3298,42 @@ func (s *StanServer) sendMsgToSub(sub *subState, m *pb.MsgProto, force bool) (bo
 	return true, true
 }
 
+// setPendingSize records the payload size of the message `seq` pending
+// acknowledgment, replacing the size that may already be recorded.
+// sub's lock held on entry.
+func (sub *subState) setPendingSize(seq uint64, size int) {
+	if sub.pendingSizes == nil {
+		sub.pendingSizes = make(map[uint64]int)
+	}
+	sub.pendingBytes += int64(size - sub.pendingSizes[seq])
+	sub.pendingSizes[seq] = size
+}
+
+// clearPendingSize removes the payload size of the message `seq` that is no
+// longer pending acknowledgment.
+// sub's lock held on entry.
+func (sub *subState) clearPendingSize(seq uint64) {
+	if size, ok := sub.pendingSizes[seq]; ok {
+		sub.pendingBytes -= int64(size)
+		delete(sub.pendingSizes, seq)
+	}
+}
+
+// inflightBytesAllow returns true if a message with a payload of `size` bytes
+// can be sent without exceeding MaxInFlightBytes. A message is always allowed
+// if no payload is inflight, otherwise a message larger than the limit could
+// never be delivered.
+// sub's lock held on entry.
+func (sub *subState) inflightBytesAllow(size int) bool {
+	return sub.MaxInFlightBytes <= 0 || sub.pendingBytes == 0 || sub.pendingBytes+int64(size) <= sub.MaxInFlightBytes
+}
+
+// inflightBytesFull returns true if the payloads inflight reach MaxInFlightBytes.
+// sub's lock held on entry.
+func (sub *subState) inflightBytesFull() bool {
+	return sub.MaxInFlightBytes > 0 && sub.pendingBytes >= sub.MaxInFlightBytes
+}
+
 // Sets up the ackTimer to fire at the given duration.
 // sub's lock held on entry.
 func (s *StanServer) setupAckTimer(sub *subState, d time.Duration) {
@@ -2669,14 +3372,84 @@ func (s *StanServer) ioLoop(ready *sync.WaitGroup) {
 	var _pendingMsgs [ioChannelSize]*ioPendingMsg
 	var pendingMsgs = _pendingMsgs[:0]
 
+	var now int64
+
+	// Store error (not caused by limits) of the current batch, and whether
+	// one has been reported to the health endpoint and not cleared yet.
+	var (
+		storeErr         error
+		storeErrReported bool
+	)
+	// Errors, keyed by channel name, of the stores that failed to flush.
+	var flushErrs map[string]error
+
 	storeIOPendingMsg := func(iopm *ioPendingMsg) {
-		cs, err := s.assignAndStore(&iopm.pm)
+		tracing := s.msgTracer.enabled()
+		if iopm.batch != nil && iopm.batch.req.Atomic {
+			cs, err := s.storeTx(iopm.batch)
+			if err != nil {
+				s.log.Errorf("[Client:%s] Error processing transaction: %v", iopm.batch.req.ClientID, err)
+				if err != stores.ErrTooManyChannels {
+					storeErr = err
+				}
+			}
+			for _, c := range cs {
+				storesToFlush[c] = struct{}{}
+			}
+			pendingMsgs = append(pendingMsgs, iopm)
+			return
+		}
+		if iopm.batch != nil {
+			// Store the messages that were accepted, and ack the
+			// whole request after the flush, like a single message.
+			acks := iopm.batch.ack.Acks
+			for i, pm := range iopm.batch.req.Msgs {
+				ack := acks[i]
+				if ack.Error != "" {
+					continue
+				}
+				if tracing {
+					s.msgTracer.guidEvent(pm.Guid, msgTraceBatched, "")
+				}
+				cs, seq, err := s.assignAndStore(pm)
+				if err != nil {
+					s.log.Errorf("[Client:%s] Error processing message for subject %q: %v", pm.ClientID, pm.Subject, err)
+					ack.Error = err.Error()
+					if tracing {
+						s.msgTracer.guidEvent(pm.Guid, msgTraceStoreFailed, err.Error())
+					}
+					if err != stores.ErrTooManyChannels {
+						storeErr = err
+					}
+					continue
+				}
+				ack.Sequence = seq
+				storesToFlush[cs] = struct{}{}
+				cs.stats.in.add(now, uint64(len(pm.Data)))
+			}
+			pendingMsgs = append(pendingMsgs, iopm)
+			return
+		}
+		if tracing {
+			s.msgTracer.guidEvent(iopm.pm.Guid, msgTraceBatched, "")
+		}
+		cs, seq, err := s.assignAndStore(&iopm.pm)
 		if err != nil {
 			s.log.Errorf("[Client:%s] Error processing message for subject %q: %v", iopm.pm.ClientID, iopm.m.Subject, err)
 			s.sendPublishErr(iopm.m.Reply, iopm.pm.Guid, err)
+			if tracing {
+				s.msgTracer.guidEvent(iopm.pm.Guid, msgTraceStoreFailed, err.Error())
+			}
+			if err != stores.ErrTooManyChannels {
+				storeErr = err
+			}
 		} else {
+			if iopm.ackSeq {
+				iopm.pa.Sequence = seq
+			}
 			pendingMsgs = append(pendingMsgs, iopm)
 			storesToFlush[cs] = struct{}{}
+			cs.stats.in.add(now, uint64(len(iopm.pm.Data)))
 		}
 	}
 
@@ -2689,6 +3462,8 @@ func (s *StanServer) ioLoop(ready *sync.WaitGroup) {
 	for {
 		select {
 		case iopm := <-s.ioChannel:
+			now = time.Now().Unix()
+			storeErr = nil
 			// store the one we just pulled
 			storeIOPendingMsg(iopm)
 
@@ -2731,25 +3506,45 @@ func (s *StanServer) ioLoop(ready *sync.WaitGroup) {
 			// flush all the stores with messages written to them...
 			for c := range storesToFlush {
 				if err := c.store.Msgs.Flush(); err != nil {
-					// TODO: Attempt recovery, notify publishers of error.
-					panic(fmt.Errorf("Unable to flush msg store: %v", err))
+					s.log.Errorf("Unable to flush msg store for channel %q: %v", c.name, err)
+					storeErr = err
+					// Notify the publishers of this channel of the error.
+					if flushErrs == nil {
+						flushErrs = make(map[string]error)
+					}
+					flushErrs[c.name] = err
+				} else {
+					// Call this here, so messages are sent to subscribers,
+					// which means that msg seq is added to subscription file.
+					// Messages that could not be flushed are not delivered
+					// since their publishers get an error.
+					s.processMsg(c)
 				}
-				// Call this here, so messages are sent to subscribers,
-				// which means that msg seq is added to subscription file
-				s.processMsg(c)
 				if err := c.store.Subs.Flush(); err != nil {
-					panic(fmt.Errorf("Unable to flush sub store: %v", err))
+					s.log.Errorf("Unable to flush sub store for channel %q: %v", c.name, err)
+					storeErr = err
 				}
 				// Remove entry from map (this is safe in Go)
 				delete(storesToFlush, c)
 			}
 
+			// Report the store error, or clear the previous one if this
+			// batch was stored successfully.
+			if storeErr != nil || storeErrReported {
+				s.setStoreError(storeErr)
+				storeErrReported = storeErr != nil
+			}
+
 			// Ack our messages back to the publisher
 			for i := range pendingMsgs {
 				iopm := pendingMsgs[i]
+				if flushErrs != nil {
+					setFlushErrors(iopm, flushErrs)
+				}
 				s.ackPublisher(iopm)
 				pendingMsgs[i] = nil
 			}
+			flushErrs = nil
 
 			// clear out pending messages
 			pendingMsgs = pendingMsgs[:0]
@@ -2761,29 +3556,141 @@ func (s *StanServer) ioLoop(ready *sync.WaitGroup) {
 }
 
 // assignAndStore will assign a sequence ID and then store the message.
-func (s *StanServer) assignAndStore(pm *pb.PubMsg) (*channel, error) {
+// Returns the channel and the sequence assigned to the message.
+func (s *StanServer) assignAndStore(pm *spb.PubMsg) (*channel, uint64, error) {
 	c, err := s.lookupOrCreateChannel(pm.Subject)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	seq, err := c.store.Msgs.Store(pm.Data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.msgTracer.enabled() {
+		s.msgTracer.stored(pm.Guid, c.name, seq)
+	}
+	return c, seq, nil
+}
+
+// storeTx stores the messages of an atomic batch publish request as a
+// single transaction, and updates the batch's acks. On error, no message
+// is stored, and the acks are replaced with the error. Returns the
+// channels the messages were stored into.
+func (s *StanServer) storeTx(batch *ioPendingBatch) ([]*channel, error) {
+	var (
+		now     = time.Now().Unix()
+		tracing = s.msgTracer.enabled()
+		msgs    = batch.req.Msgs
+		txMsgs  = make([]*stores.TxMsg, len(msgs))
+		cs      = make([]*channel, len(msgs))
+		err     error
+	)
+	for i, pm := range msgs {
+		if tracing {
+			s.msgTracer.guidEvent(pm.Guid, msgTraceBatched, "")
+		}
+		// Channels of a transaction that fails are not removed.
+		if cs[i], err = s.lookupOrCreateChannel(pm.Subject); err != nil {
+			break
+		}
+		txMsgs[i] = &stores.TxMsg{Channel: pm.Subject, Data: pm.Data}
 	}
-	if _, err := c.store.Msgs.Store(pm.Data); err != nil {
+	var seqs []uint64
+	if err == nil {
+		seqs, err = s.store.StoreTx(txMsgs)
+	}
+	if err != nil {
+		if tracing {
+			for _, pm := range msgs {
+				s.msgTracer.guidEvent(pm.Guid, msgTraceStoreFailed, err.Error())
+			}
+		}
+		batch.ack.Acks = nil
+		batch.ack.Error = err.Error()
 		return nil, err
 	}
-	return c, nil
+	for i, pm := range msgs {
+		batch.ack.Acks[i].Sequence = seqs[i]
+		cs[i].stats.in.add(now, uint64(len(pm.Data)))
+		if tracing {
+			s.msgTracer.stored(pm.Guid, cs[i].name, seqs[i])
+		}
+	}
+	return cs, nil
 }
 
-// ackPublisher sends the ack for a message.
+// ackPublisher sends the ack for a message, or for all the messages
+// of a batch publish request.
 func (s *StanServer) ackPublisher(iopm *ioPendingMsg) {
+	if iopm.batch != nil {
+		s.ackBatchPublisher(iopm)
+		return
+	}
 	msgAck := &iopm.pa
 	msgAck.Guid = iopm.pm.Guid
 	needed := msgAck.Size()
 	s.tmpBuf = util.EnsureBufBigEnough(s.tmpBuf, needed)
 	n, _ := msgAck.MarshalTo(s.tmpBuf)
-	if s.trace {
+	if s.isTrace() {
 		pm := &iopm.pm
 		s.log.Tracef("[Client:%s] Acking Publisher subj=%s guid=%s", pm.ClientID, pm.Subject, pm.Guid)
 	}
 	s.ncs.Publish(iopm.m.Reply, s.tmpBuf[:n])
+	if s.msgTracer.enabled() {
+		s.msgTracer.guidEvent(iopm.pm.Guid, msgTracePublisherAcked, "")
+	}
+}
+
+// setFlushErrors sets, in the ack(s) of `iopm`, the error of the store
+// in which the message(s) could not be flushed. A transaction fails as
+// a whole if any of its channels could not be flushed.
+func setFlushErrors(iopm *ioPendingMsg, flushErrs map[string]error) {
+	if iopm.batch == nil {
+		if err, ok := flushErrs[iopm.pm.Subject]; ok {
+			iopm.pa.Sequence = 0
+			iopm.pa.Error = err.Error()
+		}
+		return
+	}
+	batchAck := &iopm.batch.ack
+	// Nothing was stored for a transaction that failed.
+	if batchAck.Error != "" || len(batchAck.Acks) == 0 {
+		return
+	}
+	for i, pm := range iopm.batch.req.Msgs {
+		err, ok := flushErrs[pm.Subject]
+		if !ok {
+			continue
+		}
+		if iopm.batch.req.Atomic {
+			batchAck.Acks = nil
+			batchAck.Error = err.Error()
+			return
+		}
+		if ack := batchAck.Acks[i]; ack.Error == "" {
+			ack.Sequence = 0
+			ack.Error = err.Error()
+		}
+	}
+}
+
+// ackBatchPublisher sends the ack for a batch publish request.
+func (s *StanServer) ackBatchPublisher(iopm *ioPendingMsg) {
+	batchAck := &iopm.batch.ack
+	needed := batchAck.Size()
+	s.tmpBuf = util.EnsureBufBigEnough(s.tmpBuf, needed)
+	n, _ := batchAck.MarshalTo(s.tmpBuf)
+	if s.isTrace() {
+		s.log.Tracef("[Client:%s] Acking batch Publisher msgs=%v", iopm.batch.req.ClientID, len(batchAck.Acks))
+	}
+	s.ncs.Publish(iopm.m.Reply, s.tmpBuf[:n])
+	if s.msgTracer.enabled() {
+		for _, ack := range batchAck.Acks {
+			if ack.Error == "" {
+				s.msgTracer.guidEvent(ack.Guid, msgTracePublisherAcked, "")
+			}
+		}
+	}
 }
 
 // Delete a sub from a given list.
@@ -2838,31 +3745,39 @@ func (s *StanServer) removeAllNonDurableSubscribers(client *client) {
 
 // processUnsubscribeRequest will process a unsubscribe request.
 func (s *StanServer) processUnsubscribeRequest(m *nats.Msg) {
-	req := &pb.UnsubscribeRequest{}
+	req := &spb.UnsubscribeRequest{}
 	err := req.Unmarshal(m.Data)
 	if err != nil {
 		s.log.Errorf("Invalid unsub request from %s", m.Subject)
 		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidUnsubReq)
 		return
 	}
+	if err := s.checkClientIdentity(req.ClientID, req.ConnID); err != nil {
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
 	s.performSubUnsubOrClose(spb.CtrlMsg_SubUnsubscribe, scheduleRequest, m, req)
 }
 
 // processSubCloseRequest will process a subscription close request.
 func (s *StanServer) processSubCloseRequest(m *nats.Msg) {
-	req := &pb.UnsubscribeRequest{}
+	req := &spb.UnsubscribeRequest{}
 	err := req.Unmarshal(m.Data)
 	if err != nil {
 		s.log.Errorf("Invalid sub close request from %s", m.Subject)
 		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidUnsubReq)
 		return
 	}
+	if err := s.checkClientIdentity(req.ClientID, req.ConnID); err != nil {
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
 	s.performSubUnsubOrClose(spb.CtrlMsg_SubClose, scheduleRequest, m, req)
 }
 
 // performSubUnsubOrClose either schedules the request to the
 // subscriber's AckInbox subscriber, or processes the request in place.
-func (s *StanServer) performSubUnsubOrClose(reqType spb.CtrlMsg_Type, schedule bool, m *nats.Msg, req *pb.UnsubscribeRequest) {
+func (s *StanServer) performSubUnsubOrClose(reqType spb.CtrlMsg_Type, schedule bool, m *nats.Msg, req *spb.UnsubscribeRequest) {
 	// With partitioning, first verify that this server is handling this
 	// channel. If not, do not return an error, since another server will
 	// handle it. If no other server is, the client will get a timeout.
@@ -2872,6 +3787,11 @@ func (s *StanServer) performSubUnsubOrClose(reqType spb.CtrlMsg_Type, schedule b
 		}
 	}
 
+	if !util.IsSubjectLiteral(req.Subject) {
+		s.performWildcardUnsubOrClose(reqType, schedule, m, req)
+		return
+	}
+
 	action := "unsub"
 	isSubClose := false
 	if reqType == spb.CtrlMsg_SubClose {
@@ -2890,6 +3810,13 @@ func (s *StanServer) performSubUnsubOrClose(reqType spb.CtrlMsg_Type, schedule b
 	ss := c.ss
 
 	sub := ss.LookupByAckInbox(req.Inbox)
+	if sub == nil {
+		// Standbys of exclusive durables don't receive acks, so their
+		// request does not need to be ordered with acks.
+		if sub = ss.lookupStandby(req.Inbox); sub != nil {
+			schedule = false
+		}
+	}
 	if sub == nil {
 		s.log.Errorf("[Client:%s] %s request for missing inbox %s",
 			req.ClientID, action, req.Inbox)
@@ -2931,10 +3858,26 @@ func (s *StanServer) performSubUnsubOrClose(reqType spb.CtrlMsg_Type, schedule b
 
 	// Remove the subscription
 	unsubscribe := !isSubClose
+	sub.RLock()
+	isDurable, durableName, qgroup, durableKey := sub.IsDurable, sub.DurableName, sub.QGroup, sub.durableKey()
+	sub.RUnlock()
 	ss.Remove(c, sub, unsubscribe)
 	s.monMu.Lock()
 	s.numSubs--
 	s.monMu.Unlock()
+	if unsubscribe && isDurable && s.auditLog != nil {
+		// A durable queue group is deleted only when its last member leaves,
+		// and an exclusive durable only when it has no standby.
+		ss.RLock()
+		deleted := ss.durables[durableKey] == nil
+		if qgroup != "" {
+			deleted = ss.qsubs[qgroup] == nil
+		}
+		ss.RUnlock()
+		if deleted {
+			s.auditf(audit.EventDurableDelete, req.ClientID, "%s", durableDetails(req.Subject, durableName, qgroup))
+		}
+	}
 
 	// Create a non-error response
 	resp := &pb.SubscriptionResponse{AckInbox: req.Inbox}
@@ -2994,11 +3937,28 @@ func (sub *subState) adjustAckTimer(nextExpirationTime int64) {
 	}
 }
 
+// traceDetails returns the subscription's information reported in
+// message traces.
+// Sub lock held on entry.
+func (sub *subState) traceDetails() string {
+	details := fmt.Sprintf("client=%s subid=%d inbox=%s", sub.ClientID, sub.ID, sub.Inbox)
+	if sub.QGroup != "" {
+		details += " queue=" + sub.QGroup
+	}
+	if sub.DurableName != "" {
+		details += " durable=" + sub.DurableName
+	}
+	return details
+}
+
 // Used to generate durable key. This should not be called on non-durables.
 func (sub *subState) durableKey() string {
 	if sub.DurableName == "" {
 		return ""
 	}
+	if sub.Exclusive {
+		return exclusiveDurableKey(sub.subject, sub.DurableName)
+	}
 	return fmt.Sprintf("%s-%s-%s", sub.ClientID, sub.subject, sub.DurableName)
 }
 
@@ -3028,10 +3988,13 @@ func (sub *subState) isOfflineDurableSubscriber() bool {
 }
 
 // Used to generate durable key. This should not be called on non-durables.
-func durableKey(sr *pb.SubscriptionRequest) string {
+func durableKey(sr *spb.SubscriptionRequest) string {
 	if sr.DurableName == "" {
 		return ""
 	}
+	if sr.Exclusive {
+		return exclusiveDurableKey(sr.Subject, sr.DurableName)
+	}
 	return fmt.Sprintf("%s-%s-%s", sr.ClientID, sr.Subject, sr.DurableName)
 }
 
@@ -3072,9 +4035,53 @@ func (s *StanServer) updateDurable(ss *subStore, sub *subState) error {
 	return nil
 }
 
+// resumeDurable prepares the remembered subscription `sub` to be resumed
+// by the subscription request `sr`, with the new `ackInbox`.
+func (s *StanServer) resumeDurable(sub *subState, sr *spb.SubscriptionRequest, ackInbox string) {
+	sub.Lock()
+	// Set ClientID and new AckInbox but leave LastSent to the
+	// remembered value.
+	sub.AckInbox = ackInbox
+	sub.ClientID = sr.ClientID
+	sub.Inbox = sr.Inbox
+	sub.IsDurable = true
+	// Use some of the new options, but ignore the ones regarding start position
+	sub.MaxInFlight = sr.MaxInFlight
+	sub.MaxInFlightBytes = sr.MaxInFlightBytes
+	sub.AckWaitInSecs = sr.AckWaitInSecs
+	sub.ackWait = computeAckWait(sr.AckWaitInSecs)
+	sub.ReplaySpeedPercent = sr.ReplaySpeedPercent
+	sub.replayMsgTime = 0
+	sub.DeliveryRate = sr.DeliveryRate
+	sub.DeliveryBurst = sr.DeliveryBurst
+	qs := sub.qstate
+	if qs == nil {
+		if sub.limiter != nil {
+			sub.limiter.stop()
+		}
+		sub.limiter = newDeliveryLimiter(sr.DeliveryRate, sr.DeliveryBurst)
+	}
+	sub.stalled = false
+	if len(sub.acksPending) > 0 {
+		// We have a durable with pending messages, set newOnHold
+		// until we have performed the initial redelivery.
+		sub.newOnHold = true
+		s.setupAckTimer(sub, sub.ackWait)
+	}
+	// Clear the removed and IsClosed flags that were set during a Close()
+	sub.removed = false
+	sub.IsClosed = false
+	sub.OfflineTime = 0
+	sub.clearIdleTimer()
+	// A durable closed after reaching its stop position is closed again
+	// once resumed.
+	sub.replayEnded = false
+	sub.Unlock()
+}
+
 // processSubscriptionRequest will process a subscription request.
 func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
-	sr := &pb.SubscriptionRequest{}
+	sr := &spb.SubscriptionRequest{}
 	err := sr.Unmarshal(m.Data)
 	if err != nil {
 		s.log.Errorf("Invalid Subscription request from %s: %v", m.Subject, err)
@@ -3088,6 +4095,10 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 		s.sendSubscriptionResponseErr(m.Reply, ErrMissingClient)
 		return
 	}
+	if err := s.checkClientIdentity(sr.ClientID, sr.ConnID); err != nil {
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
 
 	// AckWait must be >= 1s (except in test mode where negative value means that
 	// duration should be interpreted as Milliseconds)
@@ -3106,8 +4117,16 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 		return
 	}
 
-	// StartPosition between StartPosition_NewOnly and StartPosition_First
-	if sr.StartPosition < pb.StartPosition_NewOnly || sr.StartPosition > pb.StartPosition_First {
+	// MaxInflightBytes must be >= 0 (0 means no limit)
+	if sr.MaxInFlightBytes < 0 {
+		s.log.Errorf("[Client:%s] Invalid MaxInflightBytes (%v) in subscription request from %s",
+			sr.ClientID, sr.MaxInFlightBytes, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidMaxInflightBytes)
+		return
+	}
+
+	// StartPosition between StartPosition_NewOnly and StartPosition_LastN
+	if sr.StartPosition < spb.StartPosition_NewOnly || sr.StartPosition > spb.StartPosition_LastN {
 		s.log.Errorf("[Client:%s] Invalid StartPosition (%v) in subscription request from %s",
 			sr.ClientID, int(sr.StartPosition), m.Subject)
 		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidStart)
@@ -3115,13 +4134,49 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 	}
 
 	// Make sure subject is valid
-	if !util.IsSubjectValid(sr.Subject, false) {
+	if !util.IsSubjectValid(sr.Subject, true) {
 		s.log.Errorf("[Client:%s] Invalid Subject %q in subscription request from %s",
 			sr.ClientID, sr.Subject, m.Subject)
 		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidSubject)
 		return
 	}
 
+	// A stop position is only supported for plain and durable subscriptions.
+	if (sr.StopSequence != 0 || sr.StopTimeDelta != 0) && (sr.QGroup != "" || !util.IsSubjectLiteral(sr.Subject)) {
+		s.log.Errorf("[Client:%s] Invalid stop position in subscription request from %s",
+			sr.ClientID, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidStop)
+		return
+	}
+
+	// A burst is only valid with a delivery rate.
+	if sr.DeliveryBurst != 0 && sr.DeliveryRate == 0 {
+		s.log.Errorf("[Client:%s] Invalid delivery rate in subscription request from %s",
+			sr.ClientID, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidDeliveryRate)
+		return
+	}
+
+	if sr.ReplaySpeedPercent != 0 && sr.QGroup != "" {
+		s.log.Errorf("[Client:%s] Invalid replay speed for queue subscriber from %s",
+			sr.ClientID, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidReplay)
+		return
+	}
+
+	if sr.Exclusive && (sr.DurableName == "" || sr.QGroup != "" || !util.IsSubjectLiteral(sr.Subject)) {
+		s.log.Errorf("[Client:%s] Invalid exclusive subscription request from %s",
+			sr.ClientID, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidExclusive)
+		return
+	}
+
+	// Subscriptions on a wildcard subject span several channels.
+	if !util.IsSubjectLiteral(sr.Subject) {
+		s.processWildcardSubscriptionRequest(m, sr)
+		return
+	}
+
 	// In partitioning mode, do not fail the subscription request
 	// if this server does not have the channel. It could be that there
 	// is another server out there that will accept the subscription.
@@ -3132,6 +4187,18 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 		}
 	}
 
+	if !s.getPermissions().canSubscribe(sr.ClientID, sr.Subject) {
+		s.log.Errorf("[Client:%s] Not authorized to subscribe to %q", sr.ClientID, sr.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrSubNotAuthorized)
+		return
+	}
+
+	if err := s.checkSubQuota(sr.ClientID); err != nil {
+		s.log.Errorf("[Client:%s] Subscription to %q rejected: %v", sr.ClientID, sr.Subject, err)
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
+
 	// Grab channel state, create a new one if needed.
 	c, err := s.lookupOrCreateChannel(sr.Subject)
 	if err != nil {
@@ -3151,6 +4218,8 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 	// Will be set to false for en existing durable subscriber or existing
 	// queue group (durable or not).
 	setStartPos := true
+	// Will be set for a new member of a paused queue group.
+	paused, pauseRedeliveries := false, false
 	// Check for durable queue subscribers
 	if sr.QGroup != "" {
 		if sr.DurableName != "" {
@@ -3174,11 +4243,22 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 		qs := ss.qsubs[sr.QGroup]
 		if qs != nil {
 			qs.Lock()
+			// Members must use the delivery rate of the group.
+			if sr.DeliveryRate != qs.rate || sr.DeliveryBurst != qs.burst {
+				qs.Unlock()
+				ss.RUnlock()
+				s.log.Errorf("[Client:%s] Invalid delivery rate for queue group %q from %s",
+					sr.ClientID, sr.QGroup, sr.Subject)
+				s.sendSubscriptionResponseErr(m.Reply, ErrQueueDeliveryRate)
+				return
+			}
 			if qs.shadow != nil {
 				sub = qs.shadow
 				qs.shadow = nil
 				qs.subs = append(qs.subs, sub)
 			}
+			// New members of a paused group are paused too.
+			paused, pauseRedeliveries = qs.paused, qs.pauseRedeliveries
 			qs.Unlock()
 			setStartPos = false
 		}
@@ -3186,16 +4266,34 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 	} else if sr.DurableName != "" {
 		// Check for DurableSubscriber status
 		if sub = ss.LookupByDurable(durableKey(sr)); sub != nil {
-			sub.RLock()
-			clientID := sub.ClientID
-			sub.RUnlock()
+			// Instances of an existing exclusive durable wait as standbys,
+			// even if the durable is offline, in which case the first
+			// standby is promoted right away.
+			if sr.Exclusive {
+				s.addStandby(c, sub, sr, m.Reply, ackInbox, ackSubject)
+				return
+			}
+			sub.Lock()
+			clientID, expired := sub.ClientID, sub.expired
+			// Claim the offline durable so that it does not expire
+			// while being resumed.
+			if clientID == "" && !expired {
+				sub.ClientID = sr.ClientID
+			}
+			sub.Unlock()
 			if clientID != "" {
 				s.log.Errorf("[Client:%s] Invalid ClientID in subscription request from %s",
 					sr.ClientID, m.Subject)
 				s.sendSubscriptionResponseErr(m.Reply, ErrDupDurable)
 				return
 			}
-			setStartPos = false
+			if expired {
+				// The durable has just been removed due to inactivity,
+				// so this request creates a new one.
+				sub = nil
+			} else {
+				setStartPos = false
+			}
 		}
 		isDurable = true
 	}
@@ -3205,28 +4303,7 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 	)
 	if sub != nil {
 		// ok we have a remembered subscription
-		sub.Lock()
-		// Set ClientID and new AckInbox but leave LastSent to the
-		// remembered value.
-		sub.AckInbox = ackInbox
-		sub.ClientID = sr.ClientID
-		sub.Inbox = sr.Inbox
-		sub.IsDurable = true
-		// Use some of the new options, but ignore the ones regarding start position
-		sub.MaxInFlight = sr.MaxInFlight
-		sub.AckWaitInSecs = sr.AckWaitInSecs
-		sub.ackWait = computeAckWait(sr.AckWaitInSecs)
-		sub.stalled = false
-		if len(sub.acksPending) > 0 {
-			// We have a durable with pending messages, set newOnHold
-			// until we have performed the initial redelivery.
-			sub.newOnHold = true
-			s.setupAckTimer(sub, sub.ackWait)
-		}
-		// Clear the removed and IsClosed flags that were set during a Close()
-		sub.removed = false
-		sub.IsClosed = false
-		sub.Unlock()
+		s.resumeDurable(sub, sr, ackInbox)
 
 		// Case of restarted durable subscriber, or first durable queue
 		// subscriber re-joining a group that was left with pending messages.
@@ -3244,13 +4321,26 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 				AckWaitInSecs: sr.AckWaitInSecs,
 				DurableName:   sr.DurableName,
 				IsDurable:     isDurable,
+				StopSequence:  sr.StopSequence,
+				Exclusive:     sr.Exclusive,
+				Paused:        paused,
 			},
 			subject:     sr.Subject,
 			ackWait:     computeAckWait(sr.AckWaitInSecs),
 			acksPending: make(map[uint64]int64),
 			store:       c.store.Subs,
+			chStats:     &c.stats,
 		}
 
+		if sr.StopTimeDelta != 0 {
+			sub.StopTime = time.Now().UnixNano() - sr.StopTimeDelta
+		}
+		sub.ReplaySpeedPercent = sr.ReplaySpeedPercent
+		sub.MaxInFlightBytes = sr.MaxInFlightBytes
+		sub.DeliveryRate = sr.DeliveryRate
+		sub.DeliveryBurst = sr.DeliveryBurst
+		sub.PauseRedeliveries = pauseRedeliveries
+
 		if setStartPos {
 			// set the start sequence of the subscriber.
 			subStartTrace, err = s.setSubStartSequence(c, sub, sr)
@@ -3270,10 +4360,14 @@ func (s *StanServer) processSubscriptionRequest(m *nats.Msg) {
 		s.sendSubscriptionResponseErr(m.Reply, err)
 		return
 	}
-	if s.debug {
+	if s.isDebug() {
 		traceCtx := subStateTraceCtx{clientID: sr.ClientID, isNew: subIsNew, startTrace: subStartTrace}
 		traceSubState(s.log, sub, &traceCtx)
 	}
+	// New durables, or new durable queue groups.
+	if subIsNew && isDurable && setStartPos {
+		s.auditf(audit.EventDurableCreate, sr.ClientID, "%s", durableDetails(sr.Subject, sr.DurableName, sr.QGroup))
+	}
 
 	s.monMu.Lock()
 	s.numSubs++
@@ -3466,7 +4560,7 @@ func (s *StanServer) processAck(c *channel, sub *subState, sequence uint64) {
 
 	sub.Lock()
 
-	if s.trace {
+	if s.isTrace() {
 		s.log.Tracef("[Client:%s] Processing ack for subid=%d, subject=%s, seq=%d",
 			sub.ClientID, sub.ID, sub.subject, sequence)
 	}
@@ -3480,9 +4574,20 @@ func (s *StanServer) processAck(c *channel, sub *subState, sequence uint64) {
 		}
 		return
 	}
+	if s.msgTracer.enabled() {
+		s.msgTracer.seqEvent(sub.subject, sequence, msgTraceAcked, sub.traceDetails())
+	}
 
+	// Expiration time is 0 for messages recovered from the store
+	// that have not been redelivered yet, so delivery time is unknown.
+	if expTime := sub.acksPending[sequence]; expTime > 0 {
+		sub.acksCount++
+		sub.ackLatencyTotal += time.Now().UnixNano() - (expTime - int64(sub.ackWait))
+	}
 	delete(sub.acksPending, sequence)
-	if sub.stalled && int32(len(sub.acksPending)) < sub.MaxInFlight {
+	sub.clearPendingSize(sequence)
+	s.checkReplayEnd(c, sub)
+	if sub.stalled && int32(len(sub.acksPending)) < sub.MaxInFlight && !sub.inflightBytesFull() {
 		// For queue, we must not check the queue stalled count here. The queue
 		// as a whole may not be stalled, yet, if this sub was stalled, it is
 		// not now since the pending acks is below MaxInflight. The server should
@@ -3500,6 +4605,12 @@ func (s *StanServer) processAck(c *channel, sub *subState, sequence uint64) {
 			qs.stalledSubCount--
 		}
 	}
+	// A message that did not fit in any member of the queue group stalls
+	// only the member it was tried on, so the room freed by any member
+	// with a MaxInFlightBytes limit needs to trigger a new attempt.
+	if qs != nil && sub.MaxInFlightBytes > 0 {
+		stalled = true
+	}
 	sub.Unlock()
 	if qs != nil {
 		qs.Unlock()
@@ -3525,16 +4636,20 @@ func (s *StanServer) sendAvailableMessagesToQueue(c *channel, qs *queueState) {
 	}
 
 	qs.Lock()
-	if qs.newOnHold {
+	if qs.newOnHold || qs.paused {
 		qs.Unlock()
 		return
 	}
 	for nextSeq := qs.lastSent + 1; qs.stalledSubCount < len(qs.subs); nextSeq++ {
 		nextMsg := s.getNextMsg(c, &nextSeq, &qs.lastSent)
-		if nextMsg == nil {
+		if nextMsg == nil || !s.checkQueueDeliveryRate(c, qs) {
 			break
 		}
-		if _, sent, sendMore := s.sendMsgToQueueGroup(qs, nextMsg, honorMaxInFlight); !sent || !sendMore {
+		_, sent, sendMore := s.sendMsgToQueueGroup(qs, nextMsg, honorMaxInFlight)
+		if sent && qs.limiter != nil {
+			qs.limiter.take()
+		}
+		if !sent || !sendMore {
 			break
 		}
 	}
@@ -3544,15 +4659,42 @@ func (s *StanServer) sendAvailableMessagesToQueue(c *channel, qs *queueState) {
 // Send any messages that are ready to be sent that have been queued.
 func (s *StanServer) sendAvailableMessages(c *channel, sub *subState) {
 	sub.Lock()
-	for nextSeq := sub.LastSent + 1; !sub.stalled; nextSeq++ {
+	for nextSeq := sub.LastSent + 1; !sub.stalled && !sub.stopReached && !sub.Paused; nextSeq++ {
 		nextMsg := s.getNextMsg(c, &nextSeq, &sub.LastSent)
+		if sub.isPastStop(nextSeq, nextMsg) {
+			sub.stopReached = true
+			break
+		}
 		if nextMsg == nil {
 			break
 		}
-		if sent, sendMore := s.sendMsgToSub(sub, nextMsg, honorMaxInFlight); !sent || !sendMore {
+		var due int64
+		if sub.ReplaySpeedPercent > 0 {
+			if due = s.replayDueTime(c, sub, nextMsg); due == 0 {
+				break
+			}
+		}
+		if !s.checkDeliveryRate(c, sub) {
 			break
 		}
+		sent, sendMore := s.sendMsgToSub(sub, nextMsg, honorMaxInFlight)
+		if sent && due != 0 {
+			sub.replayMsgTime, sub.replayTime = nextMsg.Timestamp, due
+		}
+		if sent && sub.limiter != nil {
+			sub.limiter.take()
+		}
+		if !sent || !sendMore {
+			break
+		}
+	}
+	if sub.stalled && sub.ReplaySpeedPercent > 0 {
+		sub.replayStall = true
 	}
+	if !sub.stopReached && sub.stopTimer == nil && !sub.removed && sub.StopTime > time.Now().UnixNano() {
+		s.setupStopTimer(c, sub)
+	}
+	s.checkReplayEnd(c, sub)
 	sub.Unlock()
 }
 
@@ -3598,7 +4740,7 @@ func (s *StanServer) getNextMsg(c *channel, nextSeq, lastSent *uint64) *pb.MsgPr
 }
 
 // Setup the start position for the subscriber.
-func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.SubscriptionRequest) (string, error) {
+func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *spb.SubscriptionRequest) (string, error) {
 	sub.Lock()
 	defer sub.Unlock()
 
@@ -3609,16 +4751,16 @@ func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.Subsc
 	// lastSent stays at 0.
 
 	switch sr.StartPosition {
-	case pb.StartPosition_NewOnly:
+	case spb.StartPosition_NewOnly:
 		var err error
 		lastSent, err = c.store.Msgs.LastSequence()
 		if err != nil {
 			return "", err
 		}
-		if s.debug {
+		if s.isDebug() {
 			debugTrace = fmt.Sprintf("new-only, seq=%d", lastSent+1)
 		}
-	case pb.StartPosition_LastReceived:
+	case spb.StartPosition_LastReceived:
 		lastSeq, err := c.store.Msgs.LastSequence()
 		if err != nil {
 			return "", err
@@ -3626,10 +4768,10 @@ func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.Subsc
 		if lastSeq > 0 {
 			lastSent = lastSeq - 1
 		}
-		if s.debug {
+		if s.isDebug() {
 			debugTrace = fmt.Sprintf("last message, seq=%d", lastSent+1)
 		}
-	case pb.StartPosition_TimeDeltaStart:
+	case spb.StartPosition_TimeDeltaStart:
 		startTime := time.Now().UnixNano() - sr.StartTimeDelta
 		// If there is no message, seq will be 0.
 		seq, err := c.store.Msgs.GetSequenceFromTimestamp(startTime)
@@ -3642,10 +4784,10 @@ func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.Subsc
 			// so this would translate to "new only" semantic.
 			lastSent = seq - 1
 		}
-		if s.debug {
+		if s.isDebug() {
 			debugTrace = fmt.Sprintf("from time time='%v' seq=%d", time.Unix(0, startTime), lastSent+1)
 		}
-	case pb.StartPosition_SequenceStart:
+	case spb.StartPosition_SequenceStart:
 		// If there is no message, firstSeq and lastSeq will be equal to 0.
 		firstSeq, lastSeq, err := c.store.Msgs.FirstAndLastSequence()
 		if err != nil {
@@ -3663,10 +4805,10 @@ func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.Subsc
 			// sequence number.
 			lastSent = sr.StartSequence - 1
 		}
-		if s.debug {
+		if s.isDebug() {
 			debugTrace = fmt.Sprintf("from sequence, asked_seq=%d actual_seq=%d", sr.StartSequence, lastSent+1)
 		}
-	case pb.StartPosition_First:
+	case spb.StartPosition_First:
 		firstSeq, err := c.store.Msgs.FirstSequence()
 		if err != nil {
 			return "", err
@@ -3674,9 +4816,26 @@ func (s *StanServer) setSubStartSequence(c *channel, sub *subState, sr *pb.Subsc
 		if firstSeq > 0 {
 			lastSent = firstSeq - 1
 		}
-		if s.debug {
+		if s.isDebug() {
 			debugTrace = fmt.Sprintf("from beginning, seq=%d", lastSent+1)
 		}
+	case spb.StartPosition_LastN:
+		// If there is no message, firstSeq and lastSeq will be equal to 0.
+		firstSeq, lastSeq, err := c.store.Msgs.FirstAndLastSequence()
+		if err != nil {
+			return "", err
+		}
+		if lastSeq >= sr.StartLastN && lastSeq-sr.StartLastN+1 > firstSeq {
+			// That translates to sending the last StartLastN messages,
+			// or none (that is "new only") if StartLastN is 0.
+			lastSent = lastSeq - sr.StartLastN
+		} else if firstSeq > 0 {
+			// There are fewer messages, start with the first available.
+			lastSent = firstSeq - 1
+		}
+		if s.isDebug() {
+			debugTrace = fmt.Sprintf("last messages, asked_count=%d seq=%d", sr.StartLastN, lastSent+1)
+		}
 	}
 	sub.LastSent = lastSent
 	return debugTrace, nil
@@ -3708,13 +4867,67 @@ func (s *StanServer) State() State {
 	return s.state
 }
 
+// setDebugAndTrace sets the flags checked by isDebug and isTrace.
+func (s *StanServer) setDebugAndTrace(debug, trace bool) {
+	var d, t int32
+	if debug {
+		d = 1
+	}
+	if trace {
+		t = 1
+	}
+	atomic.StoreInt32(&s.debug, d)
+	atomic.StoreInt32(&s.trace, t)
+}
+
+// isDebug returns true if debug statements should be logged.
+func (s *StanServer) isDebug() bool {
+	return atomic.LoadInt32(&s.debug) == 1
+}
+
+// isTrace returns true if trace statements should be logged.
+func (s *StanServer) isTrace() bool {
+	return atomic.LoadInt32(&s.trace) == 1
+}
+
+// getClientHBOpts returns the client heartbeat interval, timeout and
+// fail count, which can be changed on configuration reload.
+func (s *StanServer) getClientHBOpts() (time.Duration, time.Duration, int) {
+	s.optsMu.RLock()
+	interval, timeout, failCount := s.opts.ClientHBInterval, s.opts.ClientHBTimeout, s.opts.ClientHBFailCount
+	s.optsMu.RUnlock()
+	return interval, timeout, failCount
+}
+
+// setState sets the server's state.
+// Server lock held on entry.
+func (s *StanServer) setState(state State) {
+	s.state = state
+	atomic.StoreInt64(&s.healthState, int64(state))
+}
+
+// setStoreError records (or clears if `err` is nil) an error returned
+// by the store, which is reported by the health endpoint.
+func (s *StanServer) setStoreError(err error) {
+	s.storeErrMu.Lock()
+	s.storeErr = err
+	s.storeErrMu.Unlock()
+}
+
+// getStoreError returns the last recorded store error, if any.
+func (s *StanServer) getStoreError() error {
+	s.storeErrMu.Lock()
+	defer s.storeErrMu.Unlock()
+	return s.storeErr
+}
+
 // setLastError sets the last fatal error that occurred. This is
 // used in case of an async error that cannot directly be reported
 // to the user.
 func (s *StanServer) setLastError(err error) {
 	s.mu.Lock()
 	s.lastError = err
-	s.state = Failed
+	s.setState(Failed)
 	s.mu.Unlock()
 	s.log.Fatalf("%v", err)
 }
@@ -3739,7 +4952,7 @@ func (s *StanServer) Shutdown() {
 	// Allows Shutdown() to be idempotent
 	s.shutdown = true
 	// Change the state too
-	s.state = Shutdown
+	s.setState(Shutdown)
 
 	// We need to make sure that the storeIOLoop returns before
 	// closing the Store
@@ -3774,6 +4987,9 @@ func (s *StanServer) Shutdown() {
 	}
 	s.mu.Unlock()
 
+	// Tenants use our NATS Server, so shut them down first.
+	s.shutdownTenants()
+
 	// Make sure the StoreIOLoop returns before closing the Store
 	if waitForIOStoreLoop {
 		s.ioChannelWG.Wait()
@@ -3800,4 +5016,8 @@ func (s *StanServer) Shutdown() {
 
 	// Wait for go-routines to return
 	s.wg.Wait()
+
+	if s.auditLog != nil {
+		s.auditLog.Close()
+	}
 }
@@ -9,7 +9,8 @@ import (
 
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
-	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nuid"
 )
 
 func TestClientIDIsValid(t *testing.T) {
@@ -31,14 +32,14 @@ func TestClientIDIsValid(t *testing.T) {
 		"idWithLotsOfNotAllowedCharacters!@#$%^&*()"}
 
 	for _, cID := range invalidClientIDs {
-		req := &pb.ConnectRequest{ClientID: cID, HeartbeatInbox: "hbInbox"}
+		req := &spb.ConnectRequest{ClientID: cID, HeartbeatInbox: "hbInbox"}
 		b, _ := req.Marshal()
 
 		resp, err := nc.Request(connSubj, b, time.Second)
 		if err != nil {
 			t.Fatalf("Unexpected error on publishing request: %v", err)
 		}
-		r := &pb.ConnectResponse{}
+		r := &spb.ConnectResponse{}
 		err = r.Unmarshal(resp.Data)
 		if err != nil {
 			t.Fatalf("Unexpected response object: %v", err)
@@ -51,14 +52,14 @@ func TestClientIDIsValid(t *testing.T) {
 	validClientIDs := []string{"id", "id_with_underscores", "id-with-hypens"}
 
 	for _, cID := range validClientIDs {
-		req := &pb.ConnectRequest{ClientID: cID, HeartbeatInbox: "hbInbox"}
+		req := &spb.ConnectRequest{ClientID: cID, HeartbeatInbox: "hbInbox"}
 		b, _ := req.Marshal()
 
 		resp, err := nc.Request(connSubj, b, time.Second)
 		if err != nil {
 			t.Fatalf("Unexpected error on publishing request: %v", err)
 		}
-		r := &pb.ConnectResponse{}
+		r := &spb.ConnectResponse{}
 		err = r.Unmarshal(resp.Data)
 		if err != nil {
 			t.Fatalf("Unexpected response object: %v", err)
@@ -472,3 +473,166 @@ func TestPersistentStoreCheckClientHealthAfterRestart(t *testing.T) {
 	// Both clients should quickly timed-out
 	waitForNumClients(t, s, 0)
 }
+
+func TestCheckClientHealthOnMissedPings(t *testing.T) {
+	opts := GetDefaultOptions()
+	// Make sure that the client would not be detected by the
+	// regular heartbeats within the duration of the test.
+	opts.ClientHBInterval = time.Hour
+	opts.ClientHBTimeout = 100 * time.Millisecond
+	opts.ClientHBFailCount = 10
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	sc, err := stan.Connect(clusterName, clientName, stan.NatsConn(nc))
+	if err != nil {
+		t.Fatalf("Expected to connect correctly, got err %v", err)
+	}
+	defer sc.Close()
+
+	c := s.clients.lookup(clientName)
+	c.RLock()
+	hbInbox := c.info.HbInbox
+	c.RUnlock()
+	ping, _ := (&spb.Ping{ClientID: clientName, HeartbeatInbox: hbInbox}).Marshal()
+	sendPings := func(count int) {
+		for i := 0; i < count; i++ {
+			if _, err := nc.Request(s.info.Ping, ping, time.Second); err != nil {
+				t.Fatalf("Unexpected error on ping: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	// A client that keeps pinging is left alone.
+	sendPings(10)
+	checkClients(t, s, 1)
+	// A client that stops pinging, but still responds to heartbeats,
+	// is kept too.
+	time.Sleep(300 * time.Millisecond)
+	checkClients(t, s, 1)
+
+	// Once the client has stopped pinging and its NATS connection is gone,
+	// it should be closed after a single failed heartbeat.
+	sendPings(3)
+	nc.Close()
+	waitForNumClients(t, s, 0)
+}
+
+func TestClientPings(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	cr := rawConnect(t, s, nc, clientName, nil)
+	if cr.PingRequests == "" || cr.Capabilities&uint32(spb.Capability_CapPing) == 0 {
+		t.Fatalf("Pings should have been negotiated: %v", cr)
+	}
+
+	c := s.clients.lookup(clientName)
+	c.RLock()
+	hbInbox := c.info.HbInbox
+	c.RUnlock()
+
+	ping := func(req *spb.Ping, expected error) {
+		resp := &spb.PingResponse{}
+		sendRawRequest(t, nc, cr.PingRequests, req, resp)
+		if (expected == nil && resp.Error != "") || (expected != nil && resp.Error != expected.Error()) {
+			stackFatalf(t, "Ping %v: expected error %v, got %q", req, expected, resp.Error)
+		}
+	}
+	ping(&spb.Ping{ClientID: clientName, HeartbeatInbox: hbInbox}, nil)
+	ping(&spb.Ping{}, ErrInvalidPingReq)
+	ping(&spb.Ping{ClientID: "unknown", HeartbeatInbox: hbInbox}, ErrUnknownClient)
+	ping(&spb.Ping{ClientID: clientName, HeartbeatInbox: "other"}, ErrClientReplaced)
+
+	// Once the server closes the client, its pings are rejected so that
+	// the client knows that its connection is lost.
+	s.closeClient(clientName)
+	ping(&spb.Ping{ClientID: clientName, HeartbeatInbox: hbInbox}, ErrUnknownClient)
+}
+
+func TestClientProtocolNegotiation(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+
+	allCaps := uint32(serverCapabilities)
+	for _, test := range []struct {
+		clientID     string
+		protocol     spb.Protocol
+		capabilities uint32
+		expectedProt spb.Protocol
+		expectedCaps uint32
+	}{
+		// Clients of the initial protocol do not get capabilities, even
+		// if they send some.
+		{"old", spb.Protocol_ProtocolZero, allCaps, spb.Protocol_ProtocolZero, 0},
+		{"some", spb.Protocol_ProtocolOne, uint32(spb.Capability_CapPing), spb.Protocol_ProtocolOne, uint32(spb.Capability_CapPing)},
+		// Versions and capabilities unknown to the server are ignored.
+		{"newer", spb.Protocol_ProtocolOne + 1, 0xFFFF, spb.Protocol_ProtocolOne, allCaps},
+	} {
+		req := &spb.ConnectRequest{
+			ClientID:       test.clientID,
+			HeartbeatInbox: nats.NewInbox(),
+			Protocol:       test.protocol,
+			Capabilities:   test.capabilities,
+		}
+		b, _ := req.Marshal()
+		reply, err := nc.Request(s.info.Discovery, b, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error on connect request: %v", err)
+		}
+		cr := &spb.ConnectResponse{}
+		if err := cr.Unmarshal(reply.Data); err != nil || cr.Error != "" {
+			t.Fatalf("Unexpected connect response: %v - %v", cr, err)
+		}
+		if cr.Protocol != test.expectedProt || cr.Capabilities != test.expectedCaps {
+			t.Fatalf("Client %q: expected protocol %v and capabilities %v, got %v and %v",
+				test.clientID, test.expectedProt, test.expectedCaps, cr.Protocol, cr.Capabilities)
+		}
+		// Subjects of unsupported features are not sent.
+		if hasBatch := test.expectedCaps&uint32(spb.Capability_CapPubBatch) != 0; hasBatch != (cr.PubBatchRequests != "") {
+			t.Fatalf("Client %q: unexpected batch publish subject %q", test.clientID, cr.PubBatchRequests)
+		}
+		if hasPing := test.expectedCaps&uint32(spb.Capability_CapPing) != 0; hasPing != (cr.PingRequests != "") {
+			t.Fatalf("Client %q: unexpected ping subject %q", test.clientID, cr.PingRequests)
+		}
+		c := s.clients.lookup(test.clientID)
+		c.RLock()
+		prot, caps := c.info.Protocol, c.info.Capabilities
+		c.RUnlock()
+		if prot != int32(test.expectedProt) || caps != test.expectedCaps {
+			t.Fatalf("Client %q: recorded protocol %v and capabilities %v", test.clientID, prot, caps)
+		}
+
+		// The sequence of the stored message is set in the PubAck
+		// only for clients that support it.
+		pm := &spb.PubMsg{ClientID: test.clientID, Guid: nuid.Next(), Subject: "foo"}
+		b, _ = pm.Marshal()
+		reply, err = nc.Request(cr.PubPrefix+".foo", b, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+		pa := &spb.PubAck{}
+		if err := pa.Unmarshal(reply.Data); err != nil || pa.Error != "" {
+			t.Fatalf("Unexpected publish ack: %v - %v", pa, err)
+		}
+		if hasSeq := test.expectedCaps&uint32(spb.Capability_CapPubAckSequence) != 0; hasSeq != (pa.Sequence != 0) {
+			t.Fatalf("Client %q: unexpected sequence in ack: %v", test.clientID, pa.Sequence)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/spb"
 )
 
 func testStalledDelivery(t *testing.T, typeSub string) {
@@ -176,3 +177,313 @@ func TestPersistentStoreAutomaticDeliveryOnRestart(t *testing.T) {
 	s2.Shutdown()
 	s2 = nil
 }
+
+func TestReplayAtOriginalRate(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	gaps := []time.Duration{0, 200 * time.Millisecond, 100 * time.Millisecond, 300 * time.Millisecond}
+	for _, gap := range gaps {
+		time.Sleep(gap)
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, "sub", nil)
+
+	ackDelay := 150 * time.Millisecond
+	check := func(speedPercent uint32, stall bool) {
+		req := &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo",
+			StartPosition: spb.StartPosition_First, ReplaySpeedPercent: speedPercent}
+		if stall {
+			req.MaxInFlight = 1
+		}
+		rs, err := rawSubscribe(t, s, nc, req)
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		received := make(chan time.Time, len(gaps))
+		go func() {
+			for range gaps {
+				m := <-rs.msgs
+				received <- time.Now()
+				// With a MaxInflight of 1, ack the second message late,
+				// which delays the delivery of the third.
+				if stall && m.Sequence == 2 {
+					time.Sleep(ackDelay)
+				}
+				rs.ack(t, m)
+			}
+		}()
+		prev := <-received
+		for i, gap := range gaps[1:] {
+			select {
+			case now := <-received:
+				expected := gap * 100 / time.Duration(speedPercent)
+				if stall && i == 1 {
+					// The gap applies from the time the message was acked.
+					expected += ackDelay
+				}
+				if got := now.Sub(prev); got < expected-10*time.Millisecond || got > expected+100*time.Millisecond {
+					t.Fatalf("Speed %v%%: expected message %v to be delivered after %v, got %v", speedPercent, i+2, expected, got)
+				}
+				prev = now
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Did not get message %v", i+2)
+			}
+		}
+	}
+	check(100, false)
+	check(200, false)
+	check(100, true)
+
+	if _, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo", QGroup: "group",
+		ReplaySpeedPercent: 100}); err == nil || err.Error() != ErrInvalidReplay.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrInvalidReplay, err)
+	}
+}
+
+func TestMaxInflightBytes(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	for _, size := range []int{4, 4, 4, 20, 1} {
+		if err := sc.Publish("foo", make([]byte, size)); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, "sub", nil)
+
+	rs, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo",
+		StartPosition: spb.StartPosition_First, MaxInFlightBytes: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	check := func(expected ...uint64) []*spb.MsgProto {
+		var got []*spb.MsgProto
+		for _, seq := range expected {
+			if m := rs.next(t); m.Sequence != seq {
+				stackFatalf(t, "Expected message %v, got %v", seq, m.Sequence)
+			} else {
+				got = append(got, m)
+			}
+		}
+		select {
+		case m := <-rs.msgs:
+			stackFatalf(t, "Unexpected message %v", m.Sequence)
+		case <-time.After(50 * time.Millisecond):
+		}
+		return got
+	}
+	// The third message would exceed the limit.
+	got := check(1, 2)
+	rs.ack(t, got[0])
+	got = append(got[1:], check(3)...)
+	// The fourth message is larger than the limit, so it is delivered
+	// only once nothing else is inflight.
+	rs.ack(t, got[0])
+	check()
+	rs.ack(t, got[1])
+	got = check(4)
+	sub := checkSubs(t, s, "sub", 1)[0]
+	sub.RLock()
+	pendingBytes := sub.pendingBytes
+	sub.RUnlock()
+	if pendingBytes != 20 {
+		t.Fatalf("Expected 20 bytes inflight, got %v", pendingBytes)
+	}
+	rs.ack(t, got[0])
+	check(5)
+
+	// Queue members get messages only if they have room for them.
+	var members []*rawSubscription
+	for i := 0; i < 2; i++ {
+		member, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "bar",
+			QGroup: "group", MaxInFlightBytes: 10})
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		members = append(members, member)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sc.Publish("bar", make([]byte, 6)); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	// Returns the next message received by a member, and this member.
+	nextQueueMsg := func() (*spb.MsgProto, *rawSubscription) {
+		select {
+		case m := <-members[0].msgs:
+			return m, members[0]
+		case m := <-members[1].msgs:
+			return m, members[1]
+		case <-time.After(2 * time.Second):
+			stackFatalf(t, "Did not get the next message")
+		}
+		return nil, nil
+	}
+	m1, member1 := nextQueueMsg()
+	m2, member2 := nextQueueMsg()
+	if m2.Sequence < m1.Sequence {
+		m1, m2, member2 = m2, m1, member1
+	}
+	if m1.Sequence != 1 || m2.Sequence != 2 {
+		t.Fatalf("Expected messages 1 and 2, got %v and %v", m1.Sequence, m2.Sequence)
+	}
+	// Each member has one message inflight, so the third message is
+	// delivered once one of them is acknowledged.
+	member2.ack(t, m2)
+	if m, _ := nextQueueMsg(); m.Sequence != 3 {
+		t.Fatalf("Expected message 3, got %v", m.Sequence)
+	}
+
+	if _, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo",
+		MaxInFlightBytes: -1}); err == nil || err.Error() != ErrInvalidMaxInflightBytes.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrInvalidMaxInflightBytes, err)
+	}
+}
+
+func TestDeliveryRate(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	for i := 0; i < 6; i++ {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, "sub", nil)
+	subscribe := func(req *spb.SubscriptionRequest) (*rawSubscription, error) {
+		req.ClientID = "sub"
+		return rawSubscribe(t, s, nc, req)
+	}
+	// waitForMsgs returns the time at which each of the `count` messages
+	// has been received by any of `subs`. Redeliveries are ignored.
+	waitForMsgs := func(count int, subs ...*rawSubscription) []time.Time {
+		var times []time.Time
+		deadline := time.Now().Add(2 * time.Second)
+		for len(times) < count {
+			if time.Now().After(deadline) {
+				stackFatalf(t, "Did not get message %v", len(times)+1)
+			}
+			for _, rs := range subs {
+				select {
+				case m := <-rs.msgs:
+					if !m.Redelivered {
+						times = append(times, time.Now())
+					}
+				default:
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return times
+	}
+	start := time.Now()
+	rs, err := subscribe(&spb.SubscriptionRequest{Subject: "foo", StartPosition: spb.StartPosition_First,
+		DeliveryRate: 20, DeliveryBurst: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	times := waitForMsgs(2, rs)
+	// The first 2 messages are delivered right away.
+	if d := times[1].Sub(start); d > 40*time.Millisecond {
+		t.Fatalf("Burst should have been delivered right away, took %v", d)
+	}
+	// Messages over the rate are not sent, so not pending acknowledgment.
+	sub := checkSubs(t, s, "sub", 1)[0]
+	sub.RLock()
+	pending := len(sub.acksPending)
+	sub.RUnlock()
+	if pending > 3 {
+		t.Fatalf("Expected at most 3 messages pending, got %v", pending)
+	}
+	// Then one message every 50ms.
+	times = append(times, waitForMsgs(4, rs)...)
+	if d := times[5].Sub(times[1]); d < 180*time.Millisecond {
+		t.Fatalf("Messages delivered too fast: %v", d)
+	}
+
+	// The rate applies to the queue group as a whole.
+	var members []*rawSubscription
+	for i := 0; i < 2; i++ {
+		member, err := subscribe(&spb.SubscriptionRequest{Subject: "bar", QGroup: "group",
+			DeliveryRate: 20, DeliveryBurst: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		members = append(members, member)
+	}
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		if err := sc.Publish("bar", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	times = waitForMsgs(4, members...)
+	if d := times[3].Sub(start); d < 140*time.Millisecond {
+		t.Fatalf("Messages delivered too fast: %v", d)
+	}
+
+	// Members can't change the rate of the group.
+	for _, rate := range [][2]uint32{{10, 1}, {20, 2}} {
+		if _, err := subscribe(&spb.SubscriptionRequest{Subject: "bar", QGroup: "group",
+			DeliveryRate: rate[0], DeliveryBurst: rate[1]}); err == nil || err.Error() != ErrQueueDeliveryRate.Error() {
+			t.Fatalf("Expected error %v, got %v", ErrQueueDeliveryRate, err)
+		}
+	}
+	ss := s.channels.get("bar").ss
+	ss.RLock()
+	qs := ss.qsubs["group"]
+	ss.RUnlock()
+	qs.RLock()
+	rate, burst := qs.rate, qs.burst
+	qs.RUnlock()
+	if rate != 20 || burst != 1 {
+		t.Fatalf("Expected rate of the group to be 20/1, got %v/%v", rate, burst)
+	}
+
+	// The rate of a durable group is kept when its last member leaves.
+	dur, err := subscribe(&spb.SubscriptionRequest{Subject: "bar", QGroup: "dgroup", DurableName: "dur",
+		DeliveryRate: 20, DeliveryBurst: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	dur.close(t)
+	if _, err := subscribe(&spb.SubscriptionRequest{Subject: "bar", QGroup: "dgroup",
+		DurableName: "dur"}); err == nil || err.Error() != ErrQueueDeliveryRate.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrQueueDeliveryRate, err)
+	}
+	if _, err := subscribe(&spb.SubscriptionRequest{Subject: "bar", QGroup: "dgroup", DurableName: "dur",
+		DeliveryRate: 20, DeliveryBurst: 1}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+
+	if _, err := subscribe(&spb.SubscriptionRequest{Subject: "foo", DeliveryBurst: 1}); err == nil || err.Error() != ErrInvalidDeliveryRate.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrInvalidDeliveryRate, err)
+	}
+}
@@ -3,6 +3,7 @@ package server
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
 	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/stores"
 )
 
 func TestDurableRestartWithMaxInflight(t *testing.T) {
@@ -104,7 +107,7 @@ func TestDurableCanReconnect(t *testing.T) {
 	cb := func(_ *stan.Msg) {}
 
 	durName := "mydur"
-	sr := &pb.SubscriptionRequest{
+	sr := &spb.SubscriptionRequest{
 		ClientID:    clientName,
 		Subject:     "foo",
 		DurableName: durName,
@@ -169,7 +172,7 @@ func TestPersistentStoreRecoveredDurableCanReconnect(t *testing.T) {
 	cb := func(_ *stan.Msg) {}
 
 	durName := "mydur"
-	sr := &pb.SubscriptionRequest{
+	sr := &spb.SubscriptionRequest{
 		ClientID:    clientName,
 		Subject:     "foo",
 		DurableName: durName,
@@ -256,7 +259,7 @@ func TestDurableAckedMsgNotRedelivered(t *testing.T) {
 	}
 
 	durName := "mydur"
-	sr := &pb.SubscriptionRequest{
+	sr := &spb.SubscriptionRequest{
 		ClientID:    clientName,
 		Subject:     "foo",
 		DurableName: durName,
@@ -355,7 +358,7 @@ func TestDurableRemovedOnUnsubscribe(t *testing.T) {
 	cb := func(_ *stan.Msg) {}
 
 	durName := "mydur"
-	sr := &pb.SubscriptionRequest{
+	sr := &spb.SubscriptionRequest{
 		ClientID:    clientName,
 		Subject:     "foo",
 		DurableName: durName,
@@ -892,3 +895,290 @@ func TestPersistentStoreDurableClosedStatusOnRestart(t *testing.T) {
 	// Restart one last time
 	dur = restartDurable()
 }
+
+func TestPersistentStoreDurableSeek(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+	defer os.Remove(credsFile)
+
+	writeCredentials(t, "admin_token: \"admin\"")
+	opts := getTestDefaultOptsForPersistentStore()
+	opts.CredentialsFile = credsFile
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	seek := func(req *spb.SeekRequest, expectedErr error) uint64 {
+		if req.AdminToken == "" {
+			req.AdminToken = "admin"
+		}
+		b, _ := req.Marshal()
+		reply, err := nc.Request(adminSeekSubject(clusterName), b, 2*time.Second)
+		if err != nil {
+			stackFatalf(t, "Unexpected error on seek request: %v", err)
+		}
+		resp := &spb.SeekResponse{}
+		resp.Unmarshal(reply.Data)
+		if (expectedErr == nil && resp.Error != "") || (expectedErr != nil && resp.Error != expectedErr.Error()) {
+			stackFatalf(t, "Expected error %v, got %q", expectedErr, resp.Error)
+		}
+		return resp.Sequence
+	}
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	var seekTime int64
+	for i := 0; i < 5; i++ {
+		if i == 2 {
+			time.Sleep(10 * time.Millisecond)
+			seekTime = time.Now().UnixNano()
+		}
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	msgs := make(chan *stan.Msg, 10)
+	cb := func(m *stan.Msg) { msgs <- m }
+	dur, err := sc.Subscribe("foo", cb, stan.DurableName("dur"), stan.DeliverAllAvailable(),
+		stan.SetManualAckMode())
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// Ack the first 3 messages only, and close the durable.
+	for i := 0; i < 5; i++ {
+		if m := <-msgs; m.Sequence <= 3 {
+			m.Ack()
+		}
+	}
+	sub := checkSubs(t, s, clientName, 1)[0]
+	waitForCount(t, 2, func() (string, int) {
+		sub.RLock()
+		defer sub.RUnlock()
+		return "ack pending", len(sub.acksPending)
+	})
+	dur.Close()
+	waitForNumSubs(t, s, clientName, 0)
+
+	seek(&spb.SeekRequest{Channel: "foo", DurableName: "dur", ClientID: clientName, Sequence: 1,
+		AdminToken: "wrong"}, ErrAdminNotAuthorized)
+	seek(&spb.SeekRequest{Channel: "foo", DurableName: "dur"}, ErrInvalidSeekReq)
+	seek(&spb.SeekRequest{Channel: "foo", DurableName: "dur", ClientID: "other", Sequence: 1}, ErrUnknownDurable)
+	// Move the offline durable to the third message, clearing the pending ones.
+	if seq := seek(&spb.SeekRequest{Channel: "foo", DurableName: "dur", ClientID: clientName,
+		Time: seekTime, ClearPending: true}, nil); seq != 3 {
+		t.Fatalf("Expected next sequence 3, got %v", seq)
+	}
+
+	// The new position is persisted.
+	sc.Close()
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+	sc = NewDefaultConnection(t)
+	defer sc.Close()
+
+	checkMsgs := func(expected ...uint64) {
+		for _, seq := range expected {
+			select {
+			case m := <-msgs:
+				if m.Sequence != seq || m.Redelivered {
+					stackFatalf(t, "Expected message %v, got %v (redelivered=%v)", seq, m.Sequence, m.Redelivered)
+				}
+			case <-time.After(2 * time.Second):
+				stackFatalf(t, "Did not get message %v", seq)
+			}
+		}
+	}
+	if _, err := sc.Subscribe("foo", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	checkMsgs(3, 4, 5)
+
+	// Move an online durable queue group back.
+	if _, err := sc.QueueSubscribe("foo", "group", cb, stan.DurableName("dur"),
+		stan.DeliverAllAvailable()); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	checkMsgs(1, 2, 3, 4, 5)
+	if seq := seek(&spb.SeekRequest{Channel: "foo", DurableName: "dur", QGroup: "group", Sequence: 4}, nil); seq != 4 {
+		t.Fatalf("Expected next sequence 4, got %v", seq)
+	}
+	checkMsgs(4, 5)
+}
+
+func TestPersistentStoreDurableQueuePause(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+	defer os.Remove(credsFile)
+
+	writeCredentials(t, "admin_token: \"admin\"")
+	opts := getTestDefaultOptsForPersistentStore()
+	opts.CredentialsFile = credsFile
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	pause := func(req *spb.PauseRequest, expectedErr error) {
+		if req.AdminToken == "" {
+			req.AdminToken = "admin"
+		}
+		b, _ := req.Marshal()
+		reply, err := nc.Request(adminPauseSubject(clusterName), b, 2*time.Second)
+		if err != nil {
+			stackFatalf(t, "Unexpected error on pause request: %v", err)
+		}
+		resp := &spb.PauseResponse{}
+		resp.Unmarshal(reply.Data)
+		if (expectedErr == nil && resp.Error != "") || (expectedErr != nil && resp.Error != expectedErr.Error()) {
+			stackFatalf(t, "Expected error %v, got %q", expectedErr, resp.Error)
+		}
+	}
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	msgs := make(chan *stan.Msg, 10)
+	cb := func(m *stan.Msg) { msgs <- m }
+	if _, err := sc.QueueSubscribe("foo", "group", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	pause(&spb.PauseRequest{Channel: "foo", DurableName: "dur", QGroup: "group", Pause: true,
+		AdminToken: "wrong"}, ErrAdminNotAuthorized)
+	pause(&spb.PauseRequest{Channel: "foo", DurableName: "dur", Pause: true}, ErrInvalidPauseReq)
+	pause(&spb.PauseRequest{Channel: "foo", DurableName: "dur", QGroup: "other", Pause: true}, ErrUnknownDurable)
+	pause(&spb.PauseRequest{Channel: "foo", DurableName: "dur", QGroup: "group", Pause: true}, nil)
+	if err := sc.Publish("foo", []byte("msg")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	select {
+	case m := <-msgs:
+		t.Fatalf("Unexpected message %v", m.Sequence)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The paused state survives a restart, and applies to new members.
+	sc.Close()
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+	sc = NewDefaultConnection(t)
+	defer sc.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := sc.QueueSubscribe("foo", "group", cb, stan.DurableName("dur")); err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+	}
+	for _, sub := range checkSubs(t, s, clientName, 2) {
+		sub.RLock()
+		paused := sub.Paused
+		sub.RUnlock()
+		if !paused {
+			t.Fatal("Queue member should be paused")
+		}
+	}
+	select {
+	case m := <-msgs:
+		t.Fatalf("Unexpected message %v", m.Sequence)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	pause(&spb.PauseRequest{Channel: "foo", DurableName: "dur", QGroup: "group"}, nil)
+	select {
+	case m := <-msgs:
+		if m.Sequence != 1 {
+			t.Fatalf("Expected message 1, got %v", m.Sequence)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Did not get message after resume")
+	}
+}
+
+func TestPersistentStoreDurableInactivity(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+
+	opts := getTestDefaultOptsForPersistentStore()
+	opts.MaxDurableInactivity = 250 * time.Millisecond
+	// Durables on "bar" never expire.
+	opts.AddPerChannel("bar", &stores.ChannelLimits{SubStoreLimits: stores.SubStoreLimits{MaxDurableInactivity: -1}})
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	// Returns the number of durables and durable queue groups on `channel`.
+	countDurables := func(channel string) (string, int) {
+		c := s.channels.get(channel)
+		if c == nil {
+			return "durables", 0
+		}
+		c.ss.RLock()
+		defer c.ss.RUnlock()
+		return "durables", len(c.ss.durables) + len(c.ss.qsubs)
+	}
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	cb := func(_ *stan.Msg) {}
+	subscribe := func(channel string) {
+		sub, err := sc.Subscribe(channel, cb, stan.DurableName("dur"))
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		qsub, err := sc.QueueSubscribe(channel, "group", cb, stan.DurableName("dur"))
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		if err := sub.Close(); err != nil {
+			t.Fatalf("Unexpected error on close: %v", err)
+		}
+		if err := qsub.Close(); err != nil {
+			t.Fatalf("Unexpected error on close: %v", err)
+		}
+	}
+	subscribe("foo")
+	subscribe("bar")
+	// Online durables don't expire.
+	if _, err := sc.Subscribe("baz", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	waitForCount(t, 0, func() (string, int) { return countDurables("foo") })
+	if _, n := countDurables("bar"); n != 2 {
+		t.Fatalf("Expected 2 durables on bar, got %v", n)
+	}
+	if _, n := countDurables("baz"); n != 1 {
+		t.Fatalf("Expected 1 durable on baz, got %v", n)
+	}
+
+	// Durables that are resumed before the limit is reached are kept,
+	// and their offline time is recorded in the store, so that it
+	// survives a restart.
+	subscribe("foo")
+	if _, n := countDurables("foo"); n != 2 {
+		t.Fatalf("Expected 2 durables on foo, got %v", n)
+	}
+	sc.Close()
+	s.Shutdown()
+	time.Sleep(300 * time.Millisecond)
+	s = runServerWithOpts(t, opts, nil)
+	start := time.Now()
+	waitForCount(t, 0, func() (string, int) { return countDurables("foo") })
+	if dur := time.Since(start); dur >= opts.MaxDurableInactivity {
+		t.Fatalf("Durables should have expired right after the restart, took %v", dur)
+	}
+	if _, n := countDurables("bar"); n != 2 {
+		t.Fatalf("Expected 2 durables on bar, got %v", n)
+	}
+
+	// An expired durable is removed from the store.
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+	if _, n := countDurables("foo"); n != 0 {
+		t.Fatalf("Expected no durable on foo, got %v", n)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/nats-streaming-server/spb"
+)
+
+// checkExclusiveMsgs checks that the messages received by `rs` are, in
+// order, those with sequence in `expected`, and that no other message
+// is received. Returns the received messages.
+func checkExclusiveMsgs(t *testing.T, rs *rawSubscription, expected ...uint64) []*spb.MsgProto {
+	var msgs []*spb.MsgProto
+	for _, seq := range expected {
+		select {
+		case m := <-rs.msgs:
+			if m.Sequence != seq {
+				stackFatalf(t, "Expected message %v, got %v", seq, m.Sequence)
+			}
+			msgs = append(msgs, m)
+		case <-time.After(2 * time.Second):
+			stackFatalf(t, "Did not get message %v", seq)
+		}
+	}
+	select {
+	case m := <-rs.msgs:
+		stackFatalf(t, "Unexpected message %v", m.Sequence)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return msgs
+}
+
+// subscribeExclusive creates an exclusive durable for a new connection
+// with client ID `clientID`.
+func subscribeExclusive(t *testing.T, s *StanServer, clientID string) (*nats.Conn, *rawSubscription) {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		stackFatalf(t, "Unexpected error on connect: %v", err)
+	}
+	if cr := rawConnect(t, s, nc, clientID, nil); cr.Error != "" {
+		nc.Close()
+		stackFatalf(t, "Expected to connect correctly, got err %v", cr.Error)
+	}
+	rs, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: clientID, Subject: "foo",
+		DurableName: "dur", Exclusive: true, StartPosition: spb.StartPosition_First, AckWaitInSecs: 3600})
+	if err != nil {
+		nc.Close()
+		stackFatalf(t, "Unexpected error on subscribe: %v", err)
+	}
+	return nc, rs
+}
+
+// ackAndWait acks `m` and waits for the durable of client `clientID` to
+// have `pending` messages pending acknowledgment.
+func ackAndWait(t *testing.T, s *StanServer, clientID string, rs *rawSubscription, m *spb.MsgProto, pending int) {
+	rs.ack(t, m)
+	sub := checkSubs(t, s, clientID, 1)[0]
+	waitForCount(t, pending, func() (string, int) {
+		sub.RLock()
+		defer sub.RUnlock()
+		return "ack pending", len(sub.acksPending)
+	})
+}
+
+func TestExclusiveDurable(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	publish := func() {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			stackFatalf(t, "Unexpected error on publish: %v", err)
+		}
+	}
+
+	nc1, rs1 := subscribeExclusive(t, s, "c1")
+	defer nc1.Close()
+	nc2, rs2 := subscribeExclusive(t, s, "c2")
+	defer nc2.Close()
+	nc3, rs3 := subscribeExclusive(t, s, "c3")
+	defer nc3.Close()
+
+	for i := 0; i < 3; i++ {
+		publish()
+	}
+	// Only the first instance receives messages.
+	msgs := checkExclusiveMsgs(t, rs1, 1, 2, 3)
+	checkExclusiveMsgs(t, rs2)
+	checkExclusiveMsgs(t, rs3)
+	ss := s.channels.get("foo").ss
+	ss.RLock()
+	numDurables, numStandbys := len(ss.durables), len(ss.standbys[exclusiveDurableKey("foo", "dur")])
+	ss.RUnlock()
+	if numDurables != 1 || numStandbys != 2 {
+		t.Fatalf("Expected 1 durable and 2 standbys, got %v and %v", numDurables, numStandbys)
+	}
+
+	// Ack only the first message and close: the next standby takes over
+	// with the pending messages.
+	ackAndWait(t, s, "c1", rs1, msgs[0], 2)
+	rs1.close(t)
+	msgs = checkExclusiveMsgs(t, rs2, 2, 3)
+	checkExclusiveMsgs(t, rs3)
+
+	// Unsubscribing the active instance does not delete the durable since
+	// there is a standby.
+	ackAndWait(t, s, "c2", rs2, msgs[0], 1)
+	rs2.unsubscribe(t)
+	checkExclusiveMsgs(t, rs3, 3)
+
+	// A new instance waits, and takes over when the connection of the
+	// active one is closed.
+	nc4, rs4 := subscribeExclusive(t, s, "c4")
+	defer nc4.Close()
+	publish()
+	checkExclusiveMsgs(t, rs3, 4)
+	checkExclusiveMsgs(t, rs4)
+	rawClose(t, s, nc3, "c3")
+	checkExclusiveMsgs(t, rs4, 3, 4)
+
+	// Once the last instance unsubscribes, the durable is deleted.
+	rs4.unsubscribe(t)
+	ss.RLock()
+	numDurables, numStandbys = len(ss.durables), len(ss.standbys)
+	ss.RUnlock()
+	if numDurables != 0 || numStandbys != 0 {
+		t.Fatalf("Expected no durable and standby, got %v and %v", numDurables, numStandbys)
+	}
+}
+
+func TestExclusiveDurableInvalid(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, clientName, nil)
+
+	subscribe := func(req *spb.SubscriptionRequest) error {
+		req.ClientID = clientName
+		_, err := rawSubscribe(t, s, nc, req)
+		return err
+	}
+	for _, test := range []struct {
+		name string
+		req  *spb.SubscriptionRequest
+	}{
+		{"not durable", &spb.SubscriptionRequest{Subject: "foo", Exclusive: true}},
+		{"queue", &spb.SubscriptionRequest{Subject: "foo", QGroup: "group", DurableName: "dur", Exclusive: true}},
+		{"wildcard", &spb.SubscriptionRequest{Subject: "foo.*", DurableName: "dur", Exclusive: true}},
+	} {
+		if err := subscribe(test.req); err == nil || err.Error() != ErrInvalidExclusive.Error() {
+			t.Fatalf("%s: expected error %v, got %v", test.name, ErrInvalidExclusive, err)
+		}
+	}
+	// An exclusive durable is different from a regular durable with the
+	// same name.
+	if err := subscribe(&spb.SubscriptionRequest{Subject: "foo", DurableName: "dur"}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := subscribe(&spb.SubscriptionRequest{Subject: "foo", DurableName: "dur", Exclusive: true}); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := subscribe(&spb.SubscriptionRequest{Subject: "foo", DurableName: "dur"}); err == nil || err.Error() != ErrDupDurable.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrDupDurable, err)
+	}
+}
+
+func TestPersistentStoreExclusiveDurableRecovery(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+
+	opts := getTestDefaultOptsForPersistentStore()
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	nc1, rs1 := subscribeExclusive(t, s, "c1")
+	defer nc1.Close()
+	nc2, rs2 := subscribeExclusive(t, s, "c2")
+	defer nc2.Close()
+	nc3, _ := subscribeExclusive(t, s, "c3")
+	defer nc3.Close()
+	// The standby of a closed connection is removed.
+	rawClose(t, s, nc3, "c3")
+
+	sc := NewDefaultConnection(t)
+	if err := sc.Publish("foo", []byte("msg")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	sc.Close()
+	checkExclusiveMsgs(t, rs1, 1)
+
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+
+	// Make sure that the standby's connection is reconnected, otherwise
+	// the redelivered message would be lost.
+	for _, nc := range []*nats.Conn{nc1, nc2} {
+		waitForCount(t, 1, func() (string, int) {
+			if nc.IsConnected() {
+				return "connection", 1
+			}
+			return "connection", 0
+		})
+		if err := nc.Flush(); err != nil {
+			t.Fatalf("Unexpected error on flush: %v", err)
+		}
+	}
+
+	ss := s.channels.get("foo").ss
+	ss.RLock()
+	standbys := ss.standbys[exclusiveDurableKey("foo", "dur")]
+	ss.RUnlock()
+	if len(standbys) != 1 || standbys[0].ClientID != "c2" {
+		t.Fatalf("Unexpected standbys: %v", standbys)
+	}
+	// The recovered standby takes over the pending message.
+	rs1.close(t)
+	checkExclusiveMsgs(t, rs2, 1)
+}
@@ -0,0 +1,271 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/stores"
+	"github.com/nats-io/nuid"
+)
+
+// pubBatch sends a batch publish request on `subj` for the client
+// `clientName`, and returns the ack.
+func pubBatch(t tLogger, nc *nats.Conn, subj string, atomic bool, msgs ...*spb.PubMsg) *spb.PubBatchAck {
+	for _, m := range msgs {
+		m.Guid = nuid.Next()
+	}
+	ack := &spb.PubBatchAck{}
+	sendRawRequest(t, nc, subj, &spb.PubBatch{ClientID: clientName, Msgs: msgs, Atomic: atomic}, ack)
+	return ack
+}
+
+func TestPubBatch(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.MaxChannels = 2
+	opts.ClientLimits.MaxPubBytes = 100
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	cr := rawConnect(t, s, nc, clientName, nil)
+	if cr.PubBatchRequests == "" || cr.Capabilities&uint32(spb.Capability_CapPubBatch) == 0 {
+		t.Fatalf("Batch publish should have been negotiated: %v", cr)
+	}
+
+	if ack := pubBatch(t, nc, cr.PubBatchRequests, false, &spb.PubMsg{Subject: "foo", Data: []byte("first")}); ack.Error != "" {
+		t.Fatalf("Unexpected error on batch publish: %v", ack.Error)
+	}
+	msgs := []*spb.PubMsg{
+		{Subject: "foo", Data: []byte("1")},
+		{Subject: "bar", Data: []byte("2")},
+		{Subject: "foo..bar", Data: []byte("3")},
+		{Subject: "baz", Data: []byte("4")},
+		{Subject: "foo", Data: make([]byte, 200)},
+		{Subject: "foo", Data: []byte("6")},
+	}
+	ack := pubBatch(t, nc, cr.PubBatchRequests, false, msgs...)
+	if ack.Error != "" {
+		t.Fatalf("Unexpected error on batch publish: %v", ack.Error)
+	}
+	expected := []struct {
+		seq uint64
+		err error
+	}{
+		{2, nil},
+		{1, nil},
+		{0, ErrInvalidPubReq},
+		{0, stores.ErrTooManyChannels},
+		{0, ErrPubRateExceeded},
+		{3, nil},
+	}
+	if len(ack.Acks) != len(expected) {
+		t.Fatalf("Expected %v acks, got %v", len(expected), len(ack.Acks))
+	}
+	for i, r := range ack.Acks {
+		e := expected[i]
+		if r.Guid != msgs[i].Guid {
+			t.Fatalf("Ack %v: expected guid %v, got %v", i, msgs[i].Guid, r.Guid)
+		}
+		if r.Sequence != e.seq {
+			t.Fatalf("Ack %v: expected sequence %v, got %v", i, e.seq, r.Sequence)
+		}
+		if (e.err == nil && r.Error != "") || (e.err != nil && r.Error != e.err.Error()) {
+			t.Fatalf("Ack %v: expected error %v, got %q", i, e.err, r.Error)
+		}
+	}
+
+	// Check that the stored messages are those acked in the batch.
+	for _, test := range []struct {
+		channel string
+		data    []string
+	}{
+		{"foo", []string{"first", "1", "6"}},
+		{"bar", []string{"2"}},
+	} {
+		c := s.channels.get(test.channel)
+		if c == nil {
+			t.Fatalf("Channel %q should have been created", test.channel)
+		}
+		for i, data := range test.data {
+			m, err := c.store.Msgs.Lookup(uint64(i + 1))
+			if err != nil || m == nil {
+				t.Fatalf("Error looking up message %v: %v", i+1, err)
+			}
+			if string(m.Data) != data {
+				t.Fatalf("Channel %q, message %v: expected %q, got %q", test.channel, i+1, data, m.Data)
+			}
+		}
+		if _, last := msgStoreFirstAndLastSequence(t, c.store.Msgs); last != uint64(len(test.data)) {
+			t.Fatalf("Channel %q: expected %v messages, got %v", test.channel, len(test.data), last)
+		}
+	}
+}
+
+func TestPubBatchInvalidRequest(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	cr := rawConnect(t, s, nc, clientName, nil)
+	if ack := pubBatch(t, nc, cr.PubBatchRequests, false); ack.Error != ErrInvalidPubReq.Error() {
+		t.Fatalf("Expected error %v, got %q", ErrInvalidPubReq, ack.Error)
+	}
+
+	req := &spb.PubBatch{ClientID: "unknown", Msgs: []*spb.PubMsg{{Guid: "guid", Subject: "foo"}}}
+	b, _ := req.Marshal()
+	for _, data := range [][]byte{[]byte("invalid"), b} {
+		reply, err := nc.Request(s.info.PubBatch, data, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error on request: %v", err)
+		}
+		ack := &spb.PubBatchAck{}
+		if err := ack.Unmarshal(reply.Data); err != nil {
+			t.Fatalf("Unexpected error on unmarshal: %v", err)
+		}
+		if ack.Error != ErrInvalidPubReq.Error() || len(ack.Acks) != 0 {
+			t.Fatalf("Unexpected ack: %v", ack)
+		}
+	}
+	if s.channels.get("foo") != nil {
+		t.Fatal("Channel foo should not have been created")
+	}
+}
+
+func TestPubBatchTooBig(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	tooManyMsgs := make([]*spb.PubMsg, MaxPubBatchMsgs+1)
+	for i := range tooManyMsgs {
+		tooManyMsgs[i] = &spb.PubMsg{Guid: nuid.Next(), Subject: "foo", Data: []byte("x")}
+	}
+	tooManyBytes := []*spb.PubMsg{
+		{Guid: nuid.Next(), Subject: "foo", Data: make([]byte, MaxPubBatchBytes/2)},
+		{Guid: nuid.Next(), Subject: "foo", Data: make([]byte, MaxPubBatchBytes/2+1)},
+	}
+	for _, msgs := range [][]*spb.PubMsg{tooManyMsgs, tooManyBytes} {
+		req := &spb.PubBatch{ClientID: clientName, Msgs: msgs}
+		b, _ := req.Marshal()
+		reply, err := nc.Request(s.info.PubBatch, b, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error on request: %v", err)
+		}
+		ack := &spb.PubBatchAck{}
+		if err := ack.Unmarshal(reply.Data); err != nil {
+			t.Fatalf("Unexpected error on unmarshal: %v", err)
+		}
+		if ack.Error != ErrPubBatchTooBig.Error() || len(ack.Acks) != 0 {
+			t.Fatalf("Unexpected ack: %v", ack)
+		}
+	}
+	if s.channels.get("foo") != nil {
+		t.Fatal("Channel foo should not have been created")
+	}
+}
+
+func TestPubBatchNotSupportedWithPartitions(t *testing.T) {
+	setPartitionsVarsForTest()
+	defer resetDefaultPartitionsVars()
+
+	opts := GetDefaultOptions()
+	opts.Partitioning = true
+	opts.StoreLimits.AddPerChannel("foo", &stores.ChannelLimits{})
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	// Batch publish is not negotiated with the client.
+	cr := rawConnect(t, s, nc, clientName, nil)
+	if cr.Error != "" {
+		t.Fatalf("Unexpected error on connect: %v", cr.Error)
+	}
+	if cr.PubBatchRequests != "" || cr.Capabilities&uint32(spb.Capability_CapPubBatch) != 0 {
+		t.Fatalf("Batch publish should not have been negotiated: %v", cr)
+	}
+}
+
+func TestPubBatchTransaction(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.MaxChannels = 2
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	ch := make(chan *stan.Msg, 10)
+	if _, err := sc.Subscribe("foo", func(m *stan.Msg) { ch <- m }); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	ack := pubBatch(t, nc, s.info.PubBatch, true,
+		&spb.PubMsg{Subject: "foo", Data: []byte("1")},
+		&spb.PubMsg{Subject: "bar", Data: []byte("2")},
+		&spb.PubMsg{Subject: "foo", Data: []byte("3")})
+	if ack.Error != "" {
+		t.Fatalf("Unexpected error on transaction: %v", ack.Error)
+	}
+	if len(ack.Acks) != 3 || ack.Acks[0].Sequence != 1 || ack.Acks[1].Sequence != 1 || ack.Acks[2].Sequence != 2 {
+		t.Fatalf("Unexpected acks: %v", ack.Acks)
+	}
+
+	// Nothing is stored if a message is invalid or can not be stored.
+	for _, test := range []struct {
+		subject string
+		err     error
+	}{
+		{"foo..bar", ErrInvalidPubReq},
+		{"baz", stores.ErrTooManyChannels},
+	} {
+		if ack := pubBatch(t, nc, s.info.PubBatch, true,
+			&spb.PubMsg{Subject: "foo", Data: []byte("aborted")},
+			&spb.PubMsg{Subject: test.subject, Data: []byte("aborted")}); ack.Error != test.err.Error() {
+			t.Fatalf("Expected error %v, got %q", test.err, ack.Error)
+		}
+	}
+	if err := sc.Publish("foo", []byte("4")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	for _, expected := range []string{"1", "3", "4"} {
+		select {
+		case m := <-ch:
+			if string(m.Data) != expected {
+				t.Fatalf("Expected message %q, got %q", expected, m.Data)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Did not get our message")
+		}
+	}
+	if _, last := msgStoreFirstAndLastSequence(t, s.channels.get("foo").store.Msgs); last != 3 {
+		t.Fatalf("Expected last sequence to be 3, got %v", last)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
 	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
 )
 
 type response interface {
@@ -52,13 +53,13 @@ func TestInvalidRequests(t *testing.T) {
 	// Get the connect subject
 	connSubj := fmt.Sprintf("%s.%s", s.opts.DiscoverPrefix, clusterName)
 	if err := checkServerResponse(nc, connSubj, ErrInvalidConnReq,
-		&pb.ConnectResponse{}); err != nil {
+		&spb.ConnectResponse{}); err != nil {
 		t.Fatalf("%v", err)
 	}
 
 	// Send a dummy message on the STAN publish subject
 	if err := checkServerResponse(nc, s.info.Publish+".foo", ErrInvalidPubReq,
-		&pb.PubAck{}); err != nil {
+		&spb.PubAck{}); err != nil {
 		t.Fatalf("%v", err)
 	}
 
@@ -87,7 +88,7 @@ func TestInvalidRequests(t *testing.T) {
 	}
 }
 
-func sendInvalidSubRequest(s *StanServer, nc *nats.Conn, req *pb.SubscriptionRequest, expectedErr error) error {
+func sendInvalidSubRequest(s *StanServer, nc *nats.Conn, req *spb.SubscriptionRequest, expectedErr error) error {
 	b, err := req.Marshal()
 	if err != nil {
 		return fmt.Errorf("Error during marshal: %v", err)
@@ -123,7 +124,7 @@ func TestInvalidSubRequest(t *testing.T) {
 	// may require changes here.
 
 	// Create empty request
-	req := &pb.SubscriptionRequest{}
+	req := &spb.SubscriptionRequest{}
 
 	// We have already tested corrupted SusbcriptionRequests
 	// (as in Unmarshal errors) in TestInvalidRequests. Here, we check
@@ -163,17 +164,17 @@ func TestInvalidSubRequest(t *testing.T) {
 
 	// Test invalid StartPosition values
 	req.MaxInFlight = 1
-	req.StartPosition = pb.StartPosition_NewOnly - 1
+	req.StartPosition = spb.StartPosition_NewOnly - 1
 	if err := sendInvalidSubRequest(s, nc, req, ErrInvalidStart); err != nil {
 		t.Fatalf("%v", err)
 	}
-	req.StartPosition = pb.StartPosition_First + 1
+	req.StartPosition = spb.StartPosition_LastN + 1
 	if err := sendInvalidSubRequest(s, nc, req, ErrInvalidStart); err != nil {
 		t.Fatalf("%v", err)
 	}
 
 	// Test invalid subjects
-	req.StartPosition = pb.StartPosition_First
+	req.StartPosition = spb.StartPosition_First
 	req.Subject = "foo*.bar"
 	if err := sendInvalidSubRequest(s, nc, req, ErrInvalidSubject); err != nil {
 		t.Fatalf("%v", err)
@@ -238,7 +239,7 @@ func TestInvalidSubRequest(t *testing.T) {
 	}
 }
 
-func sendInvalidUnsubRequest(s *StanServer, nc *nats.Conn, req *pb.UnsubscribeRequest) error {
+func sendInvalidUnsubRequest(s *StanServer, nc *nats.Conn, req *spb.UnsubscribeRequest) error {
 	b, err := req.Marshal()
 	if err != nil {
 		return fmt.Errorf("Error during marshal: %v", err)
@@ -288,7 +289,7 @@ func TestInvalidUnsubRequest(t *testing.T) {
 	subs := checkSubs(t, s, clientName, 1)
 
 	// Create empty request
-	req := &pb.UnsubscribeRequest{}
+	req := &spb.UnsubscribeRequest{}
 
 	// Send this empty request
 	if err := sendInvalidUnsubRequest(s, nc, req); err != nil {
@@ -8,10 +8,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
 	"github.com/nats-io/go-nats-streaming/pb"
 	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
+	"github.com/nats-io/nuid"
 )
 
 type mockedStore struct {
@@ -21,7 +23,9 @@ type mockedStore struct {
 type mockedMsgStore struct {
 	stores.MsgStore
 	sync.RWMutex
-	fail bool
+	fail      bool
+	failStore bool
+	failFlush bool
 }
 
 type mockedSubStore struct {
@@ -40,6 +44,26 @@ func (ms *mockedStore) CreateChannel(name string) (*stores.Channel, error) {
 	return cs, nil
 }
 
+func (ms *mockedMsgStore) Store(data []byte) (uint64, error) {
+	ms.RLock()
+	fail := ms.failStore
+	ms.RUnlock()
+	if fail {
+		return 0, errOnPurpose
+	}
+	return ms.MsgStore.Store(data)
+}
+
+func (ms *mockedMsgStore) Flush() error {
+	ms.RLock()
+	fail := ms.failFlush
+	ms.RUnlock()
+	if fail {
+		return errOnPurpose
+	}
+	return ms.MsgStore.Flush()
+}
+
 func (ms *mockedMsgStore) Lookup(seq uint64) (*pb.MsgProto, error) {
 	ms.RLock()
 	fail := ms.fail
@@ -556,3 +580,108 @@ func TestClientStoreError(t *testing.T) {
 		t.Fatalf("Unexpected client in server: %v", c)
 	}
 }
+
+func TestFlushFailureWithTransactions(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.MaxChannels = 2
+	// Give time to the requests below to be stored in the same batch.
+	opts.IOSleepTime = int64(500 * time.Millisecond / time.Microsecond)
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	s.channels.Lock()
+	s.channels.store = &mockedStore{Store: s.channels.store}
+	s.channels.Unlock()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+	ch := make(chan *stan.Msg, 10)
+	if _, err := sc.Subscribe("foo", func(m *stan.Msg) { ch <- m }); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := sc.Publish("bar", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	mms := channelsGet(t, s.channels, "foo").store.Msgs.(*mockedMsgStore)
+	mms.Lock()
+	mms.failFlush = true
+	mms.Unlock()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	sendTx := func(msgs ...*spb.PubMsg) *nats.Subscription {
+		for _, m := range msgs {
+			m.Guid = nuid.Next()
+		}
+		b, _ := (&spb.PubBatch{ClientID: clientName, Msgs: msgs, Atomic: true}).Marshal()
+		inbox := nats.NewInbox()
+		sub, err := nc.SubscribeSync(inbox)
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		if err := nc.PublishRequest(s.info.PubBatch, inbox, b); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+		return sub
+	}
+	checkTxErr := func(sub *nats.Subscription, expected error) {
+		m, err := sub.NextMsg(5 * time.Second)
+		if err != nil {
+			t.Fatalf("Did not get the transaction ack: %v", err)
+		}
+		ack := &spb.PubBatchAck{}
+		if err := ack.Unmarshal(m.Data); err != nil {
+			t.Fatalf("Unexpected error on unmarshal: %v", err)
+		}
+		if ack.Error != expected.Error() || len(ack.Acks) != 0 {
+			t.Fatalf("Expected error %v and no ack, got %v", expected, ack)
+		}
+	}
+
+	// A message that fails to be flushed and transactions that fail to be
+	// stored (the mocked stores do not support transactions) are processed
+	// in the same batch.
+	pubErr := make(chan error, 1)
+	if _, err := sc.PublishAsync("foo", []byte("1"), func(_ string, err error) { pubErr <- err }); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	notSupportedTx := sendTx(&spb.PubMsg{Subject: "foo", Data: []byte("2")}, &spb.PubMsg{Subject: "bar", Data: []byte("2")})
+	tooManyChannelsTx := sendTx(&spb.PubMsg{Subject: "foo", Data: []byte("3")}, &spb.PubMsg{Subject: "baz", Data: []byte("3")})
+	select {
+	case err := <-pubErr:
+		if err == nil || err.Error() != errOnPurpose.Error() {
+			t.Fatalf("Expected error %v, got %v", errOnPurpose, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Did not get the publish ack")
+	}
+	checkTxErr(notSupportedTx, stores.ErrNotSupported)
+	checkTxErr(tooManyChannelsTx, stores.ErrTooManyChannels)
+
+	// The messages that could not be flushed are not delivered.
+	select {
+	case m := <-ch:
+		t.Fatalf("Unexpected message: %v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+	// The server is still running.
+	mms.Lock()
+	mms.failFlush = false
+	mms.Unlock()
+	if err := sc.Publish("bar", []byte("hello")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+
+	// A transaction stored in a channel that fails to be flushed fails as
+	// a whole.
+	iopm := &ioPendingMsg{batch: &ioPendingBatch{}}
+	iopm.batch.req = spb.PubBatch{Atomic: true, Msgs: []*spb.PubMsg{{Subject: "bar"}, {Subject: "foo"}}}
+	iopm.batch.ack.Acks = []*spb.PubAck{{Sequence: 2}, {Sequence: 1}}
+	setFlushErrors(iopm, map[string]error{"foo": errOnPurpose})
+	if ack := iopm.batch.ack; ack.Error != errOnPurpose.Error() || len(ack.Acks) != 0 {
+		t.Fatalf("Expected error %v and no ack, got %v", errOnPurpose, ack)
+	}
+}
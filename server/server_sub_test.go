@@ -2,6 +2,7 @@
 package server
 
 import (
+	"errors"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/nats-io/go-nats"
 	"github.com/nats-io/go-nats-streaming"
 	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 )
 
@@ -656,6 +658,59 @@ func TestSubStartPositionWithQueueSub(t *testing.T) {
 	}
 }
 
+func TestSubStartPositionLastN(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	total := 10
+	for i := 0; i < total; i++ {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, "sub", nil)
+	// Subscribes starting with the last `n` messages and checks the sequence
+	// of the first message received, if any.
+	check := func(expected uint64, n uint64, req *spb.SubscriptionRequest) *rawSubscription {
+		req.ClientID, req.Subject = "sub", "foo"
+		req.StartPosition, req.StartLastN = spb.StartPosition_LastN, n
+		rs, err := rawSubscribe(t, s, nc, req)
+		if err != nil {
+			stackFatalf(t, "Unexpected error on subscribe: %v", err)
+		}
+		select {
+		case m := <-rs.msgs:
+			if m.Sequence != expected {
+				stackFatalf(t, "Expected first message %v, got %v", expected, m.Sequence)
+			}
+		case <-time.After(250 * time.Millisecond):
+			if expected != 0 {
+				stackFatalf(t, "Did not get our message")
+			}
+		}
+		return rs
+	}
+	check(8, 3, &spb.SubscriptionRequest{}).unsubscribe(t)
+	check(1, uint64(total), &spb.SubscriptionRequest{}).unsubscribe(t)
+	// Clamped at the first message.
+	check(1, 100, &spb.SubscriptionRequest{}).unsubscribe(t)
+	// No message for N=0.
+	check(0, 0, &spb.SubscriptionRequest{}).unsubscribe(t)
+
+	// A new durable queue group starts with the last N messages, but
+	// the start position of members joining the group is ignored.
+	check(9, 2, &spb.SubscriptionRequest{QGroup: "group", DurableName: "dur", MaxInFlight: 1})
+	check(10, 5, &spb.SubscriptionRequest{QGroup: "group", DurableName: "dur", MaxInFlight: 1})
+}
+
 func TestAckTimerSetOnStalledSub(t *testing.T) {
 
 	s := runServer(t, clusterName)
@@ -856,6 +911,24 @@ func TestTraceSubCreateCloseUnsubscribeRequests(t *testing.T) {
 			t.Fatalf("Error on unsubscribe: %v", err)
 		}
 	}
+	// The Go client has no option for the last N messages start position.
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rs, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: clientName, Subject: "foo",
+		StartPosition: spb.StartPosition_LastN, StartLastN: 5})
+	if err != nil {
+		t.Fatalf("Error on subscribe: %v", err)
+	}
+	logger.Lock()
+	msg := logger.msg
+	logger.Unlock()
+	if txt := "last messages, asked_count=5 seq=1"; !strings.HasSuffix(msg, txt) {
+		t.Fatalf("Execpected suffix %q, got %q", txt, msg)
+	}
+	rs.unsubscribe(t)
 	checkTrace := func(trace string) {
 		logger.Lock()
 		msg := logger.msg
@@ -1040,3 +1113,218 @@ func TestSubStalledSemantics(t *testing.T) {
 	c.store.Msgs = orgMS
 	s.channels.Unlock()
 }
+
+func TestSubStopPosition(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	publish := func(n int) {
+		for i := 0; i < n; i++ {
+			if err := sc.Publish("foo", []byte("msg")); err != nil {
+				t.Fatalf("Unexpected error on publish: %v", err)
+			}
+		}
+	}
+	publish(3)
+	stopTime := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	publish(2)
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	if cr := rawConnect(t, s, nc, "sub", nil); cr.Capabilities&uint32(spb.Capability_CapReplayEnd) == 0 {
+		t.Fatalf("End of replay should have been negotiated: %v", cr)
+	}
+
+	check := func(expected []uint64, req *spb.SubscriptionRequest) {
+		req.ClientID, req.Subject = "sub", "foo"
+		rs, err := rawSubscribe(t, s, nc, req)
+		if err != nil {
+			t.Fatalf("Unexpected error on subscribe: %v", err)
+		}
+		for _, seq := range expected {
+			m := rs.next(t)
+			if m.ReplayEnd || m.Sequence != seq {
+				t.Fatalf("Expected message %v, got %v", seq, m)
+			}
+			rs.ack(t, m)
+		}
+		if m := rs.next(t); !m.ReplayEnd {
+			t.Fatalf("Expected the end of replay, got %v", m)
+		}
+		waitForNumSubs(t, s, "sub", 0)
+	}
+	check([]uint64{2, 3, 4}, &spb.SubscriptionRequest{StartPosition: spb.StartPosition_SequenceStart,
+		StartSequence: 2, StopSequence: 4})
+	check([]uint64{1, 2, 3}, &spb.SubscriptionRequest{StartPosition: spb.StartPosition_First,
+		StopTimeDelta: time.Now().UnixNano() - stopTime.UnixNano()})
+	// A stop position before the start position ends the subscription right away.
+	check(nil, &spb.SubscriptionRequest{StartPosition: spb.StartPosition_SequenceStart,
+		StartSequence: 4, StopSequence: 2})
+	// The subscription waits for messages up to its stop position.
+	time.AfterFunc(50*time.Millisecond, func() { publish(3) })
+	check([]uint64{5, 6, 7}, &spb.SubscriptionRequest{StartPosition: spb.StartPosition_SequenceStart,
+		StartSequence: 5, StopSequence: 7})
+	// Without any message after the stop time, the end is checked at that time.
+	check([]uint64{8}, &spb.SubscriptionRequest{StartPosition: spb.StartPosition_LastReceived,
+		StopTimeDelta: -int64(100 * time.Millisecond)})
+
+	for _, subject := range []string{"foo", "foo.*"} {
+		req := &spb.SubscriptionRequest{ClientID: "sub", Subject: subject, StopSequence: 2}
+		if subject == "foo" {
+			req.QGroup = "group"
+		}
+		if _, err := rawSubscribe(t, s, nc, req); err == nil || err.Error() != ErrInvalidStop.Error() {
+			t.Fatalf("Expected error %v, got %v", ErrInvalidStop, err)
+		}
+	}
+}
+
+func TestSubStopPositionWithDurable(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	rawConnect(t, s, nc, "sub", nil)
+	rs, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo",
+		DurableName: "dur", StartPosition: spb.StartPosition_First, StopSequence: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	first, last := rs.next(t), rs.next(t)
+	rs.ack(t, first)
+	// The subscription is not closed until all messages are acknowledged.
+	select {
+	case m := <-rs.msgs:
+		t.Fatalf("Unexpected message %v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+	rs.ack(t, last)
+	if m := rs.next(t); !m.ReplayEnd {
+		t.Fatalf("Expected the end of replay, got %v", m)
+	}
+	if last.Sequence != 2 || len(rs.msgs) != 0 {
+		t.Fatalf("Unexpected messages, last=%v pending=%v", last.Sequence, len(rs.msgs))
+	}
+	waitForNumSubs(t, s, "sub", 0)
+
+	// The durable is closed, not removed, and ends again when resumed.
+	rs, err = rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo", DurableName: "dur"})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if m := rs.next(t); !m.ReplayEnd {
+		t.Fatalf("Expected the end of replay, got %v", m)
+	}
+}
+
+func TestSubPauseResume(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		t.Fatalf("Unexpected error on connect: %v", err)
+	}
+	defer nc.Close()
+	cr := rawConnect(t, s, nc, "sub", nil)
+	if cr.SubPauseRequests == "" || cr.Capabilities&uint32(spb.Capability_CapSubPause) == 0 {
+		t.Fatalf("Subscription pause should have been negotiated: %v", cr)
+	}
+	rs, err := rawSubscribe(t, s, nc, &spb.SubscriptionRequest{ClientID: "sub", Subject: "foo", AckWaitInSecs: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	pause := func(pause, redeliveries bool) error {
+		resp := &pb.SubscriptionResponse{}
+		sendRawRequest(t, nc, cr.SubPauseRequests, &spb.SubPauseRequest{ClientID: "sub", Subject: "foo",
+			Inbox: rs.ackInbox, Pause: pause, Redeliveries: redeliveries}, resp)
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+	publish := func() {
+		if err := sc.Publish("foo", []byte("msg")); err != nil {
+			stackFatalf(t, "Unexpected error on publish: %v", err)
+		}
+	}
+	checkMsg := func(seq uint64, redelivered bool) {
+		if m := rs.next(t); m.Sequence != seq || m.Redelivered != redelivered {
+			stackFatalf(t, "Expected message %v (redelivered=%v), got %v (redelivered=%v)",
+				seq, redelivered, m.Sequence, m.Redelivered)
+		}
+	}
+	checkNoMsg := func(d time.Duration) {
+		select {
+		case m := <-rs.msgs:
+			stackFatalf(t, "Unexpected message %v (redelivered=%v)", m.Sequence, m.Redelivered)
+		case <-time.After(d):
+		}
+	}
+	publish()
+	checkMsg(1, false)
+
+	// While paused, new messages are not delivered, but the pending one
+	// is still redelivered.
+	if err := pause(true, false); err != nil {
+		t.Fatalf("Unexpected error on pause: %v", err)
+	}
+	subs := checkSubs(t, s, "sub", 1)
+	subs[0].RLock()
+	paused := subs[0].Paused
+	subs[0].RUnlock()
+	if !paused {
+		t.Fatal("Subscription should be paused")
+	}
+	publish()
+	checkMsg(1, true)
+	checkNoMsg(100 * time.Millisecond)
+	if err := pause(false, false); err != nil {
+		t.Fatalf("Unexpected error on resume: %v", err)
+	}
+	checkMsg(2, false)
+
+	// Redeliveries can be paused too.
+	if err := pause(true, true); err != nil {
+		t.Fatalf("Unexpected error on pause: %v", err)
+	}
+	publish()
+	checkNoMsg(1500 * time.Millisecond)
+	if err := pause(false, false); err != nil {
+		t.Fatalf("Unexpected error on resume: %v", err)
+	}
+	// The new message and the redeliveries can be received in any order.
+	for i := 0; i < 3; i++ {
+		if m := rs.next(t); m.Redelivered != (m.Sequence != 3) {
+			t.Fatalf("Unexpected message %v (redelivered=%v)", m.Sequence, m.Redelivered)
+		}
+	}
+
+	// A closed subscription can't be paused.
+	rs.close(t)
+	if err := pause(true, false); err == nil || err.Error() != ErrInvalidSub.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrInvalidSub, err)
+	}
+}
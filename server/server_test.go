@@ -22,6 +22,7 @@ import (
 	"github.com/nats-io/go-nats-streaming"
 	"github.com/nats-io/go-nats-streaming/pb"
 	"github.com/nats-io/nats-streaming-server/logger"
+	"github.com/nats-io/nats-streaming-server/spb"
 	"github.com/nats-io/nats-streaming-server/stores"
 	"github.com/nats-io/nuid"
 )
@@ -289,6 +290,141 @@ func NewDefaultConnection(t tLogger) stan.Conn {
 	return sc
 }
 
+// sendRawRequest sends the protocol request `req` on `subj` with a plain
+// NATS connection, and decodes the reply into `resp`. This is used to
+// exercise the protocol extensions that the Go client does not support.
+func sendRawRequest(t tLogger, nc *nats.Conn, subj string, req interface {
+	Marshal() ([]byte, error)
+}, resp response) {
+	b, err := req.Marshal()
+	if err != nil {
+		stackFatalf(t, "Unexpected error on marshal: %v", err)
+	}
+	reply, err := nc.Request(subj, b, 2*time.Second)
+	if err != nil {
+		stackFatalf(t, "Unexpected error on request: %v", err)
+	}
+	if err := resp.Unmarshal(reply.Data); err != nil {
+		stackFatalf(t, "Unexpected error on unmarshal: %v", err)
+	}
+}
+
+// rawConnect sends a connect request for `clientID`, updated by `setup` if
+// not nil, with a plain NATS connection, and returns the response. Like the
+// Go client, the connection replies to the server heartbeats.
+func rawConnect(t tLogger, s *StanServer, nc *nats.Conn, clientID string, setup func(*spb.ConnectRequest)) *spb.ConnectResponse {
+	req := &spb.ConnectRequest{
+		ClientID:       clientID,
+		HeartbeatInbox: nats.NewInbox(),
+		ConnID:         nuid.Next(),
+		Protocol:       protocolVersion,
+		Capabilities:   uint32(serverCapabilities),
+	}
+	if setup != nil {
+		setup(req)
+	}
+	if _, err := nc.Subscribe(req.HeartbeatInbox, func(m *nats.Msg) {
+		nc.Publish(m.Reply, nil)
+	}); err != nil {
+		stackFatalf(t, "Unexpected error on subscribe: %v", err)
+	}
+	cr := &spb.ConnectResponse{}
+	sendRawRequest(t, nc, s.info.Discovery, req, cr)
+	return cr
+}
+
+// rawClose sends a close request for `clientID` with a plain NATS connection.
+func rawClose(t tLogger, s *StanServer, nc *nats.Conn, clientID string) {
+	resp := &pb.CloseResponse{}
+	sendRawRequest(t, nc, s.info.Close, &spb.CloseRequest{ClientID: clientID}, resp)
+	if resp.Error != "" {
+		stackFatalf(t, "Unexpected error on close: %v", resp.Error)
+	}
+}
+
+// rawSubscription is a subscription created with rawSubscribe.
+type rawSubscription struct {
+	nc           *nats.Conn
+	ackInbox     string
+	msgs         chan *spb.MsgProto
+	unsubSubj    string
+	subCloseSubj string
+	unsubReq     *spb.UnsubscribeRequest
+}
+
+// rawSubscribe sends the subscription request `req` with a plain NATS
+// connection, after setting the inbox, maximum inflight and ack wait to
+// the Go client's defaults if not set. The messages received on the inbox,
+// including the end of replay notifications, are sent to the `msgs`
+// channel of the returned subscription.
+func rawSubscribe(t tLogger, s *StanServer, nc *nats.Conn, req *spb.SubscriptionRequest) (*rawSubscription, error) {
+	if req.Inbox == "" {
+		req.Inbox = nats.NewInbox()
+	}
+	if req.MaxInFlight == 0 {
+		req.MaxInFlight = stan.DefaultMaxInflight
+	}
+	if req.AckWaitInSecs == 0 {
+		req.AckWaitInSecs = int32(stan.DefaultAckWait / time.Second)
+	}
+	rs := &rawSubscription{nc: nc, msgs: make(chan *spb.MsgProto, 100)}
+	if _, err := nc.Subscribe(req.Inbox, func(m *nats.Msg) {
+		msg := &spb.MsgProto{}
+		if err := msg.Unmarshal(m.Data); err == nil {
+			rs.msgs <- msg
+		}
+	}); err != nil {
+		stackFatalf(t, "Unexpected error on subscribe: %v", err)
+	}
+	resp := &pb.SubscriptionResponse{}
+	sendRawRequest(t, nc, s.info.Subscribe, req, resp)
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	rs.ackInbox = resp.AckInbox
+	rs.unsubSubj, rs.subCloseSubj = s.info.Unsubscribe, s.info.SubClose
+	rs.unsubReq = &spb.UnsubscribeRequest{ClientID: req.ClientID, Subject: req.Subject,
+		Inbox: resp.AckInbox, DurableName: req.DurableName, ConnID: req.ConnID}
+	return rs, nil
+}
+
+// next returns the next message received by the subscription.
+func (rs *rawSubscription) next(t tLogger) *spb.MsgProto {
+	select {
+	case m := <-rs.msgs:
+		return m
+	case <-time.After(2 * time.Second):
+		stackFatalf(t, "Did not get the next message")
+	}
+	return nil
+}
+
+// unsubscribe unsubscribes the subscription.
+func (rs *rawSubscription) unsubscribe(t tLogger) {
+	rs.sendUnsubRequest(t, rs.unsubSubj)
+}
+
+// close closes the subscription.
+func (rs *rawSubscription) close(t tLogger) {
+	rs.sendUnsubRequest(t, rs.subCloseSubj)
+}
+
+func (rs *rawSubscription) sendUnsubRequest(t tLogger, subj string) {
+	resp := &pb.SubscriptionResponse{}
+	sendRawRequest(t, rs.nc, subj, rs.unsubReq, resp)
+	if resp.Error != "" {
+		stackFatalf(t, "Unexpected error on unsubscribe or close: %v", resp.Error)
+	}
+}
+
+// ack acknowledges the message `m`.
+func (rs *rawSubscription) ack(t tLogger, m *spb.MsgProto) {
+	b, _ := (&pb.Ack{Subject: m.Subject, Sequence: m.Sequence}).Marshal()
+	if err := rs.nc.Publish(rs.ackInbox, b); err != nil {
+		stackFatalf(t, "Unexpected error on ack: %v", err)
+	}
+}
+
 func cleanupDatastore(t *testing.T) {
 	if persistentStoreType == stores.TypeFile {
 		if err := os.RemoveAll(defaultDataStore); err != nil {
@@ -850,7 +986,7 @@ func TestAckPublisherBufSize(t *testing.T) {
 	inbox := nats.NewInbox()
 	iopm := &ioPendingMsg{m: &nats.Msg{Reply: inbox}}
 	nc.Subscribe(inbox, func(m *nats.Msg) {
-		pubAck := pb.PubAck{}
+		pubAck := spb.PubAck{}
 		if err := pubAck.Unmarshal(m.Data); err != nil {
 			errCh <- err
 			return
@@ -932,7 +1068,7 @@ func TestMsgsNotSentToSubBeforeSubReqResponse(t *testing.T) {
 
 	// Get the connect subject
 	connSubj := fmt.Sprintf("%s.%s", s.opts.DiscoverPrefix, clusterName)
-	connReq := &pb.ConnectRequest{
+	connReq := &spb.ConnectRequest{
 		ClientID:       clientName,
 		HeartbeatInbox: nats.NewInbox(),
 	}
@@ -941,26 +1077,26 @@ func TestMsgsNotSentToSubBeforeSubReqResponse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Request error: %v", err)
 	}
-	connResponse := &pb.ConnectResponse{}
+	connResponse := &spb.ConnectResponse{}
 	connResponse.Unmarshal(respMsg.Data)
 
 	subSubj := connResponse.SubRequests
 	unsubSubj := connResponse.UnsubRequests
 	pubSubj := connResponse.PubPrefix + ".foo"
 
-	pubMsg := &pb.PubMsg{
+	pubMsg := &spb.PubMsg{
 		ClientID: clientName,
 		Subject:  "foo",
 		Data:     []byte("hello"),
 	}
-	subReq := &pb.SubscriptionRequest{
+	subReq := &spb.SubscriptionRequest{
 		ClientID:      clientName,
 		MaxInFlight:   1024,
 		Subject:       "foo",
-		StartPosition: pb.StartPosition_First,
+		StartPosition: spb.StartPosition_First,
 		AckWaitInSecs: 30,
 	}
-	unsubReq := &pb.UnsubscribeRequest{
+	unsubReq := &spb.UnsubscribeRequest{
 		ClientID: clientName,
 		Subject:  "foo",
 	}
@@ -0,0 +1,221 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/go-nats-streaming"
+)
+
+// checkWildcardMsgs checks that the messages received on `ch` are, in
+// order, those in `expected` (formatted as "channel:data").
+func checkWildcardMsgs(t *testing.T, ch chan *stan.Msg, expected ...string) {
+	for _, e := range expected {
+		select {
+		case m := <-ch:
+			if got := fmt.Sprintf("%s:%s", m.Subject, m.Data); got != e {
+				stackFatalf(t, "Expected message %q, got %q", e, got)
+			}
+		case <-time.After(2 * time.Second):
+			stackFatalf(t, "Did not get message %q", e)
+		}
+	}
+	select {
+	case m := <-ch:
+		stackFatalf(t, "Unexpected message %s:%s", m.Subject, m.Data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWildcardSubscription(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	for _, subj := range []string{"foo.a", "foo.a", "bar"} {
+		if err := sc.Publish(subj, []byte("old")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	ch := make(chan *stan.Msg, 10)
+	sub, err := sc.Subscribe("foo.*", func(m *stan.Msg) { ch <- m }, stan.StartAtSequence(2))
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// The start position applies to the existing channels.
+	checkWildcardMsgs(t, ch, "foo.a:old")
+
+	// New channels are matched, and each channel has its own position.
+	for _, pm := range []struct{ subj, data string }{
+		{"foo.b", "1"},
+		{"foo.a", "2"},
+		{"bar", "3"},
+		{"foo.a.b", "4"},
+		{"foo.b", "5"},
+	} {
+		if err := sc.Publish(pm.subj, []byte(pm.data)); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	checkWildcardMsgs(t, ch, "foo.b:1", "foo.a:2", "foo.b:5")
+	for channel, lastSent := range map[string]uint64{"foo.a": 3, "foo.b": 2} {
+		subs := s.channels.get(channel).ss.psubs
+		if len(subs) != 1 || subs[0].LastSent != lastSent {
+			t.Fatalf("Unexpected subscriptions on %q: %v", channel, subs)
+		}
+	}
+	checkSubs(t, s, clientName, 2)
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error on unsubscribe: %v", err)
+	}
+	checkSubs(t, s, clientName, 0)
+	for _, subj := range []string{"foo.a", "foo.c"} {
+		if err := sc.Publish(subj, []byte("after")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	checkWildcardMsgs(t, ch)
+	if n := len(s.wildcards.subs); n != 0 {
+		t.Fatalf("Expected no wildcard subscription, got %v", n)
+	}
+}
+
+func TestWildcardSubscriptionInvalid(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	cb := func(_ *stan.Msg) {}
+	if _, err := sc.QueueSubscribe("foo.*", "group", cb); err == nil || err.Error() != ErrInvalidWildcardSub.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrInvalidWildcardSub, err)
+	}
+	if _, err := sc.Subscribe("foo.*", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if _, err := sc.Subscribe("foo.*", cb, stan.DurableName("dur")); err == nil || err.Error() != ErrDupDurable.Error() {
+		t.Fatalf("Expected error %v, got %v", ErrDupDurable, err)
+	}
+	// A literal durable with the same name is a different subscription.
+	if _, err := sc.Subscribe("foo.a", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+}
+
+func TestWildcardSubscriptionClientClose(t *testing.T) {
+	s := runServer(t, clusterName)
+	defer s.Shutdown()
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	cb := func(_ *stan.Msg) {}
+	if _, err := sc.Subscribe("foo.>", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if _, err := sc.Subscribe("foo.*", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	if err := sc.Publish("foo.a", []byte("msg")); err != nil {
+		t.Fatalf("Unexpected error on publish: %v", err)
+	}
+	checkSubs(t, s, clientName, 2)
+	sc.Close()
+	waitForNumClients(t, s, 0)
+
+	// The non durable wildcard subscription is gone, the durable is offline.
+	if n := len(s.wildcards.subs); n != 1 {
+		t.Fatalf("Expected 1 wildcard subscription, got %v", n)
+	}
+	if wsub := s.wildcards.lookupByDurable(fmt.Sprintf("%s-foo.*-dur", clientName)); wsub == nil || !wsub.State.IsClosed {
+		t.Fatalf("Durable should be offline: %v", wsub)
+	}
+	if n := len(s.channels.get("foo.a").ss.psubs); n != 0 {
+		t.Fatalf("Expected no subscription on channel, got %v", n)
+	}
+}
+
+func TestWildcardDurableSubscriptionRecovery(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+
+	opts := getTestDefaultOptsForPersistentStore()
+	s := runServerWithOpts(t, opts, nil)
+	defer shutdownRestartedServerOnTestExit(&s)
+
+	sc := NewDefaultConnection(t)
+	defer sc.Close()
+
+	ch := make(chan *stan.Msg, 10)
+	cb := func(m *stan.Msg) { ch <- m }
+	sub, err := sc.Subscribe("foo.>", cb, stan.DurableName("dur"), stan.DeliverAllAvailable())
+	if err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	for _, subj := range []string{"foo.a", "foo.b.c"} {
+		if err := sc.Publish(subj, []byte("1")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	checkWildcardMsgs(t, ch, "foo.a:1", "foo.b.c:1")
+	// Wait for the acks to be processed before closing.
+	for _, sub := range checkSubs(t, s, clientName, 2) {
+		waitForAcks(t, s, clientName, sub.ID, 0)
+	}
+	if err := sub.Close(); err != nil {
+		t.Fatalf("Unexpected error on close: %v", err)
+	}
+	// Messages published while the durable is offline, including on a
+	// new channel, are delivered once it is resumed after a restart.
+	for _, subj := range []string{"foo.a", "foo.d"} {
+		if err := sc.Publish(subj, []byte("2")); err != nil {
+			t.Fatalf("Unexpected error on publish: %v", err)
+		}
+	}
+	sc.Close()
+
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+
+	sc = NewDefaultConnection(t)
+	defer sc.Close()
+	if _, err := sc.Subscribe("foo.>", cb, stan.DurableName("dur")); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	// Each channel delivers on its own, so there is no ordering between them.
+	got := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case m := <-ch:
+			got[fmt.Sprintf("%s:%s", m.Subject, m.Data)] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Did not get all messages, got %v", got)
+		}
+	}
+	if !got["foo.a:2"] || !got["foo.d:2"] {
+		t.Fatalf("Unexpected messages: %v", got)
+	}
+	checkWildcardMsgs(t, ch)
+
+	// A non durable wildcard subscription whose client is gone is not
+	// recovered.
+	if _, err := sc.Subscribe("foo.*", cb); err != nil {
+		t.Fatalf("Unexpected error on subscribe: %v", err)
+	}
+	sc.Close()
+	s.Shutdown()
+	s = runServerWithOpts(t, opts, nil)
+	if n := len(s.wildcards.subs); n != 1 {
+		t.Fatalf("Expected 1 wildcard subscription, got %v", n)
+	}
+	if n := len(s.channels.get("foo.a").ss.psubs); n != 0 {
+		t.Fatalf("Expected no subscription on channel, got %v", n)
+	}
+}
@@ -12,7 +12,7 @@ import (
 // Signal Handling
 func (s *StanServer) handleSignals() {
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGUSR1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGUSR1, syscall.SIGHUP)
 	go func() {
 		for sig := range c {
 			// Notify will relay only the signals that we have
@@ -25,6 +25,9 @@ func (s *StanServer) handleSignals() {
 			case syscall.SIGUSR1:
 				// File log re-open for rotating file logs.
 				s.natsServer.ReOpenLogFile()
+			case syscall.SIGHUP:
+				// Configuration reload, errors are logged.
+				s.Reload()
 			}
 		}
 	}()
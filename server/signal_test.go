@@ -71,3 +71,26 @@ func TestSignalToReOpenLogFile(t *testing.T) {
 		t.Fatalf("Expected log to contain %q, got %q", expectedStr, string(buf))
 	}
 }
+
+func TestSignalToReloadConfig(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	writeReloadConfig(t, "hb_interval: \"10s\"")
+	opts := GetDefaultOptions()
+	opts.HandleSignals = true
+	opts.ConfigFile = reloadConfFile
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	// This should cause the configuration to be reloaded.
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if hbi, _, _ := s.getClientHBOpts(); hbi == 10*time.Second {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Configuration was not reloaded")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
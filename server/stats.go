@@ -0,0 +1,63 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"sync"
+)
+
+// Number of seconds over which message rates are computed.
+const statsRateWindow = 10
+
+// Number of messages and bytes accounted for during a given second.
+type rateSample struct {
+	time  int64
+	msgs  uint64
+	bytes uint64
+}
+
+// rateCounter keeps track of messages and bytes over the last
+// statsRateWindow seconds, using one sample per second.
+type rateCounter struct {
+	sync.Mutex
+	samples [statsRateWindow]rateSample
+}
+
+// channelStats holds the inbound (published) and outbound (delivered)
+// message rates of a channel.
+type channelStats struct {
+	in  rateCounter
+	out rateCounter
+}
+
+// add accounts for one message of `bytes` size at time `now`
+// (expressed in seconds).
+func (rc *rateCounter) add(now int64, bytes uint64) {
+	rc.Lock()
+	s := &rc.samples[now%statsRateWindow]
+	if s.time != now {
+		s.time = now
+		s.msgs = 0
+		s.bytes = 0
+	}
+	s.msgs++
+	s.bytes += bytes
+	rc.Unlock()
+}
+
+// rates returns the number of messages and bytes per second,
+// averaged over the last statsRateWindow seconds prior to `now`
+// (expressed in seconds).
+func (rc *rateCounter) rates(now int64) (float64, float64) {
+	var msgs, bytes uint64
+	rc.Lock()
+	for i := 0; i < statsRateWindow; i++ {
+		s := &rc.samples[i]
+		if s.time > now-statsRateWindow && s.time <= now {
+			msgs += s.msgs
+			bytes += s.bytes
+		}
+	}
+	rc.Unlock()
+	return float64(msgs) / statsRateWindow, float64(bytes) / statsRateWindow
+}
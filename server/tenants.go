@@ -0,0 +1,137 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/nats-io/gnatsd/server"
+	"github.com/nats-io/nats-streaming-server/stores"
+)
+
+// validateTenants checks that the tenants are isolated from each other
+// and from the main cluster ID.
+func validateTenants(sOpts *Options) error {
+	if len(sOpts.Tenants) == 0 {
+		return nil
+	}
+	if sOpts.FTGroupName != "" {
+		return fmt.Errorf("tenants are not supported in fault tolerance mode")
+	}
+	ids := map[string]struct{}{sOpts.ID: {}}
+	dirs := make(map[string]string)
+	if strings.ToUpper(sOpts.StoreType) == stores.TypeFile {
+		dirs[sOpts.FilestoreDir] = sOpts.ID
+	}
+	for _, t := range sOpts.Tenants {
+		if t.ID == "" {
+			return fmt.Errorf("missing cluster ID in tenant")
+		}
+		if _, exists := ids[t.ID]; exists {
+			return fmt.Errorf("duplicate cluster ID %q in tenants", t.ID)
+		}
+		ids[t.ID] = struct{}{}
+		if t.FTGroupName != "" {
+			return fmt.Errorf("tenant %q: fault tolerance is not supported", t.ID)
+		}
+		if len(t.Tenants) > 0 {
+			return fmt.Errorf("tenant %q: tenants cannot be nested", t.ID)
+		}
+		if strings.ToUpper(t.StoreType) == stores.TypeFile {
+			if other, used := dirs[t.FilestoreDir]; used {
+				return fmt.Errorf("tenant %q: store directory %q already used by %q", t.ID, t.FilestoreDir, other)
+			}
+			dirs[t.FilestoreDir] = t.ID
+		}
+	}
+	return nil
+}
+
+// startTenants starts a server for each tenant. Tenants connect to the
+// NATS Server used by this server, and use its logger and the TLS options
+// of its connections. Their monitoring endpoints are those of this server.
+func (s *StanServer) startTenants(nOpts *server.Options) error {
+	if len(s.opts.Tenants) == 0 {
+		return nil
+	}
+	tnOpts := nOpts.Clone()
+	tnOpts.HTTPPort, tnOpts.HTTPSPort = 0, 0
+	if s.opts.NATSServerURL == "" {
+		// The port may have been chosen by the embedded NATS Server.
+		if addr, ok := s.natsServer.Addr().(*net.TCPAddr); ok {
+			tnOpts.Port = addr.Port
+		}
+	}
+	urls, err := s.buildServerURLs(s.opts, tnOpts)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.opts.Tenants {
+		tOpts := t.Clone()
+		tOpts.NATSServerURL = strings.Join(urls, ",")
+		tOpts.Secure = s.opts.Secure
+		tOpts.ClientCert = s.opts.ClientCert
+		tOpts.ClientKey = s.opts.ClientKey
+		tOpts.ClientCA = s.opts.ClientCA
+		tOpts.EnableLogging = false
+		tOpts.CustomLogger = s.log.GetLogger()
+		tOpts.Debug = s.opts.Debug
+		tOpts.Trace = s.opts.Trace
+		tOpts.HandleSignals = false
+		tOpts.ConfigFile = ""
+		ts, err := RunServerWithOpts(tOpts, tnOpts)
+		if err != nil {
+			return fmt.Errorf("unable to start tenant %q: %v", t.ID, err)
+		}
+		s.tenantsMu.Lock()
+		if s.tenants == nil {
+			s.tenants = make(map[string]*StanServer)
+		}
+		s.tenants[t.ID] = ts
+		s.tenantsMu.Unlock()
+	}
+	return nil
+}
+
+// Tenant returns the server hosting the given cluster ID: this server if
+// `clusterID` is its own cluster ID, the server of one of its tenants, or
+// nil if the cluster ID is unknown.
+func (s *StanServer) Tenant(clusterID string) *StanServer {
+	if clusterID == s.opts.ID {
+		return s
+	}
+	s.tenantsMu.RLock()
+	ts := s.tenants[clusterID]
+	s.tenantsMu.RUnlock()
+	return ts
+}
+
+// shutdownTenants shuts down the servers of the tenants.
+func (s *StanServer) shutdownTenants() {
+	s.tenantsMu.Lock()
+	tenants := s.tenants
+	s.tenants = nil
+	s.tenantsMu.Unlock()
+	for _, ts := range tenants {
+		ts.Shutdown()
+	}
+}
+
+// tenantHandler returns a monitoring handler invoking `h` with the server
+// hosting the cluster ID given by the `cluster_id` URL argument. Without
+// this argument, `h` is invoked with this server.
+func (s *StanServer) tenantHandler(h func(*StanServer, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ts := s
+		if clusterID := r.URL.Query().Get("cluster_id"); clusterID != "" {
+			if ts = s.Tenant(clusterID); ts == nil {
+				http.Error(w, fmt.Sprintf("Unknown cluster ID %q", clusterID), http.StatusNotFound)
+				return
+			}
+		}
+		h(ts, w, r)
+	}
+}
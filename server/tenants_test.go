@@ -0,0 +1,198 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/stores"
+)
+
+func newTenantOptions(clusterID string) *Options {
+	opts := GetDefaultOptions()
+	opts.ID = clusterID
+	return opts
+}
+
+func TestTenantsInvalid(t *testing.T) {
+	fileTenant := func(clusterID, dir string) *Options {
+		opts := newTenantOptions(clusterID)
+		opts.StoreType = stores.TypeFile
+		opts.FilestoreDir = dir
+		return opts
+	}
+	ftOpts := newTenantOptions("ft")
+	ftOpts.FTGroupName = "ft"
+	nestedOpts := newTenantOptions("nested")
+	nestedOpts.Tenants = []*Options{newTenantOptions("other")}
+	for _, test := range []struct {
+		name    string
+		ftGroup string
+		tenants []*Options
+		err     string
+	}{
+		{"missing id", "", []*Options{newTenantOptions("")}, "missing cluster ID"},
+		{"main id", "", []*Options{newTenantOptions(clusterName)}, "duplicate cluster ID"},
+		{"duplicate id", "", []*Options{newTenantOptions("a"), newTenantOptions("a")}, "duplicate cluster ID"},
+		{"same dir", "", []*Options{fileTenant("a", "dir"), fileTenant("b", "dir")}, "already used"},
+		{"tenant ft", "", []*Options{ftOpts}, "fault tolerance"},
+		{"main ft", "ft", []*Options{newTenantOptions("a")}, "fault tolerance"},
+		{"nested", "", []*Options{nestedOpts}, "nested"},
+	} {
+		opts := GetDefaultOptions()
+		opts.ID = clusterName
+		opts.FTGroupName = test.ftGroup
+		opts.Tenants = test.tenants
+		s, err := RunServerWithOpts(opts, nil)
+		if s != nil || err == nil || !strings.Contains(err.Error(), test.err) {
+			if s != nil {
+				s.Shutdown()
+			}
+			t.Fatalf("%s: expected error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestTenantsIsolation(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.ID = clusterName
+	opts.Tenants = []*Options{newTenantOptions("tenant_a"), newTenantOptions("tenant_b")}
+	opts.Tenants[1].MaxMsgs = 1
+	s := runServerWithOpts(t, opts, nil)
+	defer s.Shutdown()
+
+	ta := s.Tenant("tenant_a")
+	tb := s.Tenant("tenant_b")
+	if s.Tenant(clusterName) != s || ta == nil || tb == nil || s.Tenant("unknown") != nil {
+		t.Fatal("Unexpected tenants")
+	}
+	if ta.info.Discovery == tb.info.Discovery || ta.info.Publish == s.info.Publish {
+		t.Fatal("Tenants should have their own subjects")
+	}
+
+	// The same client ID and channel can be used in each cluster.
+	var conns []stan.Conn
+	for i, clusterID := range []string{clusterName, "tenant_a", "tenant_b"} {
+		sc, err := stan.Connect(clusterID, clientName)
+		if err != nil {
+			t.Fatalf("Unexpected error on connect to %q: %v", clusterID, err)
+		}
+		conns = append(conns, sc)
+		for j := 0; j <= i; j++ {
+			if err := sc.Publish("foo", []byte("hello")); err != nil {
+				t.Fatalf("Unexpected error on publish: %v", err)
+			}
+		}
+	}
+	for _, test := range []struct {
+		s    *StanServer
+		msgs int
+	}{
+		{s, 1},
+		{ta, 2},
+		// Only 1 message because of the tenant's limits.
+		{tb, 1},
+	} {
+		cs := channelsGet(t, test.s.channels, "foo").store
+		if n, _ := msgStoreState(t, cs.Msgs); n != test.msgs {
+			t.Fatalf("Cluster %q: expected %v messages, got %v", test.s.ClusterID(), test.msgs, n)
+		}
+		if test.s.clients.count() != 1 {
+			t.Fatalf("Cluster %q: expected 1 client, got %v", test.s.ClusterID(), test.s.clients.count())
+		}
+	}
+
+	for _, sc := range conns {
+		sc.Close()
+	}
+
+	// Tenants are shutdown with the server.
+	s.Shutdown()
+	if ta.State() != Shutdown || tb.State() != Shutdown {
+		t.Fatal("Tenants should have been shutdown")
+	}
+}
+
+func TestTenantsMonitoring(t *testing.T) {
+	resetPreviousHTTPConnections()
+	opts := GetDefaultOptions()
+	opts.Tenants = []*Options{newTenantOptions("tenant_a")}
+	s := runMonitorServer(t, opts)
+	defer s.Shutdown()
+
+	for _, clusterID := range []string{"", "tenant_a"} {
+		expected := clusterID
+		if expected == "" {
+			expected = s.ClusterID()
+		}
+		for _, path := range []string{ServerPath, StorePath, ClientsPath, ChannelsPath, MsgTracePath} {
+			resp, body := getBody(t, path+"?cluster_id="+clusterID, expectedJSON)
+			resp.Body.Close()
+			var v struct {
+				ClusterID string `json:"cluster_id"`
+			}
+			if err := json.Unmarshal(body, &v); err != nil {
+				t.Fatalf("Got an error unmarshalling the body: %v", err)
+			}
+			if v.ClusterID != expected {
+				t.Fatalf("%s: expected cluster ID %q, got %q", path, expected, v.ClusterID)
+			}
+		}
+		monitorExpectStatus(t, HealthzPath+"?ready=1&cluster_id="+clusterID, http.StatusOK)
+	}
+	monitorExpectStatus(t, ServerPath+"?cluster_id=unknown", http.StatusNotFound)
+}
+
+func TestTenantsConfig(t *testing.T) {
+	defer os.Remove(reloadConfFile)
+	tenants := `
+		tenants: [
+			{cluster_id: "tenant_a", discover_prefix: "_TENANT.discover", store_limits: {max_msgs: 10}}
+			{cluster_id: "tenant_b", hb_interval: "10s"}
+		]
+	`
+	s := runReloadServer(t, "hb_interval: \"5s\"\n"+tenants)
+	defer s.Shutdown()
+
+	ta := s.Tenant("tenant_a")
+	if ta == nil || s.Tenant("tenant_b") == nil {
+		t.Fatal("Tenants should have been started")
+	}
+	if ta.info.Discovery != "_TENANT.discover.tenant_a" {
+		t.Fatalf("Unexpected discovery subject: %v", ta.info.Discovery)
+	}
+	if ta.opts.MaxMsgs != 10 || ta.opts.ClientHBInterval != DefaultHeartBeatInterval {
+		t.Fatalf("Unexpected tenant options: %v", ta.opts)
+	}
+	if hbi := s.Tenant("tenant_b").opts.ClientHBInterval; hbi.String() != "10s" {
+		t.Fatalf("Unexpected tenant heartbeat interval: %v", hbi)
+	}
+
+	// Other options can be reloaded, but not the tenants.
+	writeReloadConfig(t, "hb_interval: \"6s\"\n"+tenants)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Unexpected error on reload: %v", err)
+	}
+	writeReloadConfig(t, "hb_interval: \"6s\"\ntenants: [{cluster_id: \"tenant_a\"}]")
+	if err := s.Reload(); err == nil || !strings.Contains(err.Error(), "tenants") {
+		t.Fatalf("Expected error, got %v", err)
+	}
+
+	for _, content := range []string{
+		"tenants: xxx",
+		"tenants: [xxx]",
+		"tenants: [{store: \"memory\"}]",
+		"tenants: [{cluster_id: \"a\", tenants: [{cluster_id: \"b\"}]}]",
+		"tenants: [{cluster_id: 123}]",
+	} {
+		writeReloadConfig(t, content)
+		if err := ProcessConfigFile(reloadConfFile, GetDefaultOptions()); err == nil {
+			t.Fatalf("Expected error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,572 @@
+// Copyright 2017 Apcera Inc. All rights reserved.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/go-nats"
+	"github.com/nats-io/go-nats-streaming/pb"
+	"github.com/nats-io/nats-streaming-server/audit"
+	"github.com/nats-io/nats-streaming-server/spb"
+	"github.com/nats-io/nats-streaming-server/util"
+	"github.com/nats-io/nuid"
+)
+
+// Prefix of the AckInbox of wildcard subscriptions. The acks of all wildcard
+// subscriptions are received by a single internal subscription on the acks
+// subjects prefix followed by this prefix.
+const wildcardAckInboxPrefix = "w."
+
+// wildcardSub is a subscription on a wildcard subject. Its members are
+// subscriptions on each of the channels matching the subject, so that each
+// channel keeps its own position. Members share the Inbox and AckInbox of
+// their wildcard subscription, and are created for new channels as well.
+//
+// A member is online when it has a ClientID, that is when it is part of its
+// channel's subscriptions. Members of an offline durable wildcard
+// subscription are only referenced here, until the durable is resumed.
+type wildcardSub struct {
+	sync.Mutex
+	spb.WildcardSub                      // Embedded protobuf. Used for storage.
+	members         map[string]*subState // Keyed by channel name
+	initialized     bool                 // false until the subscription response has been sent.
+	removed         bool
+}
+
+// wildcardStore holds the wildcard subscriptions, including the offline
+// durables. The ClientID, subject and durable name of a wildcard
+// subscription never change, so they can be accessed without its lock.
+type wildcardStore struct {
+	sync.RWMutex
+	sl       *util.Sublist           // Matches channels with wildcard subscriptions
+	subs     map[uint64]*wildcardSub // Keyed by ID
+	acks     map[string]*wildcardSub // AckInbox lookup, online subscriptions only
+	durables map[string]*wildcardSub // Durables lookup
+}
+
+func newWildcardStore() *wildcardStore {
+	return &wildcardStore{
+		sl:       util.NewSublist(),
+		subs:     make(map[uint64]*wildcardSub),
+		acks:     make(map[string]*wildcardSub),
+		durables: make(map[string]*wildcardSub),
+	}
+}
+
+// Used to generate durable key. This should not be called on non-durables.
+func (wsub *wildcardSub) durableKey() string {
+	return fmt.Sprintf("%s-%s-%s", wsub.State.ClientID, wsub.Subject, wsub.State.DurableName)
+}
+
+// add adds `wsub` to the store.
+// wsub's lock held on entry (or not needed, as during server restart).
+func (wcs *wildcardStore) add(wsub *wildcardSub) {
+	wcs.Lock()
+	wcs.sl.Insert(wsub.Subject, wsub)
+	wcs.subs[wsub.State.ID] = wsub
+	if !wsub.State.IsClosed {
+		wcs.acks[wsub.State.AckInbox] = wsub
+	}
+	if wsub.State.IsDurable {
+		wcs.durables[wsub.durableKey()] = wsub
+	}
+	wcs.Unlock()
+}
+
+// remove removes `wsub` from the store.
+// wsub's lock held on entry.
+func (wcs *wildcardStore) remove(wsub *wildcardSub) {
+	wcs.Lock()
+	wcs.sl.Remove(wsub.Subject, wsub)
+	delete(wcs.subs, wsub.State.ID)
+	delete(wcs.acks, wsub.State.AckInbox)
+	if wsub.State.IsDurable {
+		delete(wcs.durables, wsub.durableKey())
+	}
+	wcs.Unlock()
+}
+
+// setOnline sets the AckInbox of the resumed durable `wsub`.
+// wsub's lock held on entry.
+func (wcs *wildcardStore) setOnline(wsub *wildcardSub, ackInbox string) {
+	wcs.Lock()
+	wsub.State.AckInbox = ackInbox
+	wsub.State.IsClosed = false
+	wcs.acks[ackInbox] = wsub
+	wcs.Unlock()
+}
+
+// setOffline marks the durable `wsub` as closed.
+// wsub's lock held on entry.
+func (wcs *wildcardStore) setOffline(wsub *wildcardSub) {
+	wcs.Lock()
+	delete(wcs.acks, wsub.State.AckInbox)
+	wsub.State.IsClosed = true
+	wcs.Unlock()
+}
+
+// match returns the wildcard subscriptions matching `channel`.
+func (wcs *wildcardStore) match(channel string) []*wildcardSub {
+	r := wcs.sl.Match(channel)
+	if len(r) == 0 {
+		return nil
+	}
+	subs := make([]*wildcardSub, len(r))
+	for i, e := range r {
+		subs[i] = e.(*wildcardSub)
+	}
+	return subs
+}
+
+// matches returns true if `wsub` matches `channel`.
+func (wcs *wildcardStore) matches(wsub *wildcardSub, channel string) bool {
+	for _, e := range wcs.sl.Match(channel) {
+		if e == wsub {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup by durable key.
+func (wcs *wildcardStore) lookupByDurable(durableKey string) *wildcardSub {
+	wcs.RLock()
+	wsub := wcs.durables[durableKey]
+	wcs.RUnlock()
+	return wsub
+}
+
+// Lookup by AckInbox (without the acks subjects prefix).
+func (wcs *wildcardStore) lookupByAckInbox(ackInbox string) *wildcardSub {
+	wcs.RLock()
+	wsub := wcs.acks[ackInbox]
+	wcs.RUnlock()
+	return wsub
+}
+
+// lookupByClient returns the online wildcard subscriptions of `clientID`.
+func (wcs *wildcardStore) lookupByClient(clientID string) []*wildcardSub {
+	var subs []*wildcardSub
+	wcs.RLock()
+	for _, wsub := range wcs.acks {
+		if wsub.State.ClientID == clientID {
+			subs = append(subs, wsub)
+		}
+	}
+	wcs.RUnlock()
+	return subs
+}
+
+// processWildcardSubscriptionRequest processes a subscription request
+// on a wildcard subject, which has otherwise been validated.
+func (s *StanServer) processWildcardSubscriptionRequest(m *nats.Msg, sr *spb.SubscriptionRequest) {
+	if s.partitions != nil || sr.QGroup != "" {
+		s.log.Errorf("[Client:%s] Invalid wildcard subscription request on %q from %s",
+			sr.ClientID, sr.Subject, m.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidWildcardSub)
+		return
+	}
+	if !s.getPermissions().canSubscribe(sr.ClientID, sr.Subject) {
+		s.log.Errorf("[Client:%s] Not authorized to subscribe to %q", sr.ClientID, sr.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrSubNotAuthorized)
+		return
+	}
+	if err := s.checkSubQuota(sr.ClientID); err != nil {
+		s.log.Errorf("[Client:%s] Subscription to %q rejected: %v", sr.ClientID, sr.Subject, err)
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
+	if !s.clients.isValid(sr.ClientID) {
+		s.log.Errorf("[Client:%s] Subscription to %q from unknown client", sr.ClientID, sr.Subject)
+		s.sendSubscriptionResponseErr(m.Reply, ErrUnknownClient)
+		return
+	}
+
+	ackInbox := wildcardAckInboxPrefix + nuid.Next()
+
+	var wsub *wildcardSub
+	if sr.DurableName != "" {
+		wsub = s.wildcards.lookupByDurable(durableKey(sr))
+	}
+	isNew := wsub == nil
+	var err error
+	if isNew {
+		wsub = &wildcardSub{
+			WildcardSub: spb.WildcardSub{
+				State: &spb.SubState{
+					ClientID:           sr.ClientID,
+					Inbox:              sr.Inbox,
+					AckInbox:           ackInbox,
+					MaxInFlight:        sr.MaxInFlight,
+					MaxInFlightBytes:   sr.MaxInFlightBytes,
+					AckWaitInSecs:      sr.AckWaitInSecs,
+					DurableName:        sr.DurableName,
+					IsDurable:          sr.DurableName != "",
+					ReplaySpeedPercent: sr.ReplaySpeedPercent,
+					DeliveryRate:       sr.DeliveryRate,
+					DeliveryBurst:      sr.DeliveryBurst,
+				},
+				Subject: sr.Subject,
+			},
+			members: make(map[string]*subState),
+		}
+		if err := s.store.CreateWildcardSub(&wsub.WildcardSub); err != nil {
+			s.log.Errorf("Unable to add subscription for %s: %v", sr.Subject, err)
+			s.sendSubscriptionResponseErr(m.Reply, err)
+			return
+		}
+		wsub.Lock()
+		s.wildcards.add(wsub)
+		// Add a member for each existing channel matching the subject.
+		for name, c := range s.channels.getAll() {
+			if !s.wildcards.matches(wsub, name) {
+				continue
+			}
+			if _, err = s.addWildcardMember(wsub, c, sr); err != nil {
+				break
+			}
+		}
+	} else {
+		wsub.Lock()
+		if !wsub.State.IsClosed {
+			wsub.Unlock()
+			s.log.Errorf("[Client:%s] Invalid ClientID in subscription request from %s",
+				sr.ClientID, m.Subject)
+			s.sendSubscriptionResponseErr(m.Reply, ErrDupDurable)
+			return
+		}
+		// Use some of the new options, but ignore the ones regarding
+		// start position.
+		wsub.State.Inbox = sr.Inbox
+		wsub.State.MaxInFlight = sr.MaxInFlight
+		wsub.State.MaxInFlightBytes = sr.MaxInFlightBytes
+		wsub.State.AckWaitInSecs = sr.AckWaitInSecs
+		wsub.State.ReplaySpeedPercent = sr.ReplaySpeedPercent
+		wsub.State.DeliveryRate = sr.DeliveryRate
+		wsub.State.DeliveryBurst = sr.DeliveryBurst
+		wsub.initialized = false
+		s.wildcards.setOnline(wsub, ackInbox)
+		err = s.store.UpdateWildcardSub(&wsub.WildcardSub)
+		for name, sub := range wsub.members {
+			if err != nil {
+				break
+			}
+			c := s.channels.get(name)
+			s.resumeDurable(sub, sr, ackInbox)
+			err = s.updateDurable(c.ss, sub)
+			if err == nil {
+				s.monMu.Lock()
+				s.numSubs++
+				s.monMu.Unlock()
+			}
+		}
+	}
+	if err != nil {
+		wsub.Unlock()
+		// Try to undo what has been done.
+		s.closeMu.Lock()
+		wsub.Lock()
+		s.removeWildcardSub(wsub, isNew)
+		wsub.Unlock()
+		s.closeMu.Unlock()
+		s.log.Errorf("Unable to add subscription for %s: %v", sr.Subject, err)
+		s.sendSubscriptionResponseErr(m.Reply, err)
+		return
+	}
+	numChannels := len(wsub.members)
+	wsub.Unlock()
+
+	if s.isDebug() {
+		action := "Started new"
+		if !isNew {
+			action = "Resumed"
+		}
+		s.log.Debugf("[Client:%s] %s wildcard subscription, subject=%s, inbox=%s, durable=%s, subid=%d, channels=%d",
+			sr.ClientID, action, sr.Subject, sr.Inbox, sr.DurableName, wsub.State.ID, numChannels)
+	}
+	if isNew && sr.DurableName != "" {
+		s.auditf(audit.EventDurableCreate, sr.ClientID, "%s", durableDetails(sr.Subject, sr.DurableName, ""))
+	}
+
+	// Create a non-error response
+	resp := &pb.SubscriptionResponse{AckInbox: s.acksSubsPrefix + ackInbox}
+	b, _ := resp.Marshal()
+	s.ncs.Publish(m.Reply, b)
+
+	// Now that we have sent the response, allow messages to be sent to
+	// the members, including those added in the meantime.
+	var members []*subState
+	wsub.Lock()
+	wsub.initialized = true
+	for _, sub := range wsub.members {
+		sub.Lock()
+		if sub.ClientID != "" {
+			sub.initialized = true
+			members = append(members, sub)
+		}
+		sub.Unlock()
+	}
+	isDurable := wsub.State.IsDurable
+	wsub.Unlock()
+	for _, sub := range members {
+		s.subStartCh <- &subStartInfo{c: s.channels.get(sub.subject), sub: sub, isDurable: isDurable}
+	}
+}
+
+// addWildcardMember creates the member of the wildcard subscription `wsub`
+// on channel `c`, unless it already exists or the client is not allowed to
+// subscribe to this channel. If `sr` is not nil, it sets the start position
+// of the member, otherwise the member starts with the first message of the
+// channel. Returns the member if it has been added to the channel's
+// subscriptions, that is if `wsub` is online.
+// wsub's lock held on entry.
+func (s *StanServer) addWildcardMember(wsub *wildcardSub, c *channel, sr *spb.SubscriptionRequest) (*subState, error) {
+	if wsub.removed || wsub.members[c.name] != nil {
+		return nil, nil
+	}
+	state := wsub.State
+	if !s.getPermissions().canSubscribe(state.ClientID, c.name) {
+		return nil, nil
+	}
+	sub := &subState{
+		SubState: spb.SubState{
+			ClientID:           state.ClientID,
+			Inbox:              state.Inbox,
+			AckInbox:           state.AckInbox,
+			MaxInFlight:        state.MaxInFlight,
+			MaxInFlightBytes:   state.MaxInFlightBytes,
+			AckWaitInSecs:      state.AckWaitInSecs,
+			DurableName:        state.DurableName,
+			IsDurable:          state.IsDurable,
+			WildcardID:         state.ID,
+			ReplaySpeedPercent: state.ReplaySpeedPercent,
+			DeliveryRate:       state.DeliveryRate,
+			DeliveryBurst:      state.DeliveryBurst,
+		},
+		subject:     c.name,
+		ackWait:     computeAckWait(state.AckWaitInSecs),
+		acksPending: make(map[uint64]int64),
+		store:       c.store.Subs,
+		chStats:     &c.stats,
+		initialized: wsub.initialized,
+	}
+	if sr != nil {
+		if _, err := s.setSubStartSequence(c, sub, sr); err != nil {
+			return nil, err
+		}
+	}
+	// The client may have been closed before the wildcard subscription
+	// has been updated.
+	online := !state.IsClosed && s.clients.addSub(state.ClientID, sub)
+	if !online {
+		if !state.IsDurable {
+			return nil, nil
+		}
+		sub.IsClosed = true
+	}
+	if err := sub.store.CreateSub(&sub.SubState); err != nil {
+		if online {
+			s.clients.removeSub(state.ClientID, sub)
+		}
+		return nil, err
+	}
+	wsub.members[c.name] = sub
+	if !online {
+		// The member will be added to the channel's subscriptions when
+		// the durable is resumed.
+		sub.ClientID = ""
+		return nil, nil
+	}
+	c.ss.Lock()
+	c.ss.updateState(sub)
+	c.ss.Unlock()
+	s.monMu.Lock()
+	s.numSubs++
+	s.monMu.Unlock()
+	return sub, nil
+}
+
+// addWildcardMembers adds the members of the wildcard subscriptions matching
+// the new channel `c`.
+func (s *StanServer) addWildcardMembers(c *channel) {
+	for _, wsub := range s.wildcards.match(c.name) {
+		wsub.Lock()
+		sub, err := s.addWildcardMember(wsub, c, nil)
+		if err != nil {
+			s.log.Errorf("[Client:%s] Unable to add channel %s to wildcard subscription on %s: %v",
+				wsub.State.ClientID, c.name, wsub.Subject, err)
+		}
+		wsub.Unlock()
+		if sub != nil {
+			// Messages may have been stored since the channel was created.
+			s.sendAvailableMessages(c, sub)
+		}
+	}
+}
+
+// removeWildcardSub removes the members of the wildcard subscription `wsub`
+// from their channel and client. The wildcard subscription is deleted,
+// unless it is a durable and `unsubscribe` is false, in which case it
+// becomes offline.
+// wsub's lock and s.closeMu held on entry.
+func (s *StanServer) removeWildcardSub(wsub *wildcardSub, unsubscribe bool) {
+	state := wsub.State
+	deleted := unsubscribe || !state.IsDurable
+	for name, sub := range wsub.members {
+		sub.RLock()
+		online, removed := sub.ClientID != "", sub.removed
+		sub.RUnlock()
+		if online {
+			c := s.channels.get(name)
+			s.clients.removeSub(state.ClientID, sub)
+			c.ss.Remove(c, sub, unsubscribe)
+			s.monMu.Lock()
+			s.numSubs--
+			s.monMu.Unlock()
+		} else if !removed && deleted {
+			// Offline member, not part of the channel's subscriptions.
+			if err := sub.store.DeleteSub(sub.ID); err != nil {
+				s.log.Errorf("Error deleting subscription subid=%d, subject=%s, err=%v", sub.ID, name, err)
+			}
+		}
+	}
+	var err error
+	if deleted {
+		wsub.removed = true
+		wsub.members = nil
+		s.wildcards.remove(wsub)
+		err = s.store.DeleteWildcardSub(state.ID)
+	} else {
+		s.wildcards.setOffline(wsub)
+		err = s.store.UpdateWildcardSub(&wsub.WildcardSub)
+	}
+	if err != nil {
+		s.log.Errorf("[Client:%s] Error updating wildcard subscription subid=%d, subject=%s, err=%v",
+			state.ClientID, state.ID, wsub.Subject, err)
+	}
+	if s.isDebug() {
+		action := "Removed"
+		if !deleted {
+			action = "Suspended"
+		}
+		s.log.Debugf("[Client:%s] %s wildcard subscription, subject=%s, inbox=%s, durable=%s, subid=%d",
+			state.ClientID, action, wsub.Subject, state.Inbox, state.DurableName, state.ID)
+	}
+}
+
+// performWildcardUnsubOrClose either schedules the request to the wildcard
+// subscription's AckInbox subscriber, or processes the request in place.
+func (s *StanServer) performWildcardUnsubOrClose(reqType spb.CtrlMsg_Type, schedule bool, m *nats.Msg, req *spb.UnsubscribeRequest) {
+	action := "unsub"
+	unsubscribe := true
+	if reqType == spb.CtrlMsg_SubClose {
+		action = "sub close"
+		unsubscribe = false
+	}
+	wsub := s.wildcards.lookupByAckInbox(strings.TrimPrefix(req.Inbox, s.acksSubsPrefix))
+	if wsub == nil {
+		s.log.Errorf("[Client:%s] %s request for missing inbox %s",
+			req.ClientID, action, req.Inbox)
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidSub)
+		return
+	}
+
+	// Lock for the remainder of the function
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	wsub.Lock()
+	defer wsub.Unlock()
+
+	if wsub.removed || wsub.State.IsClosed {
+		s.sendSubscriptionResponseErr(m.Reply, ErrInvalidSub)
+		return
+	}
+	if schedule {
+		// We send a single message to a single handler, no need for ref count
+		_, ctrlNatsMsg := s.createCtrlMsg(reqType, false, m.Reply, m.Data)
+		ctrlNatsMsg.Subject = s.acksSubsPrefix + wsub.State.AckInbox
+		// In case of error, process the request in place.
+		if s.ncs.PublishMsg(ctrlNatsMsg) == nil {
+			return
+		}
+	}
+	if wsub.State.ClientID != req.ClientID || !s.clients.isValid(req.ClientID) {
+		s.log.Errorf("[Client:%s] %s request for missing client", req.ClientID, action)
+		s.sendSubscriptionResponseErr(m.Reply, ErrUnknownClient)
+		return
+	}
+	s.removeWildcardSub(wsub, unsubscribe)
+	if unsubscribe && wsub.State.IsDurable {
+		s.auditf(audit.EventDurableDelete, req.ClientID, "%s", durableDetails(wsub.Subject, wsub.State.DurableName, ""))
+	}
+
+	// Create a non-error response
+	resp := &pb.SubscriptionResponse{AckInbox: req.Inbox}
+	b, _ := resp.Marshal()
+	s.ncs.Publish(m.Reply, b)
+}
+
+// closeClientWildcardSubs updates the wildcard subscriptions of the closed
+// client `clientID`: durables become offline, the others are deleted.
+// s.closeMu held on entry.
+func (s *StanServer) closeClientWildcardSubs(clientID string) {
+	for _, wsub := range s.wildcards.lookupByClient(clientID) {
+		wsub.Lock()
+		if !wsub.removed && !wsub.State.IsClosed {
+			s.removeWildcardSub(wsub, false)
+		}
+		wsub.Unlock()
+	}
+}
+
+// processRecoveredWildcardSubs reconstructs the wildcard subscriptions on
+// restart. Their members are attached while processing the channels.
+func (s *StanServer) processRecoveredWildcardSubs(subs []*spb.WildcardSub) {
+	for _, rs := range subs {
+		wsub := &wildcardSub{
+			WildcardSub: *rs,
+			members:     make(map[string]*subState),
+			initialized: true,
+		}
+		if !wsub.State.IsClosed && !s.clients.isValid(wsub.State.ClientID) {
+			// The client is gone, a durable becomes offline, otherwise
+			// the wildcard subscription is deleted, along with its
+			// members when they are recovered.
+			if !wsub.State.IsDurable {
+				if err := s.store.DeleteWildcardSub(wsub.State.ID); err != nil {
+					s.log.Errorf("Error deleting wildcard subscription subid=%d, subject=%s, err=%v",
+						wsub.State.ID, wsub.Subject, err)
+				}
+				continue
+			}
+			wsub.State.IsClosed = true
+		}
+		s.wildcards.add(wsub)
+	}
+}
+
+// recoverWildcardMember attaches the recovered subscription `sub` to its
+// wildcard subscription. Returns true if the member is online, in which
+// case it has been added to the subscriptions of channel `c`.
+func (s *StanServer) recoverWildcardMember(c *channel, sub *subState) bool {
+	wsub := s.wildcards.subs[sub.WildcardID]
+	if wsub == nil {
+		if err := sub.store.DeleteSub(sub.ID); err != nil {
+			s.log.Errorf("Error deleting subscription subid=%d, subject=%s, err=%v", sub.ID, c.name, err)
+		}
+		return false
+	}
+	wsub.members[c.name] = sub
+	if wsub.State.IsClosed || !s.clients.addSub(sub.ClientID, sub) {
+		sub.ClientID = ""
+		return false
+	}
+	c.ss.updateState(sub)
+	s.monMu.Lock()
+	s.numSubs++
+	s.monMu.Unlock()
+	return true
+}
@@ -3,6 +3,7 @@
 package stores
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -31,6 +32,45 @@ type genericStore struct {
 	sublist  *util.Sublist
 	name     string
 	channels map[string]*Channel
+	txMu     sync.Mutex // serializes transactions
+	maxWcID  uint64     // last ID assigned to a wildcard subscription
+}
+
+// txMsgStore is implemented by the message stores that can store the
+// messages of a transaction.
+type txMsgStore interface {
+	MsgStore
+	// beginTx hides the messages stored from now on, and returns the
+	// sequence of the last message stored before the transaction.
+	beginTx() uint64
+	// commitTx makes the messages stored since beginTx visible.
+	commitTx()
+	// rollbackTx removes the messages stored since beginTx.
+	rollbackTx() error
+}
+
+// msgLimitsSetter is implemented by the message stores whose limits can
+// be changed after they have been created.
+type msgLimitsSetter interface {
+	// setLimits replaces the limits of the store, and removes the
+	// messages that exceed the new limits.
+	setLimits(limits *MsgStoreLimits) error
+}
+
+// subLimitsSetter is implemented by the subscription stores whose limits
+// can be changed after they have been created.
+type subLimitsSetter interface {
+	setLimits(limits *SubStoreLimits)
+}
+
+// txLog is implemented by the stores that need to record the transactions
+// in progress to be able to roll them back on recovery.
+type txLog interface {
+	// logTx records the transaction in progress, with the sequence of the
+	// last message before the transaction for each channel involved.
+	logTx(lastSeqs map[string]uint64) error
+	// clearTx records that the transaction has been committed or rolled back.
+	clearTx() error
 }
 
 // Used as the value for the genericSubStore's subs map.
@@ -53,7 +93,8 @@ type genericMsgStore struct {
 	subject    string // Can't be wildcard
 	first      uint64
 	last       uint64
-	lTimestamp int64 // Timestamp of last message
+	lTimestamp int64  // Timestamp of last message
+	txFirst    uint64 // Sequence of the first message of a transaction in progress, if any
 	totalCount int
 	totalBytes uint64
 	hitLimit   bool // indicates if store had to drop messages due to limit
@@ -138,11 +179,30 @@ func (gs *genericStore) getChannelLimits(channel string) *ChannelLimits {
 	return r[len(r)-1].(*ChannelLimits)
 }
 
-// SetLimits sets limits for this store
+// SetLimits sets limits for this store. The new limits, with inheritance
+// applied, are also set to the existing channels.
 func (gs *genericStore) SetLimits(limits *StoreLimits) error {
+	// Do not remove messages due to limits while a transaction is stored.
+	gs.txMu.Lock()
+	defer gs.txMu.Unlock()
+
 	gs.Lock()
-	err := gs.setLimits(limits)
-	gs.Unlock()
+	defer gs.Unlock()
+	if err := gs.setLimits(limits); err != nil {
+		return err
+	}
+	var err error
+	for name, c := range gs.channels {
+		cl := gs.getChannelLimits(name)
+		if ms, ok := c.Msgs.(msgLimitsSetter); ok {
+			if lerr := ms.setLimits(&cl.MsgStoreLimits); lerr != nil && err == nil {
+				err = lerr
+			}
+		}
+		if ss, ok := c.Subs.(subLimitsSetter); ok {
+			ss.setLimits(&cl.SubStoreLimits)
+		}
+	}
 	return err
 }
 
@@ -169,11 +229,113 @@ func (gs *genericStore) AddClient(clientID, hbInbox string) (*Client, error) {
 	return &Client{spb.ClientInfo{ID: clientID, HbInbox: hbInbox}}, nil
 }
 
+// AddClientInfo implements the Store interface
+func (gs *genericStore) AddClientInfo(info *spb.ClientInfo) (*Client, error) {
+	return &Client{*info}, nil
+}
+
 // DeleteClient implements the Store interface
 func (gs *genericStore) DeleteClient(clientID string) error {
 	return nil
 }
 
+// CreateWildcardSub implements the Store interface
+func (gs *genericStore) CreateWildcardSub(sub *spb.WildcardSub) error {
+	gs.Lock()
+	gs.maxWcID++
+	sub.State.ID = gs.maxWcID
+	gs.Unlock()
+	return nil
+}
+
+// UpdateWildcardSub implements the Store interface
+func (gs *genericStore) UpdateWildcardSub(sub *spb.WildcardSub) error {
+	return nil
+}
+
+// DeleteWildcardSub implements the Store interface
+func (gs *genericStore) DeleteWildcardSub(subid uint64) error {
+	return nil
+}
+
+// StoreTx implements the Store interface
+func (gs *genericStore) StoreTx(msgs []*TxMsg) ([]uint64, error) {
+	return gs.storeTx(msgs, nil)
+}
+
+// storeTx stores the messages of a transaction. If `log` is not nil, the
+// transaction is recorded before the first message is stored, and the
+// record is cleared once all messages are flushed or have been removed.
+func (gs *genericStore) storeTx(msgs []*TxMsg, log txLog) ([]uint64, error) {
+	gs.txMu.Lock()
+	defer gs.txMu.Unlock()
+
+	gs.RLock()
+	txStores := make(map[string]txMsgStore)
+	for _, m := range msgs {
+		if _, ok := txStores[m.Channel]; ok {
+			continue
+		}
+		c := gs.channels[m.Channel]
+		if c == nil {
+			gs.RUnlock()
+			return nil, fmt.Errorf("channel %q does not exist", m.Channel)
+		}
+		ms, ok := c.Msgs.(txMsgStore)
+		if !ok {
+			gs.RUnlock()
+			return nil, ErrNotSupported
+		}
+		txStores[m.Channel] = ms
+	}
+	gs.RUnlock()
+
+	lastSeqs := make(map[string]uint64, len(txStores))
+	for name, ms := range txStores {
+		lastSeqs[name] = ms.beginTx()
+	}
+	var err error
+	if log != nil {
+		err = log.logTx(lastSeqs)
+	}
+	seqs := make([]uint64, len(msgs))
+	for i := 0; err == nil && i < len(msgs); i++ {
+		seqs[i], err = txStores[msgs[i].Channel].Store(msgs[i].Data)
+	}
+	if err == nil && log != nil {
+		// Messages must be persisted before the transaction is cleared.
+		for _, ms := range txStores {
+			if err = ms.Flush(); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = log.clearTx()
+		}
+	}
+	if err != nil {
+		rolledBack := true
+		for name, ms := range txStores {
+			if rbErr := ms.rollbackTx(); rbErr != nil {
+				gs.log.Errorf("Unable to roll back transaction on channel %q: %v", name, rbErr)
+				rolledBack = false
+			}
+		}
+		// If the rollback failed, the record is left so that the messages
+		// are removed on recovery.
+		if rolledBack && log != nil {
+			if clErr := log.clearTx(); clErr != nil {
+				gs.log.Errorf("Unable to clear transaction record: %v", clErr)
+			}
+		}
+		return nil, err
+	}
+	for _, ms := range txStores {
+		ms.commitTx()
+	}
+	return seqs, nil
+}
+
 // Close closes all stores
 func (gs *genericStore) Close() error {
 	gs.Lock()
@@ -233,6 +395,39 @@ func (gms *genericMsgStore) createMsg(seq uint64, data []byte) *pb.MsgProto {
 	return m
 }
 
+// beginTx implements the txMsgStore interface.
+func (gms *genericMsgStore) beginTx() uint64 {
+	gms.Lock()
+	gms.txFirst = gms.last + 1
+	last := gms.last
+	gms.Unlock()
+	return last
+}
+
+// commitTx implements the txMsgStore interface.
+func (gms *genericMsgStore) commitTx() {
+	gms.Lock()
+	gms.txFirst = 0
+	gms.Unlock()
+}
+
+// visibleLast returns the sequence of the last message stored, ignoring
+// the messages of a transaction in progress.
+// Lock is assumed held on entry.
+func (gms *genericMsgStore) visibleLast() uint64 {
+	if gms.txFirst > 0 {
+		return gms.txFirst - 1
+	}
+	return gms.last
+}
+
+// isVisible returns false if the message `seq` is part of a transaction
+// in progress.
+// Lock is assumed held on entry.
+func (gms *genericMsgStore) isVisible(seq uint64) bool {
+	return gms.txFirst == 0 || seq < gms.txFirst
+}
+
 // State returns some statistics related to this store
 func (gms *genericMsgStore) State() (numMessages int, byteSize uint64, err error) {
 	gms.RLock()
@@ -258,7 +453,7 @@ func (gms *genericMsgStore) FirstSequence() (uint64, error) {
 // LastSequence returns sequence for last message stored.
 func (gms *genericMsgStore) LastSequence() (uint64, error) {
 	gms.RLock()
-	last := gms.last
+	last := gms.visibleLast()
 	gms.RUnlock()
 	return last, nil
 }
@@ -266,7 +461,7 @@ func (gms *genericMsgStore) LastSequence() (uint64, error) {
 // FirstAndLastSequence returns sequences for the first and last messages stored.
 func (gms *genericMsgStore) FirstAndLastSequence() (uint64, uint64, error) {
 	gms.RLock()
-	first, last := gms.first, gms.last
+	first, last := gms.first, gms.visibleLast()
 	gms.RUnlock()
 	return first, last, nil
 }
@@ -312,6 +507,13 @@ func (gss *genericSubStore) init(log logger.Logger, limits *SubStoreLimits) {
 	gss.subs = make(map[uint64]interface{})
 }
 
+// setLimits implements the subLimitsSetter interface.
+func (gss *genericSubStore) setLimits(limits *SubStoreLimits) {
+	gss.Lock()
+	gss.limits = *limits
+	gss.Unlock()
+}
+
 // CreateSub records a new subscription represented by SubState. On success,
 // it records the subscription's ID in SubState.ID. This ID is to be used
 // by the other SubStore methods.
@@ -322,6 +524,14 @@ func (gss *genericSubStore) CreateSub(sub *spb.SubState) error {
 	return err
 }
 
+// GetLimits returns the limits that apply to this subscriptions store.
+func (gss *genericSubStore) GetLimits() SubStoreLimits {
+	gss.RLock()
+	limits := gss.limits
+	gss.RUnlock()
+	return limits
+}
+
 // UpdateSub updates a given subscription represented by SubState.
 func (gss *genericSubStore) UpdateSub(sub *spb.SubState) error {
 	return nil
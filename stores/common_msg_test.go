@@ -525,3 +525,154 @@ func TestCSFirstAndLastMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestCSStoreTx(t *testing.T) {
+	for _, st := range testStores {
+		st := st
+		t.Run(st.name, func(t *testing.T) {
+			t.Parallel()
+			defer endTest(t, st)
+			s := startTest(t, st)
+			defer s.Close()
+
+			foo := storeCreateChannel(t, s, "foo")
+			bar := storeCreateChannel(t, s, "bar")
+			storeMsg(t, foo, "foo", []byte("first"))
+
+			seqs, err := s.StoreTx([]*TxMsg{
+				{Channel: "foo", Data: []byte("1")},
+				{Channel: "bar", Data: []byte("2")},
+				{Channel: "foo", Data: []byte("3")},
+			})
+			if err != nil {
+				t.Fatalf("Error storing transaction: %v", err)
+			}
+			if !reflect.DeepEqual(seqs, []uint64{2, 1, 3}) {
+				t.Fatalf("Unexpected sequences: %v", seqs)
+			}
+			if m := msgStoreLookup(t, foo.Msgs, 3); m == nil || string(m.Data) != "3" {
+				t.Fatalf("Unexpected message: %v", m)
+			}
+			if m := msgStoreLookup(t, bar.Msgs, 1); m == nil || string(m.Data) != "2" {
+				t.Fatalf("Unexpected message: %v", m)
+			}
+
+			// A transaction with a channel that does not exist fails
+			// before anything is stored.
+			if _, err := s.StoreTx([]*TxMsg{
+				{Channel: "foo", Data: []byte("4")},
+				{Channel: "baz", Data: []byte("5")},
+			}); err == nil {
+				t.Fatal("Expected transaction to fail")
+			}
+			if last := msgStoreLastSequence(t, foo.Msgs); last != 3 {
+				t.Fatalf("Expected last sequence to be 3, got %v", last)
+			}
+
+			// Messages of a transaction in progress are not visible, and
+			// removed on rollback.
+			checkRollback := func(cs *Channel, expectedFirst, expectedLast uint64) {
+				count, size := msgStoreState(t, cs.Msgs)
+				ms := cs.Msgs.(txMsgStore)
+				if last := ms.beginTx(); last != expectedLast {
+					stackFatalf(t, "Expected last sequence to be %v, got %v", expectedLast, last)
+				}
+				for i := 0; i < 3; i++ {
+					seq, err := ms.Store([]byte("tx"))
+					if err != nil {
+						stackFatalf(t, "Error storing message: %v", err)
+					}
+					if m := msgStoreLookup(t, ms, seq); m != nil {
+						stackFatalf(t, "Message %v should not be visible: %v", seq, m)
+					}
+				}
+				if first, last := msgStoreFirstAndLastSequence(t, ms); last != expectedLast {
+					stackFatalf(t, "Unexpected first and last sequences: %v, %v", first, last)
+				}
+				if m := msgStoreLastMsg(t, ms); expectedLast > 0 && (m == nil || m.Sequence != expectedLast) {
+					stackFatalf(t, "Unexpected last message: %v", m)
+				}
+				if err := ms.rollbackTx(); err != nil {
+					stackFatalf(t, "Error on rollback: %v", err)
+				}
+				if first, last := msgStoreFirstAndLastSequence(t, ms); first != expectedFirst || last != expectedLast {
+					stackFatalf(t, "Unexpected first and last sequences: %v, %v", first, last)
+				}
+				if n, b := msgStoreState(t, ms); n != count || b != size {
+					stackFatalf(t, "Expected state to be %v/%v, got %v/%v", count, size, n, b)
+				}
+				// Sequences are reused
+				m := storeMsg(t, cs, "", []byte("after"))
+				if m.Sequence != expectedLast+1 || string(m.Data) != "after" {
+					stackFatalf(t, "Unexpected message: %v", m)
+				}
+			}
+			checkRollback(foo, 1, 3)
+			checkRollback(storeCreateChannel(t, s, "empty"), 0, 0)
+		})
+	}
+}
+
+func TestCSStoreTxLimits(t *testing.T) {
+	for _, st := range testStores {
+		st := st
+		t.Run(st.name, func(t *testing.T) {
+			t.Parallel()
+			defer endTest(t, st)
+			s := startTest(t, st)
+			defer s.Close()
+
+			limits := testDefaultStoreLimits
+			limits.MaxMsgs = 3
+			if err := s.SetLimits(&limits); err != nil {
+				t.Fatalf("Unexpected error setting limits: %v", err)
+			}
+			cs := storeCreateChannel(t, s, "foo")
+			for i := 0; i < 3; i++ {
+				storeMsg(t, cs, "foo", []byte("hello"))
+			}
+			checkFirstAndLast := func(expectedFirst, expectedLast uint64) {
+				if first, last := msgStoreFirstAndLastSequence(t, cs.Msgs); first != expectedFirst || last != expectedLast {
+					stackFatalf(t, "Expected first and last to be %v and %v, got %v and %v",
+						expectedFirst, expectedLast, first, last)
+				}
+			}
+
+			// The channel is at its limit: the messages are not removed
+			// while the transaction is in progress, so rolling it back
+			// does not lose any message.
+			ms := cs.Msgs.(txMsgStore)
+			ms.beginTx()
+			for i := 0; i < 2; i++ {
+				if _, err := ms.Store([]byte("tx")); err != nil {
+					t.Fatalf("Error storing message: %v", err)
+				}
+			}
+			checkFirstAndLast(1, 3)
+			if err := ms.rollbackTx(); err != nil {
+				t.Fatalf("Error on rollback: %v", err)
+			}
+			checkFirstAndLast(1, 3)
+			if n, _ := msgStoreState(t, cs.Msgs); n != 3 {
+				t.Fatalf("Expected 3 messages, got %v", n)
+			}
+			for seq := uint64(1); seq <= 3; seq++ {
+				if m := msgStoreLookup(t, cs.Msgs, seq); m == nil || string(m.Data) != "hello" {
+					t.Fatalf("Unexpected message %v: %v", seq, m)
+				}
+			}
+
+			// The limits are enforced once the transaction is committed.
+			if _, err := s.StoreTx([]*TxMsg{
+				{Channel: "foo", Data: []byte("1")},
+				{Channel: "foo", Data: []byte("2")},
+			}); err != nil {
+				t.Fatalf("Error storing transaction: %v", err)
+			}
+			checkFirstAndLast(3, 5)
+			if n, _ := msgStoreState(t, cs.Msgs); n != 3 {
+				t.Fatalf("Expected 3 messages, got %v", n)
+			}
+		})
+	}
+}
@@ -310,6 +310,12 @@ func TestGSNoOps(t *testing.T) {
 	if err := gs.DeleteClient("me"); err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
+	if err := gs.UpdateWildcardSub(&spb.WildcardSub{State: &spb.SubState{}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := gs.DeleteWildcardSub(1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 	if err := gs.Close(); err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -690,8 +696,20 @@ func TestCSClientAPIs(t *testing.T) {
 			// Adding it another time should not return an error
 			storeAddClient(t, s, "client2", "hbInbox")
 
-			// Add a client
-			storeAddClient(t, s, "client3", "hbInbox")
+			// Add a client with metadata
+			client3 := spb.ClientInfo{
+				ID:          "client3",
+				HbInbox:     "hbInbox",
+				Name:        "app",
+				Version:     "1.0",
+				Hostname:    "host",
+				Language:    "go",
+				Labels:      []string{"env=prod", "region=us"},
+				ConnectTime: time.Now().UnixNano(),
+			}
+			if _, err := s.AddClientInfo(&client3); err != nil {
+				t.Fatalf("Error adding client: %v", err)
+			}
 
 			// Add a client then..
 			storeAddClient(t, s, "client4", "hbInbox")
@@ -714,6 +732,74 @@ func TestCSClientAPIs(t *testing.T) {
 					if c.ID != "client2" && c.ID != "client3" {
 						t.Fatalf("Unexpected recovered client: %v", c.ID)
 					}
+					// The metadata must have been recovered too.
+					if c.ID == "client3" && !reflect.DeepEqual(c.ClientInfo, client3) {
+						t.Fatalf("Expected client %v, got %v", client3, c.ClientInfo)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCSWildcardSubAPIs(t *testing.T) {
+	for _, st := range testStores {
+		st := st
+		t.Run(st.name, func(t *testing.T) {
+			t.Parallel()
+			defer endTest(t, st)
+			s := startTest(t, st)
+			defer s.Close()
+
+			if err := s.Init(&spb.ServerInfo{ClusterID: "id"}); err != nil {
+				t.Fatalf("Error on init: %v", err)
+			}
+			newWcSub := func(s Store, subject, durable string) *spb.WildcardSub {
+				sub := &spb.WildcardSub{
+					State:   &spb.SubState{ClientID: "me", Inbox: "inbox", DurableName: durable, IsDurable: durable != ""},
+					Subject: subject,
+				}
+				if err := s.CreateWildcardSub(sub); err != nil {
+					t.Fatalf("Error creating wildcard sub: %v", err)
+				}
+				return sub
+			}
+			foo := newWcSub(s, "foo.*", "dur")
+			bar := newWcSub(s, "bar.>", "")
+			if foo.State.ID == 0 || bar.State.ID == 0 || foo.State.ID == bar.State.ID {
+				t.Fatalf("Unexpected IDs: %v, %v", foo.State.ID, bar.State.ID)
+			}
+			// Updating or deleting a subscription that does not exist is not an error.
+			if err := s.UpdateWildcardSub(&spb.WildcardSub{State: &spb.SubState{ID: 100}}); err != nil {
+				t.Fatalf("Error updating wildcard sub: %v", err)
+			}
+			if err := s.DeleteWildcardSub(100); err != nil {
+				t.Fatalf("Error deleting wildcard sub: %v", err)
+			}
+			foo.State.ClientID = ""
+			foo.State.IsClosed = true
+			if err := s.UpdateWildcardSub(foo); err != nil {
+				t.Fatalf("Error updating wildcard sub: %v", err)
+			}
+			if err := s.DeleteWildcardSub(bar.State.ID); err != nil {
+				t.Fatalf("Error deleting wildcard sub: %v", err)
+			}
+
+			if st.recoverable {
+				// Restart the store
+				s.Close()
+
+				s, state := testReOpenStore(t, st, nil)
+				defer s.Close()
+				if state == nil {
+					t.Fatal("Expected state to be recovered")
+				}
+				if len(state.WildcardSubs) != 1 || !reflect.DeepEqual(state.WildcardSubs[0], foo) {
+					t.Fatalf("Expected %v to be recovered, got %v", foo, state.WildcardSubs)
+				}
+				// IDs are not reused after a restart.
+				if baz := newWcSub(s, "baz.*", ""); baz.State.ID <= bar.State.ID {
+					t.Fatalf("Expected ID to be greater than %v, got %v", bar.State.ID, baz.State.ID)
 				}
 			}
 		})
@@ -896,6 +982,66 @@ func TestCSPerChannelLimits(t *testing.T) {
 	}
 }
 
+func TestCSSetLimitsOnExistingChannels(t *testing.T) {
+	for _, st := range testStores {
+		st := st
+		t.Run(st.name, func(t *testing.T) {
+			t.Parallel()
+			defer endTest(t, st)
+			s := startTest(t, st)
+			defer s.Close()
+
+			foo := storeCreateChannel(t, s, "foo")
+			bar := storeCreateChannel(t, s, "bar")
+			for i := 0; i < 10; i++ {
+				storeMsg(t, foo, "foo", []byte("hello"))
+				storeMsg(t, bar, "bar", []byte("hello"))
+			}
+
+			// The new limits apply to the existing channels, based on
+			// the per-channel inheritance.
+			limits := testDefaultStoreLimits
+			limits.MaxMsgs = 5
+			limits.MaxDurableInactivity = time.Hour
+			limits.AddPerChannel("bar", &ChannelLimits{MsgStoreLimits: MsgStoreLimits{MaxMsgs: 2}})
+			if err := s.SetLimits(&limits); err != nil {
+				t.Fatalf("Unexpected error setting limits: %v", err)
+			}
+			checkFirstAndLast := func(cs *Channel, expectedFirst, expectedLast uint64) {
+				if first, last := msgStoreFirstAndLastSequence(t, cs.Msgs); first != expectedFirst || last != expectedLast {
+					stackFatalf(t, "Expected first and last to be %v and %v, got %v and %v",
+						expectedFirst, expectedLast, first, last)
+				}
+			}
+			checkFirstAndLast(foo, 6, 10)
+			checkFirstAndLast(bar, 9, 10)
+			storeMsg(t, foo, "foo", []byte("hello"))
+			checkFirstAndLast(foo, 7, 11)
+			if l := bar.Subs.GetLimits(); l.MaxDurableInactivity != time.Hour {
+				t.Fatalf("Expected max durable inactivity to be inherited, got %v", l.MaxDurableInactivity)
+			}
+
+			// An age limit set on existing channels expires messages.
+			limits.MaxAge = 100 * time.Millisecond
+			if err := s.SetLimits(&limits); err != nil {
+				t.Fatalf("Unexpected error setting limits: %v", err)
+			}
+			waitForCount := func(cs *Channel) {
+				timeout := time.Now().Add(5 * time.Second)
+				for time.Now().Before(timeout) {
+					if n, _ := msgStoreState(t, cs.Msgs); n == 0 {
+						return
+					}
+					time.Sleep(15 * time.Millisecond)
+				}
+				stackFatalf(t, "Messages did not expire")
+			}
+			waitForCount(foo)
+			waitForCount(bar)
+		})
+	}
+}
+
 func TestCSNegativeLimit(t *testing.T) {
 	for _, st := range testStores {
 		st := st
@@ -8,6 +8,7 @@ import (
 	"hash/crc32"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -48,6 +49,12 @@ const (
 	// Name of the server file.
 	serverFileName = "server" + datSuffix
 
+	// Name of the file recording the transaction in progress.
+	txFileName = "tx" + datSuffix
+
+	// Name of the wildcard subscriptions file.
+	wildcardsFileName = "wildcards" + datSuffix
+
 	// Number of bytes required to store a CRC-32 checksum
 	crcSize = crc32.Size
 
@@ -419,9 +426,12 @@ type FileStore struct {
 	fm            *filesManager
 	serverFile    *file
 	clientsFile   *file
+	txFile        *file
+	wildcardsFile *file
 	opts          FileStoreOptions
 	compactItvl   time.Duration
 	clients       map[string]*Client
+	wildcardSubs  map[uint64]*spb.WildcardSub
 	addClientRec  spb.ClientInfo
 	delClientRec  spb.ClientDelete
 	cliFileSize   int64
@@ -1098,7 +1108,11 @@ func NewFileStore(log logger.Logger, rootDir string, limits *StoreLimits, option
 		return nil, fmt.Errorf("for %v stores, root directory must be specified", TypeFile)
 	}
 
-	fs := &FileStore{opts: DefaultFileStoreOptions, clients: make(map[string]*Client)}
+	fs := &FileStore{
+		opts:         DefaultFileStoreOptions,
+		clients:      make(map[string]*Client),
+		wildcardSubs: make(map[uint64]*spb.WildcardSub),
+	}
 	if err := fs.init(TypeFile, log, limits); err != nil {
 		return nil, err
 	}
@@ -1146,6 +1160,7 @@ func (fs *FileStore) Recover() (*RecoveredState, error) {
 		recoveredState    *RecoveredState
 		serverInfo        *spb.ServerInfo
 		recoveredClients  []*Client
+		recoveredWcSubs   []*spb.WildcardSub
 		recoveredChannels = make(map[string]*RecoveredChannel)
 		channels          []os.FileInfo
 	)
@@ -1158,6 +1173,14 @@ func (fs *FileStore) Recover() (*RecoveredState, error) {
 		if fs.clientsFile != nil {
 			fs.fm.unlockFile(fs.clientsFile)
 		}
+		// The transaction file is only needed when storing a transaction,
+		// it will be reopened then.
+		if fs.txFile != nil {
+			fs.fm.closeLockedFile(fs.txFile)
+		}
+		if fs.wildcardsFile != nil {
+			fs.fm.closeLockedFile(fs.wildcardsFile)
+		}
 	}()
 
 	// Open/Create the server file (note that this file must not be opened,
@@ -1173,6 +1196,20 @@ func (fs *FileStore) Recover() (*RecoveredState, error) {
 		return nil, err
 	}
 
+	// Open/Create the transaction file (not in APPEND mode since it is
+	// truncated when a transaction completes).
+	fs.txFile, err = fs.fm.createFile(txFileName, os.O_RDWR|os.O_CREATE, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open/Create the wildcard subscriptions file (not in APPEND mode since
+	// its content is replaced on every update).
+	fs.wildcardsFile, err = fs.fm.createFile(wildcardsFileName, os.O_RDWR|os.O_CREATE, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Recover the server file.
 	serverInfo, err = fs.recoverServerInfo(fs.serverFile.handle)
 	if err != nil {
@@ -1190,6 +1227,12 @@ func (fs *FileStore) Recover() (*RecoveredState, error) {
 		return nil, err
 	}
 
+	// Recover the wildcard subscriptions file
+	recoveredWcSubs, err = fs.recoverWildcardSubs(fs.wildcardsFile.handle)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the channels (there are subdirectories of rootDir)
 	channels, err = ioutil.ReadDir(fs.fm.rootDir)
 	if err != nil {
@@ -1241,11 +1284,16 @@ func (fs *FileStore) Recover() (*RecoveredState, error) {
 		default:
 		}
 	}
+	// Remove the messages of a transaction that did not complete.
+	if err := fs.recoverTx(); err != nil {
+		return nil, err
+	}
 	// Create the recovered state to return
 	recoveredState = &RecoveredState{
-		Info:     serverInfo,
-		Clients:  recoveredClients,
-		Channels: recoveredChannels,
+		Info:         serverInfo,
+		Clients:      recoveredClients,
+		Channels:     recoveredChannels,
+		WildcardSubs: recoveredWcSubs,
 	}
 	fs.log.Noticef("Recovered %v channels", len(fs.channels))
 	return recoveredState, nil
@@ -1428,6 +1476,208 @@ func (fs *FileStore) recoverClients(file *os.File) ([]*Client, error) {
 	return clients, nil
 }
 
+// recoverTx reads the transaction file and, if a transaction was in
+// progress, removes its messages from the recovered channels.
+// The file is assumed to be locked on entry.
+func (fs *FileStore) recoverTx() error {
+	file := fs.txFile.handle
+	buf, size, _, err := readRecord(file, nil, false, fs.crcTable, fs.opts.DoCRC)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	rec := &spb.TxRecord{}
+	if err := rec.Unmarshal(buf[:size]); err != nil {
+		return err
+	}
+	for _, txc := range rec.Channels {
+		c := fs.channels[txc.Name]
+		if c == nil {
+			continue
+		}
+		ms := c.Msgs.(*FileMsgStore)
+		ms.Lock()
+		err := ms.truncate(txc.LastSeq)
+		ms.Unlock()
+		if err != nil {
+			return fmt.Errorf("unable to roll back transaction on channel %q: %v", txc.Name, err)
+		}
+	}
+	fs.log.Noticef("Rolled back incomplete transaction on %v channel(s)", len(rec.Channels))
+	return fs.truncateTxFile()
+}
+
+// StoreTx implements the Store interface
+func (fs *FileStore) StoreTx(msgs []*TxMsg) ([]uint64, error) {
+	return fs.storeTx(msgs, fs)
+}
+
+// logTx implements the txLog interface. The transaction record replaces
+// the content of the transaction file.
+func (fs *FileStore) logTx(lastSeqs map[string]uint64) error {
+	rec := &spb.TxRecord{Channels: make([]*spb.TxChannel, 0, len(lastSeqs))}
+	for name, lastSeq := range lastSeqs {
+		rec.Channels = append(rec.Channels, &spb.TxChannel{Name: name, LastSeq: lastSeq})
+	}
+	fs.Lock()
+	defer fs.Unlock()
+	if err := fs.lockTxFile(); err != nil {
+		return err
+	}
+	defer fs.fm.unlockFile(fs.txFile)
+	if err := fs.truncateTxFile(); err != nil {
+		return err
+	}
+	f := fs.txFile.handle
+	// TxRecord record is not typed. We also don't pass a reusable buffer.
+	if _, _, err := writeRecord(f, nil, recNoType, rec, rec.Size(), fs.crcTable); err != nil {
+		return err
+	}
+	if fs.opts.DoSync {
+		return f.Sync()
+	}
+	return nil
+}
+
+// clearTx implements the txLog interface.
+func (fs *FileStore) clearTx() error {
+	fs.Lock()
+	defer fs.Unlock()
+	if err := fs.lockTxFile(); err != nil {
+		return err
+	}
+	err := fs.truncateTxFile()
+	if err == nil && fs.opts.DoSync {
+		err = fs.txFile.handle.Sync()
+	}
+	fs.fm.unlockFile(fs.txFile)
+	return err
+}
+
+// lockTxFile locks the transaction file, which is created if this store
+// has not been recovered.
+// Store lock is assumed held on entry.
+func (fs *FileStore) lockTxFile() error {
+	var err error
+	if fs.txFile == nil {
+		fs.txFile, err = fs.fm.createFile(txFileName, os.O_RDWR|os.O_CREATE, nil)
+	} else {
+		_, err = fs.fm.lockFile(fs.txFile)
+	}
+	return err
+}
+
+// truncateTxFile removes the transaction record, if any, from the
+// transaction file.
+// The file is assumed to be locked on entry.
+func (fs *FileStore) truncateTxFile() error {
+	f := fs.txFile.handle
+	// Truncate the file (4 is the size of the fileVersion record)
+	if err := f.Truncate(4); err != nil {
+		return err
+	}
+	// Move offset to 4 (truncate does not do that)
+	_, err := f.Seek(4, 0)
+	return err
+}
+
+// recoverWildcardSubs reads the wildcard subscriptions file and returns
+// the recovered subscriptions.
+func (fs *FileStore) recoverWildcardSubs(file *os.File) ([]*spb.WildcardSub, error) {
+	buf, size, _, err := readRecord(file, nil, false, fs.crcTable, fs.opts.DoCRC)
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	rec := &spb.WildcardSubs{}
+	if err := rec.Unmarshal(buf[:size]); err != nil {
+		return nil, err
+	}
+	for _, sub := range rec.Subs {
+		fs.wildcardSubs[sub.State.ID] = sub
+	}
+	fs.maxWcID = rec.MaxID
+	return rec.Subs, nil
+}
+
+// CreateWildcardSub implements the Store interface
+func (fs *FileStore) CreateWildcardSub(sub *spb.WildcardSub) error {
+	fs.Lock()
+	defer fs.Unlock()
+	fs.maxWcID++
+	sub.State.ID = fs.maxWcID
+	return fs.setWildcardSub(sub)
+}
+
+// UpdateWildcardSub implements the Store interface
+func (fs *FileStore) UpdateWildcardSub(sub *spb.WildcardSub) error {
+	fs.Lock()
+	defer fs.Unlock()
+	if fs.wildcardSubs[sub.State.ID] == nil {
+		return nil
+	}
+	return fs.setWildcardSub(sub)
+}
+
+// DeleteWildcardSub implements the Store interface
+func (fs *FileStore) DeleteWildcardSub(subid uint64) error {
+	fs.Lock()
+	defer fs.Unlock()
+	if fs.wildcardSubs[subid] == nil {
+		return nil
+	}
+	delete(fs.wildcardSubs, subid)
+	return fs.writeWildcardSubs()
+}
+
+// setWildcardSub records a copy of `sub` and persists the wildcard
+// subscriptions.
+// Store lock is assumed held on entry.
+func (fs *FileStore) setWildcardSub(sub *spb.WildcardSub) error {
+	state := *sub.State
+	fs.wildcardSubs[state.ID] = &spb.WildcardSub{State: &state, Subject: sub.Subject}
+	return fs.writeWildcardSubs()
+}
+
+// writeWildcardSubs replaces the content of the wildcard subscriptions
+// file with the current wildcard subscriptions.
+// Store lock is assumed held on entry.
+func (fs *FileStore) writeWildcardSubs() error {
+	var err error
+	if fs.wildcardsFile == nil {
+		fs.wildcardsFile, err = fs.fm.createFile(wildcardsFileName, os.O_RDWR|os.O_CREATE, nil)
+	} else {
+		_, err = fs.fm.lockFile(fs.wildcardsFile)
+	}
+	if err != nil {
+		return err
+	}
+	defer fs.fm.unlockFile(fs.wildcardsFile)
+	rec := &spb.WildcardSubs{Subs: make([]*spb.WildcardSub, 0, len(fs.wildcardSubs)), MaxID: fs.maxWcID}
+	for _, sub := range fs.wildcardSubs {
+		rec.Subs = append(rec.Subs, sub)
+	}
+	f := fs.wildcardsFile.handle
+	// Truncate the file (4 is the size of the fileVersion record)
+	if err := f.Truncate(4); err != nil {
+		return err
+	}
+	// Move offset to 4 (truncate does not do that)
+	if _, err := f.Seek(4, 0); err != nil {
+		return err
+	}
+	// WildcardSubs record is not typed. We also don't pass a reusable buffer.
+	if _, _, err := writeRecord(f, nil, recNoType, rec, rec.Size(), fs.crcTable); err != nil {
+		return err
+	}
+	if fs.opts.DoSync {
+		return f.Sync()
+	}
+	return nil
+}
+
 // recoverServerInfo reads the server file and returns a ServerInfo structure
 func (fs *FileStore) recoverServerInfo(file *os.File) (*spb.ServerInfo, error) {
 	info := &spb.ServerInfo{}
@@ -1504,12 +1754,17 @@ func (fs *FileStore) CreateChannel(channel string) (*Channel, error) {
 
 // AddClient implements the Store interface
 func (fs *FileStore) AddClient(clientID, hbInbox string) (*Client, error) {
+	return fs.AddClientInfo(&spb.ClientInfo{ID: clientID, HbInbox: hbInbox})
+}
+
+// AddClientInfo implements the Store interface
+func (fs *FileStore) AddClientInfo(info *spb.ClientInfo) (*Client, error) {
 	fs.Lock()
 	if _, err := fs.fm.lockFile(fs.clientsFile); err != nil {
 		fs.Unlock()
 		return nil, err
 	}
-	fs.addClientRec = spb.ClientInfo{ID: clientID, HbInbox: hbInbox}
+	fs.addClientRec = *info
 	_, size, err := writeRecord(fs.clientsFile.handle, nil, addClient, &fs.addClientRec, fs.addClientRec.Size(), fs.crcTable)
 	if err != nil {
 		fs.fm.unlockFile(fs.clientsFile)
@@ -1519,7 +1774,7 @@ func (fs *FileStore) AddClient(clientID, hbInbox string) (*Client, error) {
 	fs.cliFileSize += int64(size)
 	fs.fm.unlockFile(fs.clientsFile)
 	client := Client{fs.addClientRec}
-	fs.clients[clientID] = &client
+	fs.clients[info.ID] = &client
 	fs.Unlock()
 	return &client, nil
 }
@@ -1605,7 +1860,7 @@ func (fs *FileStore) compactClientFile(orgFileName string) error {
 	buf := _buf[:]
 	// Dump the content of active clients into the temporary file.
 	for _, c := range fs.clients {
-		fs.addClientRec = spb.ClientInfo{ID: c.ID, HbInbox: c.HbInbox}
+		fs.addClientRec = c.ClientInfo
 		buf, size, err = writeRecord(bw, buf, addClient, &fs.addClientRec, fs.addClientRec.Size(), fs.crcTable)
 		if err != nil {
 			return err
@@ -2370,7 +2625,9 @@ func (ms *FileMsgStore) Store(data []byte) (uint64, error) {
 	}
 	fslice.lastSeq = seq
 
-	if ms.limits.MaxMsgs > 0 || ms.limits.MaxBytes > 0 {
+	// Messages are not removed while a transaction is in progress, so
+	// that they can be restored if it is rolled back.
+	if (ms.limits.MaxMsgs > 0 || ms.limits.MaxBytes > 0) && ms.txFirst == 0 {
 		// Enfore limits and update file slice if needed.
 		err = ms.enforceLimits(true, false)
 		if err != nil {
@@ -2485,6 +2742,32 @@ func (ms *FileMsgStore) enforceLimits(reportHitLimit, lockFile bool) error {
 	return nil
 }
 
+// setLimits implements the msgLimitsSetter interface.
+func (ms *FileMsgStore) setLimits(limits *MsgStoreLimits) error {
+	ms.Lock()
+	defer ms.Unlock()
+	if ms.closed {
+		return nil
+	}
+	ms.limits = *limits
+	// Only the file slices created from now on use the new limits.
+	ms.setSliceLimits()
+	if err := ms.enforceLimits(true, true); err != nil {
+		return err
+	}
+	// Compute the next expiration based on the new age limit, and have
+	// the background tasks go routine use it.
+	if maxAge := int64(ms.limits.MaxAge); maxAge > 0 && ms.totalCount > 0 {
+		ms.expireMsgs(time.Now().UnixNano(), maxAge)
+	} else {
+		ms.expiration = 0
+	}
+	if len(ms.bkgTasksWake) == 0 {
+		ms.bkgTasksWake <- true
+	}
+	return nil
+}
+
 // getMsgIndex returns a msgIndex object for message with sequence `seq`,
 // or nil if message is not found (or no longer valid: expired, removed
 // due to limits, etc).
@@ -2733,6 +3016,7 @@ func (ms *FileMsgStore) backgroundTasks() {
 		case <-ms.bkgTasksWake:
 			// wake up from a possible sleep to run the loop
 			ms.RLock()
+			maxAge = int64(ms.limits.MaxAge)
 			nextExpiration = ms.expiration
 			ms.RUnlock()
 		case <-time.After(bkgTasksSleepDuration):
@@ -2745,8 +3029,9 @@ func (ms *FileMsgStore) backgroundTasks() {
 // reading the message from disk.
 // Store write lock is assumed to be held on entry
 func (ms *FileMsgStore) lookup(seq uint64) (*pb.MsgProto, error) {
-	// Reject message for sequence outside valid range
-	if seq < ms.first || seq > ms.last {
+	// Reject message for sequence outside valid range, or part
+	// of a transaction in progress.
+	if seq < ms.first || seq > ms.visibleLast() {
 		return nil, nil
 	}
 	// Check first if it's in the cache.
@@ -2817,6 +3102,13 @@ func (ms *FileMsgStore) FirstMsg() (*pb.MsgProto, error) {
 func (ms *FileMsgStore) LastMsg() (*pb.MsgProto, error) {
 	var err error
 	ms.RLock()
+	if !ms.isVisible(ms.last) {
+		// Do not cache the last visible message, the transaction's
+		// messages will be visible soon.
+		m, err := ms.lookup(ms.visibleLast())
+		ms.RUnlock()
+		return m, err
+	}
 	if ms.lastMsg == nil {
 		ms.lastMsg, err = ms.lookup(ms.last)
 	}
@@ -2835,16 +3127,17 @@ func (ms *FileMsgStore) GetSequenceFromTimestamp(timestamp int64) (uint64, error
 	if ms.first == 0 {
 		return 0, nil
 	}
+	last := ms.visibleLast()
 	// All messages have expired
-	if ms.first > ms.last {
-		return ms.last + 1, nil
+	if ms.first > last {
+		return last + 1, nil
 	}
 	// If we have some state, try to quickly get the sequence
 	if ms.firstMsg != nil && ms.firstMsg.Timestamp >= timestamp {
 		return ms.first, nil
 	}
 	if ms.lastMsg != nil && timestamp >= ms.lastMsg.Timestamp {
-		return ms.last + 1, nil
+		return last + 1, nil
 	}
 
 	smallest := int64(-1)
@@ -2866,6 +3159,9 @@ func (ms *FileMsgStore) GetSequenceFromTimestamp(timestamp int64) (uint64, error
 					mindex = ms.getMsgIndex(slice, seq)
 					if mindex.timestamp >= timestamp {
 						ms.unlockIndexFile(slice)
+						if seq > last {
+							seq = last + 1
+						}
 						return seq, nil
 					}
 				}
@@ -2878,7 +3174,156 @@ func (ms *FileMsgStore) GetSequenceFromTimestamp(timestamp int64) (uint64, error
 	if timestamp < smallest {
 		return ms.first, nil
 	}
-	return ms.last + 1, nil
+	return last + 1, nil
+}
+
+// commitTx implements the txMsgStore interface. The limits, which are
+// not enforced during the transaction, are enforced now.
+func (ms *FileMsgStore) commitTx() {
+	ms.Lock()
+	ms.txFirst = 0
+	ms.enforceLimits(true, true)
+	ms.Unlock()
+}
+
+// rollbackTx implements the txMsgStore interface.
+func (ms *FileMsgStore) rollbackTx() error {
+	ms.Lock()
+	defer ms.Unlock()
+	if ms.txFirst == 0 {
+		return nil
+	}
+	if err := ms.truncate(ms.txFirst - 1); err != nil {
+		return err
+	}
+	ms.txFirst = 0
+	return nil
+}
+
+// truncate removes the messages with a sequence greater than `lastSeq`.
+// Messages up to `lastSeq` that have been removed due to limits are not
+// restored.
+// Store lock is assumed held on entry.
+func (ms *FileMsgStore) truncate(lastSeq uint64) error {
+	if lastSeq >= ms.last {
+		return nil
+	}
+	// Make sure that buffered messages and index records are on disk.
+	if ms.writeSlice != nil {
+		if err := ms.lockFiles(ms.writeSlice); err != nil {
+			return err
+		}
+		err := ms.flush(ms.writeSlice)
+		ms.unlockFiles(ms.writeSlice)
+		if err != nil {
+			return err
+		}
+	}
+	for fseq := ms.lastFSlSeq; fseq >= ms.firstFSlSeq; fseq-- {
+		slice := ms.files[fseq]
+		if slice == nil {
+			continue
+		}
+		if slice.lastSeq <= lastSeq {
+			break
+		}
+		if err := ms.truncateSlice(fseq, slice, lastSeq); err != nil {
+			return err
+		}
+	}
+	ms.last = lastSeq
+	ms.lastMsg = nil
+	if ms.last == 0 {
+		ms.first = 0
+		ms.firstMsg = nil
+	} else if ms.first > ms.last {
+		ms.first = ms.last + 1
+		ms.firstMsg = nil
+	}
+	// Removed messages may be in the cache, and their sequences reused.
+	if ms.cache.tail != nil {
+		ms.evictFromCache(math.MaxInt64)
+	}
+	return nil
+}
+
+// truncateSlice removes the messages with a sequence greater than `lastSeq`
+// from the file slice `fseq`. The slice is removed if it becomes empty
+// and is not the only one.
+// Store lock is assumed held on entry.
+func (ms *FileMsgStore) truncateSlice(fseq int, slice *fileSlice, lastSeq uint64) error {
+	first := slice.firstSeq
+	if first <= lastSeq {
+		first = lastSeq + 1
+	}
+	if err := ms.lockFiles(slice); err != nil {
+		return err
+	}
+	var (
+		size      uint64
+		datOffset int64
+	)
+	for seq := first; seq <= slice.lastSeq; seq++ {
+		mindex := ms.readMsgIndex(slice, seq)
+		if mindex == nil {
+			ms.unlockFiles(slice)
+			return fmt.Errorf("unable to read index of message %v", seq)
+		}
+		if seq == first {
+			datOffset = mindex.offset
+		}
+		size += uint64(mindex.msgSize + msgRecordOverhead)
+	}
+	count := int(slice.lastSeq - first + 1)
+	slice.msgsCount -= count
+	slice.msgsSize -= size
+	ms.totalCount -= count
+	ms.totalBytes -= size
+
+	if slice.msgsCount == slice.rmCount && len(ms.files) > 1 {
+		ms.closeLockedFiles(slice)
+		ms.fm.remove(slice.file)
+		ms.fm.remove(slice.idxFile)
+		os.Remove(slice.file.name)
+		os.Remove(slice.idxFile.name)
+		delete(ms.files, fseq)
+		if fseq == ms.lastFSlSeq {
+			for ms.lastFSlSeq > ms.firstFSlSeq {
+				ms.lastFSlSeq--
+				if _, ok := ms.files[ms.lastFSlSeq]; ok {
+					break
+				}
+			}
+		}
+		if slice == ms.writeSlice {
+			ms.writeSlice = ms.files[ms.lastFSlSeq]
+			if err := ms.lockFiles(ms.writeSlice); err != nil {
+				return err
+			}
+			err := ms.setFile(ms.writeSlice, -1)
+			ms.unlockFiles(ms.writeSlice)
+			return err
+		}
+		return nil
+	}
+	idxOffset := 4 + (int64(first-slice.firstSeq)+int64(slice.rmCount))*msgIndexRecSize
+	err := slice.idxFile.handle.Truncate(idxOffset)
+	if err == nil {
+		err = slice.file.handle.Truncate(datOffset)
+	}
+	if err == nil && slice == ms.writeSlice {
+		err = ms.setFile(slice, datOffset)
+	}
+	ms.unlockFiles(slice)
+	if err != nil {
+		return err
+	}
+	slice.lastSeq = first - 1
+	if slice.msgsCount == slice.rmCount {
+		// Empty slice, the next message stored has sequence `first`.
+		slice.firstSeq = first
+	}
+	return nil
 }
 
 // initCache initializes the message cache
@@ -1302,3 +1302,98 @@ func TestFSPanicOnStoreCloseWhileMsgsExpire(t *testing.T) {
 	time.Sleep(30 * time.Millisecond)
 	fs.Close()
 }
+
+func TestFSStoreTxRollbackFileSlices(t *testing.T) {
+	cleanupDatastore(t)
+	defer cleanupDatastore(t)
+
+	sliceOpt := SliceConfig(2, 0, 0, "")
+	fs := createDefaultFileStore(t, sliceOpt)
+	defer fs.Close()
+
+	foo := storeCreateChannel(t, fs, "foo")
+	bar := storeCreateChannel(t, fs, "bar")
+	for i := 1; i <= 3; i++ {
+		storeMsg(t, foo, "foo", []byte(fmt.Sprintf("msg%v", i)))
+	}
+	count, size := msgStoreState(t, foo.Msgs)
+
+	// Stores messages of a transaction that span several file slices.
+	storeTxMsgs := func(fooMsgs, barMsgs int) {
+		for i := 0; i < fooMsgs; i++ {
+			if _, err := foo.Msgs.Store([]byte("tx")); err != nil {
+				t.Fatalf("Error storing message: %v", err)
+			}
+		}
+		for i := 0; i < barMsgs; i++ {
+			if _, err := bar.Msgs.Store([]byte("tx")); err != nil {
+				t.Fatalf("Error storing message: %v", err)
+			}
+		}
+	}
+	checkFoo := func(cs *Channel) {
+		ms := cs.Msgs.(*FileMsgStore)
+		if first, last := msgStoreFirstAndLastSequence(t, ms); first != 1 || last != 3 {
+			stackFatalf(t, "Unexpected first and last sequences: %v, %v", first, last)
+		}
+		if n, b := msgStoreState(t, ms); n != count || b != size {
+			stackFatalf(t, "Expected state to be %v/%v, got %v/%v", count, size, n, b)
+		}
+		ms.RLock()
+		lastFSlSeq, numSlices := ms.lastFSlSeq, len(ms.files)
+		ms.RUnlock()
+		if lastFSlSeq != 2 || numSlices != 2 {
+			stackFatalf(t, "Expected 2 file slices, got %v (last=%v)", numSlices, lastFSlSeq)
+		}
+		for i := uint64(1); i <= 3; i++ {
+			if m := msgStoreLookup(t, ms, i); m == nil || string(m.Data) != fmt.Sprintf("msg%v", i) {
+				stackFatalf(t, "Unexpected message %v: %v", i, m)
+			}
+		}
+	}
+
+	// Rollback at runtime
+	foo.Msgs.(txMsgStore).beginTx()
+	bar.Msgs.(txMsgStore).beginTx()
+	storeTxMsgs(4, 1)
+	for _, cs := range []*Channel{foo, bar} {
+		if err := cs.Msgs.(txMsgStore).rollbackTx(); err != nil {
+			t.Fatalf("Error on rollback: %v", err)
+		}
+	}
+	checkFoo(foo)
+	if n, _ := msgStoreState(t, bar.Msgs); n != 0 {
+		t.Fatalf("Expected no message in bar, got %v", n)
+	}
+
+	// Rollback on recovery of a transaction that did not complete.
+	if err := fs.logTx(map[string]uint64{"foo": 3, "bar": 0}); err != nil {
+		t.Fatalf("Error recording transaction: %v", err)
+	}
+	foo.Msgs.(txMsgStore).beginTx()
+	bar.Msgs.(txMsgStore).beginTx()
+	storeTxMsgs(4, 2)
+	fs.Close()
+
+	fs, state := openDefaultFileStore(t, sliceOpt)
+	defer fs.Close()
+	foo = getRecoveredChannel(t, state, "foo")
+	bar = getRecoveredChannel(t, state, "bar")
+	checkFoo(foo)
+	if first, last := msgStoreFirstAndLastSequence(t, bar.Msgs); first != 0 || last != 0 {
+		t.Fatalf("Unexpected first and last sequences: %v, %v", first, last)
+	}
+	if m := storeMsg(t, foo, "foo", []byte("msg4")); m.Sequence != 4 {
+		t.Fatalf("Unexpected message: %v", m)
+	}
+	fs.Close()
+
+	// The transaction record has been cleared, so the new message is
+	// recovered.
+	fs, state = openDefaultFileStore(t, sliceOpt)
+	defer fs.Close()
+	foo = getRecoveredChannel(t, state, "foo")
+	if m := msgStoreLookup(t, foo.Msgs, 4); m == nil || string(m.Data) != "msg4" {
+		t.Fatalf("Unexpected message: %v", m)
+	}
+}
@@ -128,6 +128,11 @@ func (sl *StoreLimits) inheritLimits(channel *channelLimitInfo, parentLimits *Ch
 	} else if cl.MaxSubscriptions == 0 {
 		cl.MaxSubscriptions = parentLimits.MaxSubscriptions
 	}
+	if cl.MaxDurableInactivity < 0 {
+		cl.MaxDurableInactivity = 0
+	} else if cl.MaxDurableInactivity == 0 {
+		cl.MaxDurableInactivity = parentLimits.MaxDurableInactivity
+	}
 	if cl.MaxMsgs < 0 {
 		cl.MaxMsgs = 0
 	} else if cl.MaxMsgs == 0 {
@@ -153,6 +158,9 @@ func (sl *StoreLimits) checkGlobalLimits() error {
 	if sl.MaxSubscriptions < 0 {
 		return fmt.Errorf("max subscriptions limit cannot be negative (%v)", sl.MaxSubscriptions)
 	}
+	if sl.MaxDurableInactivity < 0 {
+		return fmt.Errorf("max durable inactivity limit cannot be negative (%v)", sl.MaxDurableInactivity)
+	}
 	if sl.MaxMsgs < 0 {
 		return fmt.Errorf("max messages limit cannot be negative (%v)", sl.MaxMsgs)
 	}
@@ -252,11 +260,13 @@ func getGlobalLimitsPrintLines(limits *ChannelLimits) []string {
 	defMaxMsgs := int64(defaultLimits.MaxMsgs)
 	defMaxBytes := defaultLimits.MaxBytes
 	defMaxAge := defaultLimits.MaxAge
+	defMaxDurInactivity := defaultLimits.MaxDurableInactivity
 	txt := []string{}
 	txt = append(txt, fmt.Sprintf("  Subscriptions: %s", getLimitStr(true, int64(limits.MaxSubscriptions), defMaxSubs, limitCount)))
 	txt = append(txt, fmt.Sprintf("  Messages     : %s", getLimitStr(true, int64(limits.MaxMsgs), defMaxMsgs, limitCount)))
 	txt = append(txt, fmt.Sprintf("  Bytes        : %s", getLimitStr(true, limits.MaxBytes, defMaxBytes, limitBytes)))
 	txt = append(txt, fmt.Sprintf("  Age          : %s", getLimitStr(true, int64(limits.MaxAge), int64(defMaxAge), limitDuration)))
+	txt = append(txt, fmt.Sprintf("  Durable idle : %s", getLimitStr(true, int64(limits.MaxDurableInactivity), int64(defMaxDurInactivity), limitDuration)))
 	return txt
 }
 
@@ -265,10 +275,12 @@ func getChannelLimitsPrintLines(level, maxLevels int, maxLen *int, channelName s
 	plMaxMsgs := int64(parentLimits.MaxMsgs)
 	plMaxBytes := parentLimits.MaxBytes
 	plMaxAge := parentLimits.MaxAge
+	plMaxDurInactivity := parentLimits.MaxDurableInactivity
 	maxSubsOverride := getLimitStr(false, int64(limits.MaxSubscriptions), plMaxSubs, limitCount)
 	maxMsgsOverride := getLimitStr(false, int64(limits.MaxMsgs), plMaxMsgs, limitCount)
 	maxBytesOverride := getLimitStr(false, limits.MaxBytes, plMaxBytes, limitBytes)
 	maxAgeOverride := getLimitStr(false, int64(limits.MaxAge), int64(plMaxAge), limitDuration)
+	maxDurInactivityOverride := getLimitStr(false, int64(limits.MaxDurableInactivity), int64(plMaxDurInactivity), limitDuration)
 	paddingLeft := repeatChar(" ", level)
 	paddingRight := repeatChar(" ", maxLevels-level)
 	txt := []string{}
@@ -285,6 +297,9 @@ func getChannelLimitsPrintLines(level, maxLevels int, maxLen *int, channelName s
 	if maxAgeOverride != "" {
 		txt = append(txt, fmt.Sprintf("%s |-> Age           %s%s", paddingLeft, paddingRight, maxAgeOverride))
 	}
+	if maxDurInactivityOverride != "" {
+		txt = append(txt, fmt.Sprintf("%s |-> Durable idle  %s%s", paddingLeft, paddingRight, maxDurInactivityOverride))
+	}
 	for _, l := range txt {
 		if len(l) > *maxLen {
 			*maxLen = len(l)
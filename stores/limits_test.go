@@ -76,12 +76,21 @@ func TestLimitsBuildErrors(t *testing.T) {
 	sl.MaxAge = -1
 	expectError("Max age")
 
+	sl.MaxChannels = 1
+	sl.MaxSubscriptions = 1
+	sl.MaxMsgs = 1
+	sl.MaxBytes = 1
+	sl.MaxAge = 1
+	sl.MaxDurableInactivity = -1
+	expectError("Max durable inactivity")
+
 	// Reset sl
 	sl.MaxChannels = 1
 	sl.MaxSubscriptions = 1
 	sl.MaxMsgs = 1
 	sl.MaxBytes = 1
 	sl.MaxAge = 1
+	sl.MaxDurableInactivity = 0
 
 	// Adding a second channel should cause build failures, AddPerChannel itself
 	// does not fail.
@@ -230,6 +239,21 @@ func TestLimitsPerChannelOverride(t *testing.T) {
 	cl2 = sl.ChannelLimits
 	cl2.MaxAge = 0
 	expectNoError("foo.*", &cl2)
+
+	sl.MaxDurableInactivity = time.Hour
+	cl = &ChannelLimits{}
+	cl.MaxDurableInactivity = -1
+	sl.AddPerChannel("foo.*", cl)
+	// A per-channel limit of -1 means no expiration for this channel,
+	// while the others are inherited.
+	cl2 = sl.ChannelLimits
+	cl2.MaxDurableInactivity = 0
+	expectNoError("foo.*", &cl2)
+
+	cl = &ChannelLimits{}
+	sl.AddPerChannel("foo.*", cl)
+	cl2 = sl.ChannelLimits
+	expectNoError("foo.*", &cl2)
 }
 
 func TestLimitsInheritance(t *testing.T) {
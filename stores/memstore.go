@@ -95,7 +95,19 @@ func (ms *MemoryMsgStore) Store(data []byte) (uint64, error) {
 		ms.ageTimer = time.AfterFunc(ms.limits.MaxAge, ms.expireMsgs)
 	}
 
-	// Check if we need to remove any (but leave at least the last added)
+	// Messages are not removed while a transaction is in progress, so
+	// that they can be restored if it is rolled back.
+	if ms.txFirst == 0 {
+		ms.enforceLimits()
+	}
+
+	return ms.last, nil
+}
+
+// enforceLimits removes the first messages if the store exceeds its
+// count or size limits (but leaves at least the last added).
+// Lock is assumed held on entry.
+func (ms *MemoryMsgStore) enforceLimits() {
 	maxMsgs := ms.limits.MaxMsgs
 	maxBytes := ms.limits.MaxBytes
 	if maxMsgs > 0 || maxBytes > 0 {
@@ -110,14 +122,37 @@ func (ms *MemoryMsgStore) Store(data []byte) (uint64, error) {
 			}
 		}
 	}
+}
 
-	return ms.last, nil
+// setLimits implements the msgLimitsSetter interface.
+func (ms *MemoryMsgStore) setLimits(limits *MsgStoreLimits) error {
+	ms.Lock()
+	defer ms.Unlock()
+	if ms.closed {
+		return nil
+	}
+	ms.limits = *limits
+	ms.enforceLimits()
+	if ms.ageTimer != nil {
+		// If the timer is pending, expire messages now based on the new
+		// age limit. Otherwise, expireMsgs is running and will use it.
+		if ms.ageTimer.Stop() {
+			ms.ageTimer.Reset(0)
+		}
+	} else if ms.limits.MaxAge > 0 && ms.totalCount > 0 {
+		ms.wg.Add(1)
+		ms.ageTimer = time.AfterFunc(0, ms.expireMsgs)
+	}
+	return nil
 }
 
 // Lookup returns the stored message with given sequence number.
 func (ms *MemoryMsgStore) Lookup(seq uint64) (*pb.MsgProto, error) {
 	ms.RLock()
-	m := ms.msgs[seq]
+	var m *pb.MsgProto
+	if ms.isVisible(seq) {
+		m = ms.msgs[seq]
+	}
 	ms.RUnlock()
 	return m, nil
 }
@@ -133,7 +168,7 @@ func (ms *MemoryMsgStore) FirstMsg() (*pb.MsgProto, error) {
 // LastMsg returns the last message stored.
 func (ms *MemoryMsgStore) LastMsg() (*pb.MsgProto, error) {
 	ms.RLock()
-	m := ms.msgs[ms.last]
+	m := ms.msgs[ms.visibleLast()]
 	ms.RUnlock()
 	return m, nil
 }
@@ -148,15 +183,16 @@ func (ms *MemoryMsgStore) GetSequenceFromTimestamp(timestamp int64) (uint64, err
 	if ms.first == 0 {
 		return 0, nil
 	}
+	last := ms.visibleLast()
 	// All messages have expired
-	if ms.first > ms.last {
-		return ms.last + 1, nil
+	if ms.first > last {
+		return last + 1, nil
 	}
 	if ms.msgs[ms.first].Timestamp >= timestamp {
 		return ms.first, nil
 	}
-	if timestamp >= ms.msgs[ms.last].Timestamp {
-		return ms.last + 1, nil
+	if timestamp >= ms.msgs[last].Timestamp {
+		return last + 1, nil
 	}
 
 	index := sort.Search(len(ms.msgs), func(i int) bool {
@@ -179,6 +215,12 @@ func (ms *MemoryMsgStore) expireMsgs() {
 
 	now := time.Now().UnixNano()
 	maxAge := int64(ms.limits.MaxAge)
+	// The age limit may have been removed.
+	if maxAge == 0 {
+		ms.ageTimer = nil
+		ms.wg.Done()
+		return
+	}
 	for {
 		m, ok := ms.msgs[ms.first]
 		if !ok {
@@ -209,6 +251,41 @@ func (ms *MemoryMsgStore) removeFirstMsg() {
 	ms.first++
 }
 
+// commitTx implements the txMsgStore interface. The limits, which are
+// not enforced during the transaction, are enforced now.
+func (ms *MemoryMsgStore) commitTx() {
+	ms.Lock()
+	ms.txFirst = 0
+	ms.enforceLimits()
+	ms.Unlock()
+}
+
+// rollbackTx implements the txMsgStore interface.
+func (ms *MemoryMsgStore) rollbackTx() error {
+	ms.Lock()
+	defer ms.Unlock()
+	if ms.txFirst == 0 {
+		return nil
+	}
+	// Messages stored before the transaction and removed due to their
+	// age are not restored.
+	for seq := ms.txFirst; seq <= ms.last; seq++ {
+		if m, ok := ms.msgs[seq]; ok {
+			ms.totalBytes -= uint64(m.Size())
+			ms.totalCount--
+			delete(ms.msgs, seq)
+		}
+	}
+	ms.last = ms.txFirst - 1
+	if ms.last == 0 {
+		ms.first = 0
+	} else if ms.first > ms.last {
+		ms.first = ms.last + 1
+	}
+	ms.txFirst = 0
+	return nil
+}
+
 // Close implements the MsgStore interface
 func (ms *MemoryMsgStore) Close() error {
 	ms.Lock()
@@ -62,6 +62,9 @@ type MsgStoreLimits struct {
 type SubStoreLimits struct {
 	// How many subscriptions are allowed.
 	MaxSubscriptions int `json:"max_subscriptions"`
+	// How long a durable subscription, or durable queue group, can stay
+	// offline before being removed.
+	MaxDurableInactivity time.Duration `json:"max_durable_inactivity"`
 }
 
 // DefaultStoreLimits are the limits that a Store must
@@ -83,9 +86,10 @@ var DefaultStoreLimits = StoreLimits{
 
 // RecoveredState allows the server to reconstruct its state after a restart.
 type RecoveredState struct {
-	Info     *spb.ServerInfo
-	Clients  []*Client
-	Channels map[string]*RecoveredChannel
+	Info         *spb.ServerInfo
+	Clients      []*Client
+	Channels     map[string]*RecoveredChannel
+	WildcardSubs []*spb.WildcardSub
 }
 
 // RecoveredChannel represents a channel that has been recovered, with all its subscriptions
@@ -109,6 +113,14 @@ type Client struct {
 	spb.ClientInfo
 }
 
+// TxMsg is a message stored as part of a transaction (see Store.StoreTx).
+type TxMsg struct {
+	// Channel is the name of the channel the message is stored into.
+	Channel string
+	// Data is the message's payload.
+	Data []byte
+}
+
 // Channel contains a reference to both Subscription and Message stores.
 type Channel struct {
 	// Subs is the Subscriptions Store.
@@ -168,8 +180,8 @@ type Store interface {
 	// attempting to recover the state but fail to do so.
 	Recover() (*RecoveredState, error)
 
-	// SetLimits sets limits for this store. The action is not expected
-	// to be retroactive.
+	// SetLimits sets limits for this store. The new limits also apply to
+	// the existing channels: the messages that exceed them are removed.
 	// The store implementation should make a deep copy as to not change
 	// the content of the structure passed by the caller.
 	// This call may return an error due to limits validation errors.
@@ -185,9 +197,36 @@ type Store interface {
 	// AddClient stores information about the client identified by `clientID`.
 	AddClient(clientID, hbInbox string) (*Client, error)
 
+	// AddClientInfo stores information about the client identified by
+	// `info.ID`, including its metadata.
+	AddClientInfo(info *spb.ClientInfo) (*Client, error)
+
 	// DeleteClient removes the client identified by `clientID` from the store.
 	DeleteClient(clientID string) error
 
+	// StoreTx stores the given messages, possibly into several channels,
+	// as a single unit: either all messages are stored, or none is.
+	// The channels must have been created. The messages of a transaction
+	// are not visible through the MsgStore API until all of them have
+	// been stored. Implementations that persist their state must not
+	// recover the messages of a transaction that did not complete.
+	// On success, returns the sequences assigned to the messages.
+	// Implementations that do not support transactions should return
+	// ErrNotSupported.
+	StoreTx(msgs []*TxMsg) ([]uint64, error)
+
+	// CreateWildcardSub records a new subscription on a wildcard subject.
+	// On success, it records the subscription's ID in `sub.State.ID`. The
+	// subscriptions of its members are recorded in the SubStore of the
+	// matching channels, with SubState.WildcardID set to this ID.
+	CreateWildcardSub(sub *spb.WildcardSub) error
+
+	// UpdateWildcardSub updates the given wildcard subscription.
+	UpdateWildcardSub(sub *spb.WildcardSub) error
+
+	// DeleteWildcardSub removes the wildcard subscription `subid`.
+	DeleteWildcardSub(subid uint64) error
+
 	// Close closes this store (including all MsgStore and SubStore).
 	// If an exlusive lock was acquired, the lock shall be released.
 	Close() error
@@ -217,6 +256,9 @@ type SubStore interface {
 	// by the subscription 'subid'.
 	AckSeqPending(subid, seqno uint64) error
 
+	// GetLimits returns the limits that apply to this subscriptions store.
+	GetLimits() SubStoreLimits
+
 	// Flush is for stores that may buffer operations and need them to be persisted.
 	Flush() error
 